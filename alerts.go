@@ -128,6 +128,8 @@ type AlertParams struct {
 	JobStateString string
 	Retry          int
 	RetryAttempt   int
+	Warning        string `json:"Warning,omitempty"`
+	Usage          *Usage `json:"Usage,omitempty"`
 	ServerName     string
 	ServerKey      string `json:"ServerKey,omitempty"`
 	StdOut         string
@@ -176,6 +178,8 @@ func (job *Job) getAlertParams() AlertParams {
 		JobStateString: job.JobStateString,
 		Retry:          job.Retry,
 		RetryAttempt:   job.RetryAttempt,
+		Warning:        job.Warning,
+		Usage:          job.Usage,
 		ServerName:     job.ServerName,
 		ServerKey:      job.ServerKey,
 		StdOut:         tailLog(job.Logging.stdoutFile, maxLogLines),
@@ -200,7 +204,7 @@ func (job *Job) getAlertParams() AlertParams {
 			params.Alert = *job.AlertActions.OnSuccess
 			params.send = true
 		}
-	case JFailed:
+	case JFailed, JLimitExceeded:
 		if job.AlertActions.OnFailure != nil {
 			params.Alert = *job.AlertActions.OnFailure
 			params.send = true
@@ -235,7 +239,7 @@ func (job *Job) getAlertParams() AlertParams {
 			params.Alert = *job.AlertActions.OnHold
 			params.send = true
 		}
-	case JWarning:
+	case JWarning, JWarning2:
 		if job.AlertActions.OnWarning != nil {
 			params.Alert = *job.AlertActions.OnWarning
 			params.send = true
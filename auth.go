@@ -234,6 +234,7 @@ type AuthUser struct {
 	User   string
 	Groups []string
 	Secret string
+	RunAs  []string `json:"RunAs,omitempty"` // OS users jobs of User may run as ("*" for any), see RunAs
 }
 
 func LoadAuth(auth string) ([]AuthUser, error) {
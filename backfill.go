@@ -0,0 +1,244 @@
+package rpeat
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// MAX_BACKFILL limits the number of runs in a single backfill request
+	MAX_BACKFILL = 1000
+)
+
+// BackfillResponse lists the asof times (YYYYMMDDhhmmss) of a backfill request
+type BackfillResponse struct {
+	Status string
+	AsOf   []string
+}
+
+// ParseBackfillTime parses a backfill range boundary given as YYYYMMDD or YYYYMMDDhhmmss
+// in loc. If end is true a date refers to the end of the day
+func ParseBackfillTime(s string, loc *time.Location, end bool) (time.Time, error) {
+	switch len(s) {
+	case 8:
+		t, err := time.ParseInLocation("20060102", s, loc)
+		if err != nil {
+			return t, err
+		}
+		if end {
+			t = t.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		return t, nil
+	case 14:
+		return time.ParseInLocation("20060102150405", s, loc)
+	}
+	return time.Time{}, errors.New(fmt.Sprintf("invalid backfill time %s (expecting YYYYMMDD or YYYYMMDDhhmmss)", s))
+}
+
+// BackfillStarts returns the CronStart triggers from from through to in time order, using
+// calendar in place of the job Calendar if specified. Jitter is not applied
+func (job *Job) BackfillStarts(from, to time.Time, calendar string) ([]time.Time, error) {
+	var starts []time.Time
+	for _, c := range job.cronStartArray {
+		if c.IsEvery() || c.IsNull() || c.isDependent() {
+			continue
+		}
+		if calendar != "" {
+			c.Calendar = calendar
+		}
+		c.jitter = 0
+		t := from.Add(-time.Millisecond)
+		for {
+			_, next, err := c.NextStartAfter(t)
+			if err != nil || next.IsZero() || next.After(to) || !next.After(t) {
+				break
+			}
+			starts = append(starts, next)
+			if len(starts) > MAX_BACKFILL {
+				return nil, errors.New(fmt.Sprintf("backfill limited to %d runs", MAX_BACKFILL))
+			}
+			t = next
+		}
+	}
+	return sortedUniqueTimes(starts), nil
+}
+
+// Backfill replays the job for each of starts, using the trigger time as the asof time
+// for DateEnv and Cmd evaluation, with at most parallel runs at once. Each run is
+// recorded in History with Backfill set and the AsOf time used. Each run waits for
+// the Pool and JobsControl.MaxConcurrent slots of the job.
+//
+// Backfill runs do not change the job state or trigger dependent jobs. Scheduled
+// triggers of the job continue during a backfill, with the evaluation of each backfill
+// run waiting for any scheduled run in progress. Backfills of a job run one at a time.
+func (job *Job) Backfill(starts []time.Time, parallel int, reason Reason) {
+	job.backfillLock.Lock()
+	defer job.backfillLock.Unlock()
+
+	if parallel < 1 {
+		parallel = 1
+	}
+	ServerLogger.Printf("BACKFILL %s:%s %d runs (parallel:%d)", job.JobUUID, job.Name, len(starts), parallel)
+
+	var wg sync.WaitGroup
+	sem := make(chan bool, parallel)
+	for _, t := range starts {
+		sem <- true
+		wg.Add(1)
+		go func(asof string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			job.runBackfill(asof, reason)
+		}(t.In(job._location).Format("20060102150405"))
+	}
+	wg.Wait()
+
+	job.Lock()
+	job.modified = time.Now().Unix()
+	job.Unlock()
+	job.SaveSnapshot(true)
+	if job.updates != nil {
+		job.sendUpdateClient()
+	}
+	ServerLogger.Printf("BACKFILL %s:%s complete", job.JobUUID, job.Name)
+}
+
+func (job *Job) runBackfill(asof string, reason Reason) {
+	// backfill runs wait for the Pool and JobsControl.MaxConcurrent slots of the job,
+	// without moving the job to JQueued
+	held, _ := job.takeSlots(nil, nil)
+	defer releaseSlots(held)
+
+	runid := uuid.New()
+	jobRunDir := filepath.Join(job.TmpDir, fmt.Sprintf("%s", job.JobUUID))
+	stdoutName := filepath.Join(jobRunDir, fmt.Sprintf("%s.stdout", runid))
+	stderrName := filepath.Join(jobRunDir, fmt.Sprintf("%s.stderr", runid))
+
+	// evaluation uses RunUUID, Params and sets CmdEval on the job, which are restored.
+	// Backfill runs use the Default of each Param. runlock excludes a scheduled run, which
+	// uses them in runTik
+	job.runlock.Lock()
+	job.evalLock.Lock()
+	runUUID, cmdEval, params := job.RunUUID, job.CmdEval, job.params
+	job.RunUUID, job.params = runid, nil
+	c, err := evaluatedCmd(job, false, asof)
+	if err != nil {
+		ServerLogger.Printf(err.Error())
+	}
+	scriptErr := job.writeScript(runid)
+	if scriptErr != nil {
+		scriptErr = errors.New(fmt.Sprintf("unable to write Script: %s", scriptErr))
+		ServerLogger.Printf("[backfill] %s %s", job.Name, scriptErr)
+	}
+	backfillCmdEval := job.CmdEval
+	if job.Logging.StdoutFile != "" {
+		stdoutName = job.ExpandEnv([]string{job.Logging.StdoutFile}, asof)[0]
+	}
+	if job.Logging.StderrFile != "" {
+		stderrName = job.ExpandEnv([]string{job.Logging.StderrFile}, asof)[0]
+	}
+	job.RunUUID, job.CmdEval, job.params = runUUID, cmdEval, params
+	job.evalLock.Unlock()
+	job.runlock.Unlock()
+
+	flags := os.O_RDWR | os.O_CREATE | os.O_TRUNC
+	if job.Logging.Append {
+		flags = os.O_RDWR | os.O_CREATE | os.O_APPEND
+	}
+	job.runDir()
+	var runFiles []*os.File
+	stdout, ferr := openRunLog(stdoutName, job.Logging.StdoutFile == "", flags)
+	if ferr != nil {
+		ServerLogger.Printf("[backfill] %s unable to create stdout: %s", job.Name, ferr)
+		return
+	}
+	defer stdout.Close()
+	if job.Logging.StdoutFile == "" {
+		runFiles = append(runFiles, stdout)
+	}
+	stderr, ferr := openRunLog(stderrName, job.Logging.StderrFile == "", flags)
+	if ferr != nil {
+		ServerLogger.Printf("[backfill] %s unable to create stderr: %s", job.Name, ferr)
+		return
+	}
+	defer stderr.Close()
+	if job.Logging.StderrFile == "" {
+		runFiles = append(runFiles, stderr)
+	}
+
+	c.SysProcAttr = syscallSysProcAttr()
+	c.Stdout = stdout
+	c.Stderr = stderr
+	uid, gid, runAsErr := job.applyRunAs(c.SysProcAttr)
+	job.chownRun(uid, gid, runid, runFiles...)
+	stdin, workDirErr := job.applyWorkDir(&c, asof, uid, gid)
+	lim := job.newLimiter(&c, runid)
+
+	start := time.Now()
+	exitcode := 0
+	if err = runAsErr; err == nil {
+		err = workDirErr
+	}
+	if err == nil {
+		err = scriptErr
+	}
+	if err == nil {
+		err = c.Start()
+	}
+	if stdin != nil {
+		stdin.Close()
+	}
+	if err == nil {
+		lim.started(c.Process.Pid)
+		err = c.Wait()
+	}
+	exceeded := lim.ended(c.ProcessState)
+	if err != nil {
+		exitcode = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitcode = exitErr.ExitCode()
+		} else {
+			stderr.Write([]byte("[ rpeat ] Unable to create process: " + err.Error()))
+		}
+	}
+	stop := time.Now()
+
+	state := JSuccess
+	if exceeded != "" {
+		state = JLimitExceeded
+	} else if exitcode != 0 {
+		state = JFailed
+	}
+	ServerLogger.Printf("BACKFILL %s:%s asof:%s run:%s exit:%d", job.JobUUID, job.Name, asof, runid, exitcode)
+
+	jh := JobHistory{
+		RunUUID:        runid.String(),
+		ExitCode:       exitcode,
+		CmdEval:        backfillCmdEval,
+		JobStateString: state.String(),
+		JobStateAbb:    historyAbb(state),
+		Start:          start.In(job._location).Format("2006-01-02 15:04:05"),
+		StartUNIX:      start.Unix(),
+		Stop:           stop.In(job._location).Format("2006-01-02 15:04:05"),
+		Elapsed:        dhms(stop.Sub(start).Round(time.Second)),
+		Stdout:         stdout.Name(),
+		Stderr:         stderr.Name(),
+		Unscheduled:    true,
+		Reason:         reason,
+		Backfill:       true,
+		AsOf:           asof,
+		LimitExceeded:  exceeded,
+		Usage:          processUsage(c.ProcessState),
+	}
+	job.Lock()
+	job.prependHistory(jh)
+	job.Unlock()
+}
@@ -0,0 +1,434 @@
+package rpeat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Blackout is a maintenance window during which scheduled triggers (CronStart and
+// dependencies) are deferred or skipped. Windows are either a date range (Start and
+// End) or recurring (Cron, e.g. "0 0 22 * * 6", with Duration), in Timezone which
+// defaults to ServerConfig.Timezone. Windows apply to all jobs, or only to jobs with a
+// Group in Groups. Manual starts are not affected.
+//
+// Windows are defined in ServerConfig.Blackouts, or added and removed while the server
+// is running with /api/blackouts/add and /api/blackouts/remove. Windows added with the
+// API are kept in blackouts.json in the server HOME until they end or are removed.
+type Blackout struct {
+	ID       string         `json:"ID"`
+	Start    string         `json:"Start,omitempty"` // "2006-01-02 15:04:05", "2006-01-02 15:04" or "2006-01-02"
+	End      string         `json:"End,omitempty"`   // as Start, with a date alone being the end of that day
+	Cron     string         `json:"Cron,omitempty"`
+	Duration string         `json:"Duration,omitempty"`
+	Timezone string         `json:"Timezone,omitempty"`
+	Policy   string         `json:"Policy,omitempty"` // see BlackoutPolicy
+	Groups   []string       `json:"Groups,omitempty"`
+	Comment  string         `json:"Comment,omitempty"`
+	User     string         `json:"User,omitempty"`
+	AdHoc    bool           `json:"AdHoc,omitempty"`
+	policy   BlackoutPolicy `json:"-"`
+	start    time.Time      `json:"-"`
+	end      time.Time      `json:"-"`
+	cron     *Cron          `json:"-"`
+	duration time.Duration  `json:"-"`
+}
+
+// BlackoutPolicy defines how a trigger within a Blackout is handled:
+//
+//	defer  (default) the job is run once at the end of the window
+//	skip   the trigger is dropped and the job waits for its next trigger after the window
+//
+// While a trigger is deferred or after it is skipped the job is in the JBlackout state.
+type BlackoutPolicy int
+
+const (
+	BlackoutDefer BlackoutPolicy = iota
+	BlackoutSkip
+)
+
+var blackoutPolicyNames = [...]string{"defer", "skip"}
+
+func (p BlackoutPolicy) String() string {
+	if p < BlackoutDefer || p > BlackoutSkip {
+		return "Unknown"
+	}
+	return blackoutPolicyNames[p]
+}
+
+// ParseBlackoutPolicy converts a Policy name, with the empty string being the default defer
+func ParseBlackoutPolicy(policy string) (BlackoutPolicy, error) {
+	if policy == "" {
+		return BlackoutDefer, nil
+	}
+	for i, name := range blackoutPolicyNames {
+		if strings.EqualFold(policy, name) {
+			return BlackoutPolicy(i), nil
+		}
+	}
+	return BlackoutDefer, errors.New(fmt.Sprintf("unrecognized Blackout Policy %s (expecting one of %s)", policy, strings.Join(blackoutPolicyNames[:], ", ")))
+}
+
+var blackoutTimeFormats = []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04", "20060102150405", "2006-01-02"}
+
+func parseBlackoutTime(s string, loc *time.Location, end bool) (time.Time, error) {
+	for _, f := range blackoutTimeFormats {
+		if t, err := time.ParseInLocation(f, s, loc); err == nil {
+			if end && f == "2006-01-02" {
+				t = t.AddDate(0, 0, 1)
+			}
+			return t, nil
+		}
+	}
+	return ZeroTime, errors.New(fmt.Sprintf("invalid time %s (expecting YYYY-MM-DD hh:mm:ss)", s))
+}
+
+// parse validates the window, using timezone if no Timezone is set
+func (b *Blackout) parse(timezone string) error {
+	if b.ID == "" {
+		return errors.New("Blackout requires an ID")
+	}
+	if b.Timezone == "" {
+		b.Timezone = timezone
+	}
+	loc, err := time.LoadLocation(b.Timezone)
+	if err != nil {
+		return fmt.Errorf("Blackout %s: invalid Timezone %s", b.ID, b.Timezone)
+	}
+	if b.policy, err = ParseBlackoutPolicy(b.Policy); err != nil {
+		return fmt.Errorf("Blackout %s: %s", b.ID, err)
+	}
+	if b.Cron != "" {
+		if b.Start != "" || b.End != "" {
+			return fmt.Errorf("Blackout %s: use either Cron and Duration or Start and End", b.ID)
+		}
+		c, err := ParseCron(b.Cron, b.Timezone, "", nil, false, false, 0)
+		if err != nil || c.IsNull() || c.isDependent() || c.IsEvery() {
+			return fmt.Errorf("Blackout %s: invalid Cron %s", b.ID, b.Cron)
+		}
+		b.cron = &c
+		if b.duration, err = time.ParseDuration(b.Duration); err != nil || b.duration <= 0 {
+			return fmt.Errorf("Blackout %s: invalid Duration %s", b.ID, b.Duration)
+		}
+		return nil
+	}
+	if b.start, err = parseBlackoutTime(b.Start, loc, false); err != nil {
+		return fmt.Errorf("Blackout %s: Start %s", b.ID, err)
+	}
+	if b.end, err = parseBlackoutTime(b.End, loc, true); err != nil {
+		return fmt.Errorf("Blackout %s: End %s", b.ID, err)
+	}
+	if !b.start.Before(b.end) {
+		return fmt.Errorf("Blackout %s: Start must be before End", b.ID)
+	}
+	return nil
+}
+
+// window returns the start and end of the window containing t, and false if t is
+// outside the window
+func (b *Blackout) window(t time.Time) (time.Time, time.Time, bool) {
+	if b.cron == nil {
+		return b.start, b.end, !t.Before(b.start) && t.Before(b.end)
+	}
+	// the first window starting after t-Duration contains t if it starts by t
+	_, start, err := b.cron.NextStartAfter(t.Add(-b.duration - time.Millisecond))
+	if err != nil || start.IsZero() || start.After(t) {
+		return ZeroTime, ZeroTime, false
+	}
+	return start, start.Add(b.duration), true
+}
+
+// expired is true if a date range window has ended
+func (b *Blackout) expired(t time.Time) bool {
+	return b.cron == nil && !t.Before(b.end)
+}
+
+// applies is true if the window affects jobs in group, with no Groups affecting all jobs
+func (b *Blackout) applies(group []string) bool {
+	if len(b.Groups) == 0 {
+		return true
+	}
+	for _, g := range group {
+		if stringInSlice(g, b.Groups) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Blackout) String() string {
+	if b.cron != nil {
+		return fmt.Sprintf("%s (%s for %s, %s)", b.ID, b.Cron, b.Duration, b.policy)
+	}
+	return fmt.Sprintf("%s (%s to %s, %s)", b.ID, b.Start, b.End, b.policy)
+}
+
+// Blackouts is a set of windows, with overlapping windows treated as one
+type Blackouts []*Blackout
+
+// Active returns the window affecting group at time t, with the time all affecting
+// windows have ended. A nil Blackout is returned if t is outside all windows
+func (bs Blackouts) Active(group []string, t time.Time) (*Blackout, time.Time) {
+	var active *Blackout
+	end := t
+	for i := 0; i < 100; i++ { // overlapping and back to back windows
+		found := false
+		for _, b := range bs {
+			if !b.applies(group) {
+				continue
+			}
+			if _, e, ok := b.window(end); ok && e.After(end) {
+				if active == nil || (b.policy == BlackoutSkip && active.policy != BlackoutSkip) {
+					active = b // skip takes precedence over defer
+				}
+				end = e
+				found = true
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return active, end
+}
+
+// Next returns the next trigger of cron after t for a job in group, with triggers
+// within a defer window moved to the end of the window and triggers within a skip
+// window dropped. The window applied to the returned trigger, if any, is returned
+func (bs Blackouts) Next(cron []Cron, group []string, t time.Time) (time.Time, *Blackout) {
+	for i := 0; i < 1000; i++ {
+		next := NextCronStartAfter(cron, t)
+		if next.IsZero() {
+			return next, nil
+		}
+		b, end := bs.Active(group, next)
+		if b == nil {
+			return next, nil
+		}
+		if b.policy == BlackoutDefer {
+			return end, b
+		}
+		t = end.Add(-time.Second) // triggers are whole seconds, with the end of a window outside it
+	}
+	return ZeroTime, nil
+}
+
+// NextCronStartAfter is NextCronStart as of time t, returning the zero time if cron
+// does not trigger
+func NextCronStartAfter(cron []Cron, t time.Time) time.Time {
+	next := ZeroTime
+	for i := range cron {
+		var n time.Time
+		if cron[i].IsEvery() {
+			n = t.Add(cron[i].every)
+		} else if cron[i].IsNull() || cron[i].isDependent() {
+			continue
+		} else {
+			_, n, _ = cron[i].NextStartAfter(t)
+		}
+		if !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return next
+}
+
+// BlackoutResponse lists the current windows, with Active the IDs of windows in effect
+type BlackoutResponse struct {
+	Status    string
+	Blackouts []Blackout
+	Active    []string
+}
+
+func newBlackoutResponse(status string) *BlackoutResponse {
+	resp := &BlackoutResponse{Status: status, Blackouts: []Blackout{}}
+	now := time.Now()
+	for _, b := range getBlackouts() {
+		resp.Blackouts = append(resp.Blackouts, *b)
+		if _, _, ok := b.window(now); ok {
+			resp.Active = append(resp.Active, b.ID)
+		}
+	}
+	return resp
+}
+
+var blackouts = struct {
+	windows Blackouts
+	file    string
+	lock    sync.Mutex
+}{}
+
+// LoadBlackouts returns the windows of ServerConfig.Blackouts together with the
+// windows added with the API, which are kept in blackouts.json in the server HOME
+func LoadBlackouts(server ServerConfig) (Blackouts, error) {
+	var bs Blackouts
+	for i := range server.Blackouts {
+		b := server.Blackouts[i]
+		b.AdHoc = false
+		if err := b.parse(server.Timezone); err != nil {
+			return nil, err
+		}
+		bs = append(bs, &b)
+	}
+	byteval, err := ioutil.ReadFile(filepath.Join(server.HOME, "blackouts.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return bs, nil
+		}
+		return bs, err
+	}
+	var adhoc []Blackout
+	if err := json.Unmarshal(byteval, &adhoc); err != nil {
+		return bs, fmt.Errorf("blackouts.json: %s", err)
+	}
+	now := time.Now()
+	for i := range adhoc {
+		b := adhoc[i]
+		b.AdHoc = true
+		if err := b.parse(server.Timezone); err != nil {
+			ServerLogger.Printf("[Blackout] ignoring %s", err)
+			continue
+		}
+		if !b.expired(now) {
+			bs = append(bs, &b)
+		}
+	}
+	return bs, nil
+}
+
+// SetBlackouts loads the windows used by all jobs
+func SetBlackouts(server ServerConfig) error {
+	bs, err := LoadBlackouts(server)
+	blackouts.lock.Lock()
+	defer blackouts.lock.Unlock()
+	blackouts.windows = bs
+	blackouts.file = filepath.Join(server.HOME, "blackouts.json")
+	for _, b := range bs {
+		ServerLogger.Printf("[Blackout] %s groups:%v", b, b.Groups)
+	}
+	return err
+}
+
+// getBlackouts returns the current windows, removing date range windows that have ended
+func getBlackouts() Blackouts {
+	blackouts.lock.Lock()
+	defer blackouts.lock.Unlock()
+	now := time.Now()
+	bs := make(Blackouts, 0, len(blackouts.windows))
+	for _, b := range blackouts.windows {
+		if !b.expired(now) {
+			bs = append(bs, b)
+		}
+	}
+	if len(bs) < len(blackouts.windows) {
+		blackouts.windows = bs
+		saveBlackouts()
+	}
+	return append(Blackouts(nil), bs...)
+}
+
+// saveBlackouts writes windows added with the API. Requires blackouts.lock
+func saveBlackouts() {
+	if blackouts.file == "" {
+		return
+	}
+	adhoc := []*Blackout{}
+	for _, b := range blackouts.windows {
+		if b.AdHoc {
+			adhoc = append(adhoc, b)
+		}
+	}
+	byteval, _ := json.MarshalIndent(adhoc, "", "  ")
+	if err := ioutil.WriteFile(blackouts.file, byteval, 0600); err != nil {
+		ServerLogger.Printf("[Blackout] unable to save %s: %s", blackouts.file, err)
+	}
+}
+
+// addBlackout adds a window, using timezone if no Timezone is set. A new ID is
+// assigned if none is given
+func addBlackout(b Blackout, timezone string) (*Blackout, error) {
+	if b.ID == "" {
+		b.ID = uuid.New().String()[:8]
+	}
+	b.AdHoc = true
+	if err := b.parse(timezone); err != nil {
+		return nil, err
+	}
+	if b.expired(time.Now()) {
+		return nil, fmt.Errorf("Blackout %s has already ended", b.ID)
+	}
+	blackouts.lock.Lock()
+	defer blackouts.lock.Unlock()
+	for _, w := range blackouts.windows {
+		if w.ID == b.ID {
+			return nil, fmt.Errorf("Blackout %s already exists", b.ID)
+		}
+	}
+	blackouts.windows = append(blackouts.windows, &b)
+	saveBlackouts()
+	return &b, nil
+}
+
+// removeBlackout removes a window added with the API
+func removeBlackout(id string) error {
+	blackouts.lock.Lock()
+	defer blackouts.lock.Unlock()
+	for i, b := range blackouts.windows {
+		if b.ID != id {
+			continue
+		}
+		if !b.AdHoc {
+			return fmt.Errorf("Blackout %s is defined in the server configuration", id)
+		}
+		blackouts.windows = append(blackouts.windows[:i], blackouts.windows[i+1:]...)
+		saveBlackouts()
+		return nil
+	}
+	return fmt.Errorf("Blackout %s not found", id)
+}
+
+// blackout checks a scheduled trigger against the current windows. A trigger within
+// a defer window is rescheduled for the end of the window, and a trigger within a skip
+// window is dropped in favour of the next trigger after it. True is returned if the
+// trigger is not to be run now. Requires job.runlock
+func (job *Job) blackout() bool {
+	bs := getBlackouts()
+	now := time.Now()
+	b, end := bs.Active(job.Group, now)
+	if b == nil {
+		if job.Blackout != "" {
+			job.Lock()
+			job.Blackout = ""
+			job.Unlock()
+		}
+		return false
+	}
+	next := end
+	scheduled := next
+	if b.policy == BlackoutSkip {
+		job.clearMissed()
+		next, _ = bs.Next(job.cronStartArray, job.Group, now)
+		scheduled, _ = bs.Next(withoutJitter(job.cronStartArray), job.Group, now)
+	}
+	ServerLogger.Printf("[Blackout] %s:%s trigger %s by %s, next trigger %s", job.JobUUID, job.Name, b.policy, b, next.In(job._location).Format("2006-01-02 15:04:05"))
+	job.Lock()
+	job.Blackout = fmt.Sprintf("%s: %s until %s", b.ID, b.policy, end.In(job._location).Format("2006-01-02 15:04:05"))
+	job.Unlock()
+	if next.IsZero() {
+		job.resetTimer(time.Duration(math.MaxInt64))
+	} else {
+		job.resetTimer(next.Sub(now))
+	}
+	job.setNextStart(next, scheduled)
+	job.setJobState(JBlackout)
+	job.sendUpdate()
+	return true
+}
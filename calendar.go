@@ -2,15 +2,27 @@ package rpeat
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"math"
 	"os"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
-	//    "errors"
+	"unicode"
 	"path/filepath"
 )
 
+// CalAdjust is the business day convention used to move a date that is not available
+// in a calendar to one that is:
+//
+//	following            the next available date
+//	modified-following   the next available date, unless in the next month, then the prior
+//	preceding            the prior available date
+//	modified-preceding   the prior available date, unless in the prior month, then the next
+//	none                 no adjustment
 type CalAdjust int
 
 const (
@@ -21,12 +33,44 @@ const (
 	CalNoAdjust
 )
 
+var calAdjustNames = [...]string{"following", "modified-following", "preceding", "modified-preceding", "none"}
+
+func (adj CalAdjust) String() string {
+	if adj < CalFollowing || adj > CalNoAdjust {
+		return "Unknown"
+	}
+	return calAdjustNames[adj]
+}
+
+// ParseCalAdjust converts a CalAdjust name, with the empty string being following. The
+// abbreviations F, MF, P and MP are also accepted
+func ParseCalAdjust(adjust string) (CalAdjust, error) {
+	switch strings.ToLower(adjust) {
+	case "", "f":
+		return CalFollowing, nil
+	case "mf":
+		return CalFollowingModified, nil
+	case "p":
+		return CalProceeding, nil
+	case "mp":
+		return CalProceedingModified, nil
+	}
+	for i, name := range calAdjustNames {
+		if strings.EqualFold(adjust, name) {
+			return CalAdjust(i), nil
+		}
+	}
+	return CalFollowing, errors.New(fmt.Sprintf("unrecognized CalAdjust %s (expecting one of %s)", adjust, strings.Join(calAdjustNames[:], ", ")))
+}
+
 type Cal struct {
 	Calendar string // Mondays
 	Forward  bool
 	DatesIn  []int // 20190101, 20190203, ...
 	DatesEx  []int // 20190101, 20190203, ...
 	Adjust   CalAdjust
+	Holidays map[int]string // rule based calendars: 20191225: "Christmas Day", ...
+	Rules    *CalendarRules
 }
 
 func NewCal(calendar string) Cal {
@@ -34,12 +78,39 @@ func NewCal(calendar string) Cal {
 	return cal
 }
 
+// ReadCalendar reads calendar from calendarPath, either a file of YYYYMMDD dates (one per
+// line) or a JSON CalendarRules definition. Calendars of BuiltinCalendars are used if no
+// file is found, and calendar may be an expression over calendars (see composite.go).
+// Calendars are cached by the CalendarRegistry while the server is running
 func ReadCalendar(calendar string, calendarPath []string) (Cal, error) {
+	if calendars != nil {
+		return calendars.get(calendar, calendarPath)
+	}
+	return readCalendar(calendar, calendarPath)
+}
+
+// lookupCalendar reads calendar as ReadCalendar, without adding it to the
+// CalendarRegistry if not already cached
+func lookupCalendar(calendar string, calendarPath []string) (Cal, error) {
+	if calendars != nil {
+		return calendars.peek(calendar, calendarPath)
+	}
+	return readCalendar(calendar, calendarPath)
+}
+
+// readCalendar reads calendar from calendarPath, bypassing the CalendarRegistry
+func readCalendar(calendar string, calendarPath []string) (Cal, error) {
+	if isCompositeCalendar(calendar) {
+		return readCompositeCalendar(calendar, calendarPath, ReadCalendar)
+	}
 	var err error
 	var calendarFile string
 	for _, path := range calendarPath {
 		exists, err := FileExists(path)
 		if !exists {
+			if cal, ok := builtinCalendar(calendar); ok {
+				return cal, nil
+			}
 			return Cal{}, CalendarError{Exception: CalendarDirNotFound, Calendar: calendar, CalendarDirs: []string{path}, fileErr: err}
 		}
 		calendarFile = filepath.Join(path, calendar)
@@ -47,40 +118,51 @@ func ReadCalendar(calendar string, calendarPath []string) (Cal, error) {
 		if exists {
 			break
 		} else {
+			if cal, ok := builtinCalendar(calendar); ok {
+				return cal, nil
+			}
 			return Cal{}, CalendarError{Exception: CalendarNotFound, Calendar: calendar, CalendarDirs: calendarPath, fileErr: err}
 		}
 	}
 	if calendarFile == "" {
+		if cal, ok := builtinCalendar(calendar); ok {
+			return cal, nil
+		}
 		return Cal{}, CalendarError{Exception: MissingCalendar, Calendar: calendar, CalendarDirs: calendarPath}
 	}
 	file, err := os.Open(calendarFile)
 	if err != nil {
 		return Cal{}, CalendarError{Exception: CalendarReadError, Calendar: calendar, CalendarDirs: calendarPath, fileErr: err}
 	}
-	scanner := bufio.NewScanner(file)
-
-	var nlines int
-	for scanner.Scan() {
-		nlines++
-	}
-	_, err = file.Seek(0, 0)
-	if err != nil {
-		return Cal{}, CalendarError{Exception: CalendarReadError, Calendar: calendar, CalendarDirs: calendarPath, fileErr: err}
+	defer file.Close()
+	if isRulesFile(file) {
+		b, err := ioutil.ReadAll(file)
+		if err != nil {
+			return Cal{}, CalendarError{Exception: CalendarReadError, Calendar: calendar, CalendarDirs: calendarPath, fileErr: err}
+		}
+		rules, err := ParseCalendarRules(b)
+		if err != nil {
+			return Cal{}, CalendarError{Exception: CalendarReadError, Calendar: calendar, CalendarDirs: calendarPath, fileErr: err}
+		}
+		return rules.currentCal(calendar), nil
 	}
-	scanner = bufio.NewScanner(file)
-
-	dates := make([]int, nlines)
-
-	var n int
+	var dates []int
+	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		txt := scanner.Text()
-		date, err := strconv.ParseInt(txt, 10, 32)
-		if err != nil {
+		txt := strings.TrimSpace(scanner.Text())
+		if txt == "" {
+			continue
+		}
+		date, parseErr := strconv.ParseInt(txt, 10, 32)
+		if parseErr != nil {
 			ServerLogger.Printf("error parsing %s into date(int)\n", txt)
-			err = CalendarError{Exception: CalendarReadError, Calendar: calendar, CalendarDirs: calendarPath, fileErr: err}
+			err = CalendarError{Exception: CalendarReadError, Calendar: calendar, CalendarDirs: calendarPath, fileErr: parseErr}
+			continue
 		}
-		dates[n] = int(date)
-		n++
+		dates = append(dates, int(date))
+	}
+	if scanErr := scanner.Err(); scanErr != nil {
+		return Cal{}, CalendarError{Exception: CalendarReadError, Calendar: calendar, CalendarDirs: calendarPath, fileErr: scanErr}
 	}
 	cal := NewCal(calendar)
 	cal.DatesIn = dates
@@ -88,6 +170,22 @@ func ReadCalendar(calendar string, calendarPath []string) (Cal, error) {
 
 }
 
+// isRulesFile is true if file contains a JSON object rather than a list of dates,
+// leaving file at its start
+func isRulesFile(file *os.File) bool {
+	defer file.Seek(0, 0)
+	r := bufio.NewReader(file)
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return false
+		}
+		if !unicode.IsSpace(rune(c)) {
+			return c == '{'
+		}
+	}
+}
+
 func NextAvailableDate(t time.Time, calendar string, calendarPath []string) (int, time.Month, int) {
 	cal, _ := ReadCalendar(calendar, calendarPath) // FIXME: handle error
 	y, m, d := t.Date()
@@ -102,28 +200,64 @@ func NextAvailableDate(t time.Time, calendar string, calendarPath []string) (int
 }
 
 //FIXME: these cal vs path are inconsistent
-func addDateWithCal(t time.Time, years int, months int, days int, cal Cal) time.Time {
+func addDateWithCal(t time.Time, years int, months int, days int, cal Cal) (time.Time, error) {
 	y, m, d := t.Date()
 	date := y*10000 + int(m)*100 + d
 
 	idx := sort.SearchInts(cal.DatesIn, date)
 
 	// rebase idx if beyond initial date
-	if !cal.Forward && cal.DatesIn[idx] > date {
+	if !cal.Forward && (idx == len(cal.DatesIn) || cal.DatesIn[idx] > date) {
 		idx = idx - 1
 	}
 
+	if idx+days < 0 || idx+days >= len(cal.DatesIn) {
+		return t, CalendarError{Exception: CalendarOutOfRange, Calendar: cal.Calendar, fileErr: fmt.Errorf("%d days from %d is outside of calendar %s", days, date, cal.Calendar)}
+	}
 	dt := cal.DatesIn[idx+days]
 	yr := dt / 10000
 	mo := time.Month(math.Mod(float64(dt/100), 100))
 	dy := int(math.Mod(float64(dt), 100))
-	return time.Date(yr, mo, dy, 0, 0, 0, 0, time.UTC)
+	return time.Date(yr, mo, dy, 0, 0, 0, 0, time.UTC), nil
+}
+
+// adjustDate moves the date dt (YYYYMMDD) to an available date of cal according to adj.
+// Dates outside of the calendar range are returned unchanged with inRange false, and
+// unavailable dates are returned as 0 with CalNoAdjust
+func (cal Cal) adjustDate(dt int, adj CalAdjust) (adjusted int, inRange bool) {
+	dates := cal.DatesIn
+	i := sort.SearchInts(dates, dt)
+	if i == len(dates) || (i == 0 && dates[0] != dt) {
+		return dt, false
+	}
+	if dates[i] == dt {
+		return dt, true
+	}
+	following, preceding := dates[i], dates[i-1]
+	month := dt / 100
+	switch adj {
+	case CalFollowingModified:
+		if following/100 != month {
+			return preceding, true
+		}
+		return following, true
+	case CalProceeding:
+		return preceding, true
+	case CalProceedingModified:
+		if preceding/100 != month {
+			return following, true
+		}
+		return preceding, true
+	case CalNoAdjust:
+		return 0, true
+	}
+	return following, true
 }
 
-func addDayWithCal(t time.Time, days int, calendar string, calendarPath []string, forward bool) time.Time {
+func addDayWithCal(t time.Time, days int, calendar string, calendarPath []string, forward bool) (time.Time, error) {
 	cal, err := ReadCalendar(calendar, calendarPath)
 	if err != nil {
-		ServerLogger.Fatal("Calendar error")
+		return t, err
 	}
 	cal.Forward = forward
 	return addDateWithCal(t, 0, 0, days, cal)
@@ -0,0 +1,161 @@
+package rpeat
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAdjustDateMonthBoundaries(t *testing.T) {
+	// business days of NYSE around the month boundaries in 2020
+	cal := Cal{Calendar: "test", DatesIn: []int{
+		20200130, 20200131, 20200203, 20200204,
+		20200227, 20200228, 20200302, 20200303,
+		20200528, 20200529, 20200601, 20200602,
+		20200730, 20200731, 20200803, 20200804,
+	}}
+	tests := []struct {
+		date   int
+		adjust CalAdjust
+		want   int
+	}{
+		// month start (Saturday 1st)
+		{20200201, CalFollowing, 20200203},
+		{20200201, CalFollowingModified, 20200203},
+		{20200201, CalProceeding, 20200131},
+		{20200201, CalProceedingModified, 20200203},
+		{20200201, CalNoAdjust, 0},
+		{20200801, CalFollowing, 20200803},
+		{20200801, CalFollowingModified, 20200803},
+		{20200801, CalProceeding, 20200731},
+		{20200801, CalProceedingModified, 20200803},
+		{20200801, CalNoAdjust, 0},
+		// month end (Saturday 29th, Sunday 31st)
+		{20200229, CalFollowing, 20200302},
+		{20200229, CalFollowingModified, 20200228},
+		{20200229, CalProceeding, 20200228},
+		{20200229, CalProceedingModified, 20200228},
+		{20200229, CalNoAdjust, 0},
+		{20200531, CalFollowing, 20200601},
+		{20200531, CalFollowingModified, 20200529},
+		{20200531, CalProceeding, 20200529},
+		{20200531, CalProceedingModified, 20200529},
+		{20200531, CalNoAdjust, 0},
+		// available dates are unchanged
+		{20200529, CalFollowing, 20200529},
+		{20200601, CalProceedingModified, 20200601},
+		{20200529, CalNoAdjust, 20200529},
+	}
+	for _, tt := range tests {
+		got, inRange := cal.adjustDate(tt.date, tt.adjust)
+		if got != tt.want || !inRange {
+			t.Errorf("adjustDate(%d, %s) = %d, %v; want %d, true", tt.date, tt.adjust, got, inRange, tt.want)
+		}
+	}
+}
+
+func TestConvertDateCalAdjust(t *testing.T) {
+	tests := []struct {
+		datevar string
+		asof    string
+		want    string
+	}{
+		{"CCYYMMDD,NYSE,following", "20200531120000", "20200601"},
+		{"CCYYMMDD,NYSE,modified-following", "20200531120000", "20200529"},
+		{"CCYYMMDD,NYSE,preceding", "20200531120000", "20200529"},
+		{"CCYYMMDD,NYSE,modified-preceding", "20200531120000", "20200529"},
+		{"CCYYMMDD,NYSE,none", "20200531120000", "20200531"},
+		{"CCYYMMDD,NYSE,following", "20200801120000", "20200803"},
+		{"CCYYMMDD,NYSE,modified-following", "20200801120000", "20200803"},
+		{"CCYYMMDD,NYSE,preceding", "20200801120000", "20200731"},
+		{"CCYYMMDD,NYSE,modified-preceding", "20200801120000", "20200803"},
+		{"CCYYMMDD,NYSE,none", "20200801120000", "20200801"},
+		// business-day shifts count from the unadjusted date
+		{"CCYYMMDD,0D,NYSE,modified-following", "20200531120000", "20200529"},
+		{"CCYYMMDD,0D,NYSE,following", "20200531120000", "20200601"},
+		{"CCYYMMDD,1D,NYSE,modified-following", "20200531120000", "20200601"},
+		{"CCYYMMDD,-1D,NYSE,modified-following", "20200531120000", "20200529"},
+		{"CCYYMMDD,-1D,NYSE,modified-preceding", "20200801120000", "20200731"},
+		{"CCYYMMDD,1D,NYSE,preceding", "20200801120000", "20200803"},
+	}
+	for _, tt := range tests {
+		got, err := ConvertDate(tt.datevar, "America/New_York", nil, tt.asof, 0)
+		if err != nil {
+			t.Errorf("ConvertDate(%q) asof %s: %s", tt.datevar, tt.asof, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ConvertDate(%q) asof %s = %s; want %s", tt.datevar, tt.asof, got, tt.want)
+		}
+	}
+}
+
+func TestCalendarDirsRestricted(t *testing.T) {
+	k := service{
+		Jobs:         jobMap{"a": &Job{Calendar: "NYSE", CalendarDirs: []string{"/srv/jobcals"}}},
+		ServerConfig: ServerConfig{CalendarDirs: []string{"/srv/calendars"}},
+	}
+	tests := []struct {
+		calendar string
+		dirs     []string
+		want     []string
+		ok       bool
+	}{
+		{"NYSE", nil, []string{"/srv/jobcals"}, true},
+		{"LSE", nil, []string{"/srv/calendars"}, true},
+		{"LSE", []string{"/srv/calendars"}, []string{"/srv/calendars"}, true},
+		{"LSE", []string{"/srv/jobcals/"}, []string{"/srv/jobcals"}, true},
+		{"LSE", []string{"/etc"}, nil, false},
+		{"LSE", []string{"/srv/calendars", "/tmp"}, nil, false},
+		{"../../etc/passwd", nil, nil, false},
+		{"NYSE & sub/LSE", nil, nil, false},
+		{"NYSE & ..", nil, nil, false},
+	}
+	for _, tt := range tests {
+		got, err := k.calendarDirs(tt.calendar, tt.dirs)
+		if (err == nil) != tt.ok || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("calendarDirs(%q, %v) = %v, %v; want %v", tt.calendar, tt.dirs, got, err, tt.want)
+		}
+	}
+}
+
+func TestLookupCalendarNotCached(t *testing.T) {
+	initServerLogging(ioutil.Discard)
+	dir, err := ioutil.TempDir("", "rpeat-cal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err = ioutil.WriteFile(filepath.Join(dir, "A"), []byte("20240102\n20240103\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := newCalendarRegistry(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.watcher.Close()
+	calendars = r
+	defer func() { calendars = nil }()
+
+	dirs := []string{dir}
+	for _, calendar := range []string{"A", "missing", "A - missing", "A & NYSE"} {
+		lookupCalendar(calendar, dirs)
+	}
+	if len(r.cals) != 0 {
+		t.Errorf("lookupCalendar cached %d calendars", len(r.cals))
+	}
+
+	// calendars used by jobs are cached, and returned by lookupCalendar
+	if _, err = ReadCalendar("A", dirs); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "A"), []byte("20240104\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cal, err := lookupCalendar("A", dirs)
+	if err != nil || len(cal.DatesIn) != 2 || len(r.cals) != 1 {
+		t.Errorf("lookupCalendar(A) = %v, %v with %d cached; want cached calendar", cal.DatesIn, err, len(r.cals))
+	}
+}
@@ -0,0 +1,440 @@
+package rpeat
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// CalendarRegistry caches the calendars read by ReadCalendar while the server is running,
+// so calendar files are read once rather than on every trigger calculation. The
+// CalendarDirs of cached calendars are watched, with calendars in a changed directory
+// reloaded and swapped in together once writes have settled (CalendarReloadDelay).
+// Jobs using a changed calendar have NextStart recomputed, with calendar errors moving
+// the job to JConfigError rather than stopping the server
+type CalendarRegistry struct {
+	cals     map[string]calEntry
+	watched  map[string]bool
+	watcher  *fsnotify.Watcher
+	onChange func(changed []calEntry)
+	lock     sync.RWMutex
+}
+
+type calEntry struct {
+	calendar string
+	dirs     []string
+	cal      Cal
+	err      error
+}
+
+// CalendarReloadDelay is the time without further changes to a calendar directory
+// before its calendars are reloaded
+var CalendarReloadDelay = 500 * time.Millisecond
+
+// calendars is the registry used by ReadCalendar, with calendars read directly if nil
+var calendars *CalendarRegistry
+
+// newCalendarRegistry returns a registry watching dirs, with onChange called with the
+// calendars that differ after a reload
+func newCalendarRegistry(dirs []string, onChange func(changed []calEntry)) (*CalendarRegistry, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	r := &CalendarRegistry{cals: make(map[string]calEntry), watched: make(map[string]bool), watcher: watcher, onChange: onChange}
+	r.watch(dirs)
+	go r.run()
+	return r, nil
+}
+
+func calendarKey(calendar string, dirs []string) string {
+	return calendar + "\x00" + strings.Join(dirs, string(os.PathListSeparator))
+}
+
+// get returns calendar from the registry, reading it on first use
+func (r *CalendarRegistry) get(calendar string, dirs []string) (Cal, error) {
+	key := calendarKey(calendar, dirs)
+	r.lock.RLock()
+	e, ok := r.cals[key]
+	r.lock.RUnlock()
+	if ok {
+		return e.cal, e.err
+	}
+	cal, err := readCalendar(calendar, dirs)
+	r.lock.Lock()
+	r.cals[key] = calEntry{calendar: calendar, dirs: dirs, cal: cal, err: err}
+	r.lock.Unlock()
+	r.watch(dirs)
+	return cal, err
+}
+
+// peek returns calendar from the registry if cached, or reads it without caching. Used
+// for lookups from the API, which need not name calendars used by the server or a job
+func (r *CalendarRegistry) peek(calendar string, dirs []string) (Cal, error) {
+	r.lock.RLock()
+	e, ok := r.cals[calendarKey(calendar, dirs)]
+	r.lock.RUnlock()
+	if ok {
+		return e.cal, e.err
+	}
+	if isCompositeCalendar(calendar) {
+		return readCompositeCalendar(calendar, dirs, r.peek)
+	}
+	return readCalendar(calendar, dirs)
+}
+
+// watch adds the existing directories of dirs to the watcher
+func (r *CalendarRegistry) watch(dirs []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, dir := range dirs {
+		dir = absDir(dir)
+		if r.watched[dir] {
+			continue
+		}
+		if err := r.watcher.Add(dir); err != nil {
+			ServerLogger.Printf("[Calendar] unable to watch %s: %s", dir, err)
+			continue
+		}
+		r.watched[dir] = true
+		ServerLogger.Printf("[Calendar] watching %s", dir)
+	}
+}
+
+func absDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return filepath.Clean(dir)
+}
+
+// run collects changed directories, reloading them once no changes have been seen
+// for CalendarReloadDelay
+func (r *CalendarRegistry) run() {
+	pending := make(map[string]bool)
+	timer := time.NewTimer(math.MaxInt64)
+	for {
+		select {
+		case evt, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if evt.Op == fsnotify.Chmod {
+				continue
+			}
+			pending[filepath.Dir(evt.Name)] = true
+			timer.Reset(CalendarReloadDelay)
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			ServerLogger.Printf("[Calendar] watch error: %s", err)
+		case <-timer.C:
+			dirs := pending
+			pending = make(map[string]bool)
+			r.reload(dirs)
+		}
+	}
+}
+
+// reload rereads the cached calendars using dirs, replacing them in the registry.
+// Single calendars are replaced before the calendar expressions using them
+func (r *CalendarRegistry) reload(dirs map[string]bool) {
+	var changed []calEntry
+	for _, composite := range []bool{false, true} {
+		r.lock.RLock()
+		var stale []calEntry
+		for _, e := range r.cals {
+			if isCompositeCalendar(e.calendar) == composite && e.usesDir(dirs) {
+				stale = append(stale, e)
+			}
+		}
+		r.lock.RUnlock()
+
+		fresh := make(map[string]calEntry, len(stale))
+		for _, e := range stale {
+			cal, err := readCalendar(e.calendar, e.dirs)
+			if !reflect.DeepEqual(cal, e.cal) || errorString(err) != errorString(e.err) {
+				ServerLogger.Printf("[Calendar] %s reloaded (%d dates)", e.calendar, len(cal.DatesIn))
+				if err != nil {
+					ServerLogger.Printf("[Calendar] %s: %s", e.calendar, err)
+				}
+				e.cal, e.err = cal, err
+				changed = append(changed, e)
+			}
+			fresh[calendarKey(e.calendar, e.dirs)] = e
+		}
+		r.lock.Lock()
+		for key, e := range fresh {
+			r.cals[key] = e
+		}
+		r.lock.Unlock()
+	}
+	if len(changed) > 0 && r.onChange != nil {
+		r.onChange(changed)
+	}
+}
+
+func (e calEntry) usesDir(dirs map[string]bool) bool {
+	for _, dir := range e.dirs {
+		if dirs[absDir(dir)] {
+			return true
+		}
+	}
+	return false
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// calendarsChanged recomputes the NextStart of jobs using the changed calendars
+func (sd *ServerData) calendarsChanged(changed []calEntry) {
+	keys := make(map[string]bool, len(changed))
+	for _, e := range changed {
+		keys[calendarKey(e.calendar, e.dirs)] = true
+	}
+	for _, job := range sd.jobs {
+		if job.Disabled || !keys[calendarKey(job.Calendar, job.CalendarDirs)] {
+			continue
+		}
+		go func(job *Job) {
+			job.runlock.Lock()
+			defer job.runlock.Unlock()
+			ServerLogger.Printf("[Calendar] %s changed - rescheduling %s:%s", job.Calendar, job.JobUUID, job.Name)
+			if job.checkCalendar() {
+				d, next, scheduled := NextCronSchedule(job.cronStartArray)
+				job.resetTimer(d)
+				job.setNextStart(next, scheduled)
+			}
+			job.sendUpdate()
+		}(job)
+	}
+}
+
+// checkCalendar moves the job to JConfigError if its Calendar cannot be read, and back
+// to JReady once it can be, returning false while the calendar is in error
+func (job *Job) checkCalendar() bool {
+	if job.Calendar == "" || job.Calendar == "ALL" {
+		return true
+	}
+	if _, err := ReadCalendar(job.Calendar, job.CalendarDirs); err != nil {
+		ServerLogger.Printf("[Calendar] %s:%s %s", job.JobUUID, job.Name, err)
+		job.Lock()
+		job.ConfigError = err.Error()
+		job.NextStart = ""
+		job.NextStartUNIX = 0
+		job.Unlock()
+		job.t.Stop()
+		job.setJobState(JConfigError)
+		return false
+	}
+	if job.JobState == JConfigError {
+		job.Lock()
+		job.ConfigError = ""
+		job.Unlock()
+		job.setJobState(JReady)
+	}
+	return true
+}
+
+// CalendarInfo describes a calendar known to the server, as listed by /api/calendars
+type CalendarInfo struct {
+	Name         string   `json:"Name"`
+	Source       string   `json:"Source"` // builtin, file, rules or expression
+	CalendarDirs []string `json:"CalendarDirs,omitempty"`
+	First        int      `json:"First,omitempty"`
+	Last         int      `json:"Last,omitempty"`
+	Dates        int      `json:"Dates"`
+	Jobs         []string `json:"Jobs,omitempty"` // jobs using the calendar
+	Error        string   `json:"Error,omitempty"`
+}
+
+// CalendarDay is a date of a calendar, with the available dates either side of it
+// and the jobs triggering on it
+type CalendarDay struct {
+	Date      int      `json:"Date"`
+	Weekday   string   `json:"Weekday"`
+	Available bool     `json:"Available"`
+	Holiday   string   `json:"Holiday,omitempty"`
+	Previous  int      `json:"Previous,omitempty"`
+	Next      int      `json:"Next,omitempty"`
+	Jobs      []string `json:"Jobs,omitempty"`
+}
+
+// CalendarResponse is returned by the /api/calendars endpoints
+type CalendarResponse struct {
+	Status    string         `json:"Status"`
+	Calendars []CalendarInfo `json:"Calendars,omitempty"`
+	Calendar  string         `json:"Calendar,omitempty"`
+	Days      []CalendarDay  `json:"Days,omitempty"`
+}
+
+// MaxCalendarDays limits the range of days returned by calendarDays
+var MaxCalendarDays = 366
+
+// knownCalendars lists the calendar files of dirs and of the CalendarDirs of jobs, the
+// built-in calendars and the calendar expressions used by jobs
+func knownCalendars(dirs []string, jobs []*Job) []CalendarInfo {
+	var infos []CalendarInfo
+	index := make(map[string]int)
+	add := func(name, source string, cdirs []string) {
+		if _, ok := index[name]; !ok {
+			index[name] = len(infos)
+			infos = append(infos, CalendarInfo{Name: name, Source: source, CalendarDirs: cdirs})
+		}
+	}
+
+	seen := make(map[string]bool)
+	scan := func(cdirs []string) {
+		for _, dir := range cdirs {
+			if seen[dir] {
+				continue
+			}
+			seen[dir] = true
+			files, err := ioutil.ReadDir(dir)
+			if err != nil {
+				continue
+			}
+			for _, f := range files {
+				if f.Mode().IsRegular() && !strings.HasPrefix(f.Name(), ".") {
+					add(f.Name(), "file", cdirs)
+				}
+			}
+		}
+	}
+	scan(dirs)
+	for _, job := range jobs {
+		scan(job.CalendarDirs)
+	}
+	var builtins []string
+	for name := range BuiltinCalendars {
+		builtins = append(builtins, name)
+	}
+	sort.Strings(builtins)
+	for _, name := range builtins {
+		add(name, "builtin", nil)
+	}
+	for _, job := range jobs {
+		if job.Calendar == "" || job.Calendar == "ALL" {
+			continue
+		}
+		if isCompositeCalendar(job.Calendar) {
+			add(job.Calendar, "expression", job.CalendarDirs)
+		} else {
+			add(job.Calendar, "file", job.CalendarDirs)
+		}
+		info := &infos[index[job.Calendar]]
+		info.Jobs = append(info.Jobs, job.Name)
+	}
+
+	for i := range infos {
+		cal, err := ReadCalendar(infos[i].Name, infos[i].CalendarDirs)
+		if err != nil {
+			infos[i].Error = err.Error()
+			continue
+		}
+		if infos[i].Source == "file" && cal.Rules != nil {
+			infos[i].Source = "rules"
+		}
+		if n := len(cal.DatesIn); n > 0 {
+			infos[i].First, infos[i].Last, infos[i].Dates = cal.DatesIn[0], cal.DatesIn[n-1], n
+		}
+	}
+	return infos
+}
+
+// calendarDays returns the days from through to of cal, with the jobs triggering on each
+func calendarDays(cal Cal, from, to time.Time, jobs []*Job) ([]CalendarDay, error) {
+	if to.Before(from) {
+		return nil, errors.New(fmt.Sprintf("%s is before %s", to.Format("20060102"), from.Format("20060102")))
+	}
+	if n := int(to.Sub(from).Hours()/24) + 1; n > MaxCalendarDays {
+		return nil, errors.New(fmt.Sprintf("%d days requested - limited to %d", n, MaxCalendarDays))
+	}
+	triggers := make(map[int][]string)
+	for _, job := range jobs {
+		for dt := range job.triggerDays(from, to) {
+			triggers[dt] = append(triggers[dt], job.Name)
+		}
+	}
+	var days []CalendarDay
+	for t := from; !t.After(to); t = t.AddDate(0, 0, 1) {
+		dt := dateAsInt(t)
+		day := CalendarDay{Date: dt, Weekday: t.Weekday().String()[:3], Holiday: cal.Holidays[dt], Jobs: triggers[dt]}
+		sort.Strings(day.Jobs)
+		i := sort.SearchInts(cal.DatesIn, dt)
+		day.Available = i < len(cal.DatesIn) && cal.DatesIn[i] == dt
+		if i > 0 {
+			day.Previous = cal.DatesIn[i-1]
+		}
+		if day.Available {
+			i++
+		}
+		if i < len(cal.DatesIn) {
+			day.Next = cal.DatesIn[i]
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// triggerDays returns the dates from through to on which CronStart triggers the job,
+// in the job Timezone
+func (job *Job) triggerDays(from, to time.Time) map[int]bool {
+	days := make(map[int]bool)
+	loc := calendarLocation(job.Timezone)
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, loc)
+	for _, c := range job.cronStartArray {
+		if c.IsNull() || c.isDependent() || c.IsEvery() {
+			continue
+		}
+		t := start.Add(-time.Second)
+		for i := 0; i <= MaxCalendarDays; i++ {
+			_, next, _ := c.NextStartAfter(t)
+			if next.IsZero() || !next.Before(end) {
+				break
+			}
+			next = next.In(loc)
+			days[dateAsInt(next)] = true
+			// only the first trigger of each day is needed
+			t = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, loc).Add(-time.Second)
+		}
+	}
+	return days
+}
+
+// parseCalendarDate parses a YYYYMMDD date, with the empty string being today
+func parseCalendarDate(date string, loc *time.Location) (time.Time, error) {
+	if date == "" {
+		y, m, d := time.Now().In(loc).Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, loc), nil
+	}
+	t, err := time.ParseInLocation("20060102", date, loc)
+	if err != nil {
+		return t, errors.New(fmt.Sprintf("invalid date %s (expecting YYYYMMDD)", date))
+	}
+	return t, nil
+}
+
+// calendarLocation is the location of timezone, or UTC if not valid
+func calendarLocation(timezone string) *time.Location {
+	if loc, err := time.LoadLocation(timezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
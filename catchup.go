@@ -0,0 +1,193 @@
+package rpeat
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CatchUpPolicy defines how CronStart triggers missed while the server was down,
+// or skipped by a jump in the system clock, are handled:
+//
+//	none                 (default) missed triggers are ignored and the next future trigger is scheduled
+//	latest               only the most recent missed trigger is run
+//	all                  every missed trigger is run, oldest first
+//	all-within-duration  as all, limited to triggers within CatchUpWithin of the current time
+//
+// Each missed run is evaluated with the missed trigger time as the asof time, so
+// DateEnv and Cmd date variables resolve to the date the run was scheduled for.
+type CatchUpPolicy int
+
+const (
+	CatchUpNone CatchUpPolicy = iota
+	CatchUpLatest
+	CatchUpAll
+	CatchUpAllWithin
+)
+
+const (
+	// MAX_CATCHUP limits the number of missed triggers queued for a job
+	MAX_CATCHUP = 1000
+)
+
+var catchUpNames = [...]string{"none", "latest", "all", "all-within-duration"}
+
+func (p CatchUpPolicy) String() string {
+	if p < CatchUpNone || p > CatchUpAllWithin {
+		return "Unknown"
+	}
+	return catchUpNames[p]
+}
+
+// ParseCatchUp converts a CatchUp name, with the empty string being the default none
+func ParseCatchUp(policy string) (CatchUpPolicy, error) {
+	if policy == "" {
+		return CatchUpNone, nil
+	}
+	for i, name := range catchUpNames {
+		if strings.EqualFold(policy, name) {
+			return CatchUpPolicy(i), nil
+		}
+	}
+	return CatchUpNone, errors.New(fmt.Sprintf("unrecognized CatchUp %s (expecting one of %s)", policy, strings.Join(catchUpNames[:], ", ")))
+}
+
+// MissedCronStarts returns all triggers of cron after after and no later than
+// before in time order, keeping at most the MAX_CATCHUP most recent. If within
+// is positive, only triggers within it of before are returned.
+// Triggers are the scheduled times, without Jitter.
+// @every and non-triggering crons have no missed triggers.
+func MissedCronStarts(cron []Cron, after, before time.Time, within time.Duration) (missed []time.Time) {
+	since := after
+	if within > 0 && before.Add(-within).After(after) {
+		since = before.Add(-within)
+		after = since.Add(-time.Second) // triggers are whole seconds, keeping any at since
+	}
+	for _, c := range withoutJitter(cron) {
+		if c.IsEvery() || c.IsNull() || c.isDependent() {
+			continue
+		}
+		missed = append(missed, missedCronStarts(c, after, before)...)
+	}
+	missed = sortedUniqueTimes(missed)
+	for len(missed) > 0 && missed[0].Before(since) {
+		missed = missed[1:]
+	}
+	if len(missed) > MAX_CATCHUP {
+		missed = missed[len(missed)-MAX_CATCHUP:]
+	}
+	return
+}
+
+// missedCronStarts returns the MAX_CATCHUP most recent triggers of c after after and
+// no later than before. Rather than walking every trigger of a long outage, once
+// MAX_CATCHUP are found the walk skips ahead to the span they cover before before
+func missedCronStarts(c Cron, after, before time.Time) (missed []time.Time) {
+	t := after
+	for {
+		_, next, err := c.NextStartAfter(t)
+		if err != nil || next.IsZero() || next.After(before) || !next.After(t) {
+			return
+		}
+		missed = append(missed, next)
+		t = next
+		if len(missed) > MAX_CATCHUP {
+			missed = missed[len(missed)-MAX_CATCHUP:]
+			if resume := before.Add(-next.Sub(missed[0]) - time.Second); resume.After(t) {
+				missed = missed[:0]
+				t = resume
+			}
+		}
+	}
+}
+
+// sortedUniqueTimes sorts ts in place, removing duplicate times (e.g. from multiple
+// rules triggering at the same time)
+func sortedUniqueTimes(ts []time.Time) []time.Time {
+	sort.Slice(ts, func(i, j int) bool { return ts[i].Before(ts[j]) })
+	n := 0
+	for i := range ts {
+		if n > 0 && ts[i].Equal(ts[n-1]) {
+			continue
+		}
+		ts[n] = ts[i]
+		n++
+	}
+	return ts[:n]
+}
+
+// queueMissed adds the CronStart triggers missed between after and before to
+// the job's catch-up queue according to its CatchUp policy, returning the number queued
+func (job *Job) queueMissed(after, before time.Time) int {
+	if job.catchUp == CatchUpNone || job.Hold || after.IsZero() {
+		return 0
+	}
+	var within time.Duration
+	if job.catchUp == CatchUpAllWithin {
+		within = job.catchUpWithin
+	}
+	missed := MissedCronStarts(job.cronStartArray, after, before, within)
+	if job.catchUp == CatchUpLatest && len(missed) > 1 {
+		missed = missed[len(missed)-1:]
+	}
+	if len(missed) == 0 {
+		return 0
+	}
+	job.Lock()
+	if job.catchUp == CatchUpLatest {
+		job.missed = missed
+	} else {
+		job.missed = append(job.missed, missed...)
+		if len(job.missed) > MAX_CATCHUP {
+			job.missed = job.missed[len(job.missed)-MAX_CATCHUP:]
+		}
+	}
+	job.Unlock()
+	ServerLogger.Printf("CatchUp (%s) queued %d missed trigger(s) for %s:%s between %s and %s", job.catchUp, len(missed), job.JobUUID, job.Name, after.In(job._location).Format("2006-01-02 15:04:05"), before.In(job._location).Format("2006-01-02 15:04:05"))
+	return len(missed)
+}
+
+// nextMissed removes the oldest queued missed trigger, returning it as the asof
+// string used by DateEnv. An empty string is returned if no triggers are queued
+func (job *Job) nextMissed() string {
+	job.Lock()
+	defer job.Unlock()
+	if len(job.missed) == 0 {
+		return ""
+	}
+	t := job.missed[0]
+	job.missed = job.missed[1:]
+	ServerLogger.Printf("CatchUp running missed trigger %s for %s:%s (%d remaining)", t.In(job._location).Format("2006-01-02 15:04:05"), job.JobUUID, job.Name, len(job.missed))
+	return t.In(job._location).Format("20060102150405")
+}
+
+// triggerAsOf returns the scheduled time of the current trigger as the asof string used
+// by DateEnv if DateEnvAsOf is trigger, or an empty string to use the time the run starts
+func (job *Job) triggerAsOf() string {
+	job.Lock()
+	defer job.Unlock()
+	if job.dateEnvAsOf != AsOfTrigger || job.Unscheduled || job.scheduled.IsZero() || job.NextStartUNIX == math.MaxInt64 {
+		return ""
+	}
+	return job.scheduled.In(job._location).Format("20060102150405")
+}
+
+// clearMissed discards any queued missed triggers
+func (job *Job) clearMissed() {
+	job.Lock()
+	job.missed = nil
+	job.Unlock()
+}
+
+// catchUpDelay returns d, or zero to trigger immediately while missed triggers are queued
+func (job *Job) catchUpDelay(d time.Duration) time.Duration {
+	job.Lock()
+	defer job.Unlock()
+	if len(job.missed) > 0 {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,44 @@
+package rpeat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMissedCronStarts(t *testing.T) {
+	c, err := ParseCron("0 * * * * *", "UTC", "", nil, false, false, 30)
+	if err != nil {
+		t.Fatalf("ParseCron: %s", err)
+	}
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := after.AddDate(0, 3, 0)
+	tests := []struct {
+		name   string
+		within time.Duration
+		n      int
+		first  time.Time
+	}{
+		{"all", 0, MAX_CATCHUP, before.Add(-(MAX_CATCHUP - 1) * time.Minute)},
+		{"within", time.Hour, 61, before.Add(-time.Hour)},
+		{"within before after", 365 * 24 * time.Hour, MAX_CATCHUP, before.Add(-(MAX_CATCHUP - 1) * time.Minute)},
+	}
+	for _, tt := range tests {
+		start := time.Now()
+		missed := MissedCronStarts([]Cron{c}, after, before, tt.within)
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("%s: took %s", tt.name, elapsed)
+		}
+		if len(missed) != tt.n {
+			t.Fatalf("%s: %d missed; want %d", tt.name, len(missed), tt.n)
+		}
+		if !missed[0].Equal(tt.first) || !missed[len(missed)-1].Equal(before) {
+			t.Errorf("%s: missed %s to %s; want %s to %s", tt.name, missed[0], missed[len(missed)-1], tt.first, before)
+		}
+		for _, m := range missed {
+			if m.Second() != 0 {
+				t.Errorf("%s: missed %s includes jitter", tt.name, m)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package rpeat
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"unicode"
+)
+
+// Composite calendars are expressions over named calendars, accepted wherever a calendar
+// name is (Calendar, DateEnv ",CAL" and rpeat-util -cal):
+//
+//	A & B    intersection: dates in both A and B (e.g. days both markets are open)
+//	A | B    union: dates in either A or B
+//	A - B    exclusion: dates in A that are not in B
+//
+// Operators are evaluated left to right, with parentheses used for grouping, e.g.
+// "(NYSE & UK) - closures". Calendar names may contain "-" but not begin with it, so
+// exclusion requires a space before the "-".
+//
+// The dates removed from the operands are recorded in DatesEx of the resulting Cal.
+
+// tokenizeCalendar splits a calendar expression into names and operators
+func tokenizeCalendar(expr string) []string {
+	var tokens []string
+	var name []rune
+	flush := func() {
+		if len(name) > 0 {
+			tokens = append(tokens, string(name))
+			name = name[:0]
+		}
+	}
+	for _, r := range expr {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r == '&' || r == '|' || r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case r == '-' && len(name) == 0:
+			tokens = append(tokens, "-")
+		default:
+			name = append(name, r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// isCompositeCalendar is true if calendar is an expression rather than a single name
+func isCompositeCalendar(calendar string) bool {
+	return len(tokenizeCalendar(calendar)) > 1
+}
+
+type calParser struct {
+	tokens       []string
+	pos          int
+	calendarPath []string
+	read         func(string, []string) (Cal, error)
+}
+
+func isCalOperator(token string) bool {
+	return token == "&" || token == "|" || token == "-"
+}
+
+// readCompositeCalendar evaluates the calendar expression calendar, reading the calendars
+// named with read
+func readCompositeCalendar(calendar string, calendarPath []string, read func(string, []string) (Cal, error)) (Cal, error) {
+	p := &calParser{tokens: tokenizeCalendar(calendar), calendarPath: calendarPath, read: read}
+	cal, err := p.expr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = errors.New(fmt.Sprintf("unexpected %q", p.tokens[p.pos]))
+	}
+	if err != nil {
+		if _, ok := err.(CalendarError); ok {
+			return Cal{}, err
+		}
+		return Cal{}, CalendarError{Exception: CalendarReadError, Calendar: calendar, CalendarDirs: calendarPath, fileErr: fmt.Errorf("calendar expression %q: %s", calendar, err)}
+	}
+	cal.Calendar = calendar
+	return cal, nil
+}
+
+func (p *calParser) expr() (Cal, error) {
+	left, err := p.term()
+	if err != nil {
+		return left, err
+	}
+	for p.pos < len(p.tokens) && isCalOperator(p.tokens[p.pos]) {
+		op := p.tokens[p.pos]
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return left, err
+		}
+		left = combineCals(op, left, right)
+	}
+	return left, nil
+}
+
+func (p *calParser) term() (Cal, error) {
+	if p.pos >= len(p.tokens) {
+		return Cal{}, errors.New("missing calendar")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	switch {
+	case token == "(":
+		cal, err := p.expr()
+		if err != nil {
+			return cal, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return cal, errors.New("missing )")
+		}
+		p.pos++
+		return cal, nil
+	case token == ")" || isCalOperator(token):
+		return Cal{}, errors.New(fmt.Sprintf("unexpected %q", token))
+	}
+	return p.read(token, p.calendarPath)
+}
+
+// combineCals applies the operator op (&, | or -) to the dates of a and b
+func combineCals(op string, a, b Cal) Cal {
+	in := make(map[int]bool, len(b.DatesIn))
+	for _, dt := range b.DatesIn {
+		in[dt] = true
+	}
+	var dates, excluded []int
+	switch op {
+	case "&":
+		inA := make(map[int]bool, len(a.DatesIn))
+		for _, dt := range a.DatesIn {
+			inA[dt] = true
+			if in[dt] {
+				dates = append(dates, dt)
+			} else {
+				excluded = append(excluded, dt)
+			}
+		}
+		for _, dt := range b.DatesIn {
+			if !inA[dt] {
+				excluded = append(excluded, dt)
+			}
+		}
+	case "|":
+		dates = append(dates, b.DatesIn...)
+		for _, dt := range a.DatesIn {
+			if !in[dt] {
+				dates = append(dates, dt)
+			}
+		}
+	case "-":
+		for _, dt := range a.DatesIn {
+			if in[dt] {
+				excluded = append(excluded, dt)
+			} else {
+				dates = append(dates, dt)
+			}
+		}
+	}
+	sort.Ints(dates)
+
+	cal := NewCal(a.Calendar + " " + op + " " + b.Calendar)
+	cal.DatesIn = dates
+	cal.DatesEx = uniqueInts(append(append(append([]int{}, a.DatesEx...), b.DatesEx...), excluded...))
+
+	// holidays of the operands that remain closed
+	result := make(map[int]bool, len(dates))
+	for _, dt := range dates {
+		result[dt] = true
+	}
+	for _, h := range []map[int]string{a.Holidays, b.Holidays} {
+		for dt, name := range h {
+			if result[dt] {
+				continue
+			}
+			if cal.Holidays == nil {
+				cal.Holidays = make(map[int]string)
+			}
+			if prev, ok := cal.Holidays[dt]; ok && prev != name {
+				name = prev + ", " + name
+			}
+			cal.Holidays[dt] = name
+		}
+	}
+	return cal
+}
+
+// uniqueInts sorts x removing duplicates
+func uniqueInts(x []int) []int {
+	sort.Ints(x)
+	n := 0
+	for i := range x {
+		if n > 0 && x[i] == x[n-1] {
+			continue
+		}
+		x[n] = x[i]
+		n++
+	}
+	return x[:n]
+}
@@ -0,0 +1,161 @@
+package rpeat
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenizeCalendar(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"NYSE", "NYSE"},
+		{"US-FED", "US-FED"},
+		{"NYSE & UK", "NYSE,&,UK"},
+		{"MF - US_BANK", "MF,-,US_BANK"},
+		{"MF -US_BANK", "MF,-,US_BANK"},
+		{"MF-US_BANK", "MF-US_BANK"},
+		{"A - B-C", "A,-,B-C"},
+		{"A--B", "A--B"},
+		{"(A|B)&C", "(,A,|,B,),&,C"},
+		{"(NYSE & UK) - closures", "(,NYSE,&,UK,),-,closures"},
+		{"  A  |  B  ", "A,|,B"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tokenizeCalendar(tt.expr), ","); got != tt.want {
+			t.Errorf("tokenizeCalendar(%q) = %s; want %s", tt.expr, got, tt.want)
+		}
+	}
+	if isCompositeCalendar("US-FED") || !isCompositeCalendar("MF - US_BANK") {
+		t.Error("isCompositeCalendar")
+	}
+}
+
+func TestCombineCals(t *testing.T) {
+	a := Cal{Calendar: "A", DatesIn: []int{1, 2, 3}, DatesEx: []int{9}, Holidays: map[int]string{4: "a4", 5: "a5"}}
+	b := Cal{Calendar: "B", DatesIn: []int{2, 3, 4}, Holidays: map[int]string{1: "b1", 5: "b5"}}
+	tests := []struct {
+		op       string
+		in       string
+		ex       string
+		holidays string
+	}{
+		{"&", "[2 3]", "[1 4 9]", "map[1:b1 4:a4 5:a5, b5]"},
+		{"|", "[1 2 3 4]", "[9]", "map[5:a5, b5]"},
+		{"-", "[1]", "[2 3 9]", "map[4:a4 5:a5, b5]"},
+	}
+	for _, tt := range tests {
+		cal := combineCals(tt.op, a, b)
+		if cal.Calendar != "A "+tt.op+" B" {
+			t.Errorf("%s: Calendar %q", tt.op, cal.Calendar)
+		}
+		if got := fmt.Sprint(cal.DatesIn); got != tt.in {
+			t.Errorf("%s: DatesIn %s; want %s", tt.op, got, tt.in)
+		}
+		if got := fmt.Sprint(cal.DatesEx); got != tt.ex {
+			t.Errorf("%s: DatesEx %s; want %s", tt.op, got, tt.ex)
+		}
+		if got := fmt.Sprint(cal.Holidays); got != tt.holidays {
+			t.Errorf("%s: Holidays %s; want %s", tt.op, got, tt.holidays)
+		}
+	}
+	if fmt.Sprint(a.DatesIn, a.DatesEx) != "[1 2 3] [9]" || fmt.Sprint(b.DatesIn) != "[2 3 4]" {
+		t.Errorf("operands modified: %v %v %v", a.DatesIn, a.DatesEx, b.DatesIn)
+	}
+}
+
+// writeCalendars writes calendar files of the dates of cals to a temporary directory
+func writeCalendars(t *testing.T, cals map[string][]int) string {
+	dir, err := ioutil.TempDir("", "rpeat-cal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, dates := range cals {
+		var lines []string
+		for _, dt := range dates {
+			lines = append(lines, fmt.Sprint(dt))
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReadCompositeCalendar(t *testing.T) {
+	dir := writeCalendars(t, map[string][]int{
+		"A":   {20240101, 20240102},
+		"B":   {20240103, 20240104},
+		"C":   {20240102, 20240103},
+		"B-C": {20240105},
+	})
+	defer os.RemoveAll(dir)
+	dirs := []string{dir}
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"A | B", "[20240101 20240102 20240103 20240104]"},
+		{"A | B & C", "[20240102 20240103]"}, // (A | B) & C
+		{"A | (B & C)", "[20240101 20240102 20240103]"},
+		{"C & B | A", "[20240101 20240102 20240103]"},
+		{"A | B - C", "[20240101 20240104]"}, // (A | B) - C
+		{"A | (B - C)", "[20240101 20240102 20240104]"},
+		{"((A | B)) - (C)", "[20240101 20240104]"},
+		{"A | B-C", "[20240101 20240102 20240105]"}, // B-C is a name
+		{"A & B", "[]"},
+	}
+	for _, tt := range tests {
+		cal, err := readCalendar(tt.expr, dirs)
+		if err != nil {
+			t.Errorf("%s: %s", tt.expr, err)
+			continue
+		}
+		if got := fmt.Sprint(cal.DatesIn); got != tt.want {
+			t.Errorf("%s = %s; want %s", tt.expr, got, tt.want)
+		}
+		if cal.Calendar != tt.expr {
+			t.Errorf("%s: Calendar %q", tt.expr, cal.Calendar)
+		}
+	}
+	for _, expr := range []string{"A &", "& A", "A | (B", "A )", "(A) B", "A & ()", "A & missing"} {
+		if _, err := readCalendar(expr, dirs); err == nil {
+			t.Errorf("%s: no error", expr)
+		}
+	}
+}
+
+func TestCompositeCalendarExample(t *testing.T) {
+	// business days: weekdays less bank holidays
+	var weekdays []int
+	for d := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); d.Month() == time.January; d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			weekdays = append(weekdays, dateAsInt(d))
+		}
+	}
+	dir := writeCalendars(t, map[string][]int{"MF": weekdays, "US_BANK": {20240101, 20240115}})
+	defer os.RemoveAll(dir)
+
+	cal, err := ReadCalendar("MF - US_BANK", []string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cal.DatesIn) != len(weekdays)-2 || cal.DatesIn[0] != 20240102 || fmt.Sprint(cal.DatesEx) != "[20240101 20240115]" {
+		t.Errorf("MF - US_BANK = %v excluding %v", cal.DatesIn, cal.DatesEx)
+	}
+	for _, dt := range cal.DatesIn {
+		if dt == 20240115 {
+			t.Error("MF - US_BANK includes 20240115")
+		}
+	}
+	next, err := addDayWithCal(time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC), 1, "MF - US_BANK", []string{dir}, true)
+	if err != nil || next.Format("20060102") != "20240116" {
+		t.Errorf("next business day after 20240112 = %s, %v; want 20240116", next.Format("20060102"), err)
+	}
+}
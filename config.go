@@ -85,6 +85,7 @@ type ServerData struct {
 	pidfile      string
 	shutdownJobs func()
 	jve          map[uuid.UUID]JobValidationExceptions
+	calendars    *CalendarRegistry
 }
 
 // find job by UUID or slug
@@ -130,17 +131,21 @@ type ServerConfig struct {
 	Permissions      Permission
 	Origin           int64 `json:"Origin,omitempty" xml:"Origin,omitempty"`
 	Timezone         string
-	Clean            bool       `json:"Clean,omitempty" xml:"Clean,omitempty"`
-	KeepHistory      bool       `json:"KeepHistory,omitempty" xml:"KeepHistory,omitempty"`
-	MaxHistory       int        `json:"MaxHistory,omitempty" xml:"MaxHistory,omitempty"`
-	Heartbeat        bool       `json:"Heartbeat" xml:"Heartbeat"`
-	CalendarDirs     []string   `json:"CalendarDirs,omitempty"`
-	Theme            string     `json:"Theme,omitempty" xml:"Theme,omitempty"`
-	ThemeDir         string     `json:"ThemeDir,omitempty" xml:"ThemeDir,omitempty"`
-	UseRelativePaths bool       `json:"UseRelativePaths,omiyempty" xml:"UseRelativePaths,omitempty"`
-	TempDir          string     `json:"TmpDir" xml:"TmpDir"`
-	Logging          JobLogging `json:"JobLogging" xml:"JobLogging"`
-	Jobs             []Job      `json:"-"`
+	Clean            bool           `json:"Clean,omitempty" xml:"Clean,omitempty"`
+	KeepHistory      bool           `json:"KeepHistory,omitempty" xml:"KeepHistory,omitempty"`
+	MaxHistory       int            `json:"MaxHistory,omitempty" xml:"MaxHistory,omitempty"`
+	Heartbeat        bool           `json:"Heartbeat" xml:"Heartbeat"`
+	CalendarDirs     []string       `json:"CalendarDirs,omitempty"`
+	Theme            string         `json:"Theme,omitempty" xml:"Theme,omitempty"`
+	ThemeDir         string         `json:"ThemeDir,omitempty" xml:"ThemeDir,omitempty"`
+	UseRelativePaths bool           `json:"UseRelativePaths,omiyempty" xml:"UseRelativePaths,omitempty"`
+	TempDir          string         `json:"TmpDir" xml:"TmpDir"`
+	Logging          JobLogging     `json:"JobLogging" xml:"JobLogging"`
+	Pools            map[string]int `json:"Pools,omitempty" xml:"-"`         // name: slots, see Pool
+	PriorityAging    string         `json:"PriorityAging,omitempty" xml:"-"` // e.g. "5m", see PriorityAging
+	Blackouts        []Blackout     `json:"Blackouts,omitempty" xml:"-"`
+	CgroupRoot       string         `json:"CgroupRoot,omitempty" xml:"-"` // delegated cgroup v2 directory, see Limits
+	Jobs             []Job          `json:"-"`
 }
 
 func (k ServerConfig) Abs(p string) string {
@@ -439,54 +444,143 @@ func LoadJobSpec(config string, maxhistory int, templates map[string]JobSpec, sc
 		ljobs := 0
 		if !specs[i].Disabled {
 			ljobs = len(specs[i].Jobs)
+			if validateMatrix(specs[i].Matrix, specs[i].Jobs) == nil && specs[i].Matrix != nil {
+				ljobs = len(specs[i].Matrix.combinations())
+			}
 		}
 		njobs = njobs + 1 + ljobs
 	}
 
 	jobs := make([]Job, njobs)
 
-	j := 0
-	var currentUUID uuid.UUID
-
-	for i := range specs {
-		// specs are top-level jobs which may contain .Jobs themselves (max one-level nesting)
-		// i iterates over top-level
-		// j iterates over top-level PLUS Jobs
-		jobs[j] = Job{Disabled: true, Timezone: sconf.Timezone, MaxHistory: sconf.MaxHistory, ServerName: sconf.Name, ServerKey: serverkey, src: config, apiKey: sconf.ApiKey}
+	// newJob constructs job from spec, including any inherited template
+	newJob := func(job *Job, spec *JobSpec) {
+		*job = Job{Disabled: true, Timezone: sconf.Timezone, MaxHistory: sconf.MaxHistory, ServerName: sconf.Name, ServerKey: serverkey, src: config, apiKey: sconf.ApiKey}
 
 		// copy inherited template (if any) into new Job[j]
-		if specs[i].Inherits != nil && !specs[i].isTemplate() {
-			tmpspec := JobSpec{Name: specs[i].Name}
+		if spec.Inherits != nil && !spec.isTemplate() {
+			tmpspec := JobSpec{Name: spec.Name}
 			env := make(EnvList, 0)
 			tmpspec.Env = &env
 			dateenv := make(EnvList, 0)
 			tmpspec.DateEnv = &dateenv
-			if tmpl, ok := templates[*(specs[i].Inherits)]; ok {
+			if tmpl, ok := templates[*(spec.Inherits)]; ok {
 				tmpspec.copyTemplate(tmpl)
-				jobs[j].copyJobSpec(&tmpspec)
-				jobs[j].InheritanceChain = tmpl.InheritanceChain
+				job.copyJobSpec(&tmpspec)
+				job.InheritanceChain = tmpl.InheritanceChain
 			} else {
-				msg := fmt.Sprintf("Template '%#s' Not Found for '%s'", *(specs[i].Inherits), specs[i].Name)
-				jobs[j].jve.AddWarning(ValidationWarning{Msg: msg, Exception: Template})
+				msg := fmt.Sprintf("Template '%#s' Not Found for '%s'", *(spec.Inherits), spec.Name)
+				job.jve.AddWarning(ValidationWarning{Msg: msg, Exception: Template})
 			}
-			jobs[j].LocalEnv = EnvList([]string{})
-			jobs[j].LocalDateEnv = EnvList([]string{})
+			job.LocalEnv = EnvList([]string{})
+			job.LocalDateEnv = EnvList([]string{})
 		}
 
 		// copy user defined specs into Job[j]
-		jobs[j].copyJobSpec(&specs[i])
+		job.copyJobSpec(spec)
 		if logging.Purge != "" {
-			if specs[j].Logging == nil {
-				ServerLogger.Printf("adding log rotation to %s", jobs[j].JobUUID)
-				jobs[j].Logging.Purge = logging.Purge
+			if spec.Logging == nil {
+				ServerLogger.Printf("adding log rotation to %s", job.JobUUID)
+				job.Logging.Purge = logging.Purge
 			}
 		}
+	}
+
+	j := 0
+	var currentUUID uuid.UUID
+
+	for i := range specs {
+		// specs are top-level jobs which may contain .Jobs themselves (max one-level nesting)
+		// i iterates over top-level
+		// j iterates over top-level PLUS Jobs (or Matrix children)
+		newJob(&jobs[j], &specs[i])
 		parentJob := jobs[j]
 		currentUUID = jobs[j].JobUUID
 		specs[i].JobUUID = currentUUID
 		if jobs[j].Disabled {
 			continue
 		}
+		matrixErr := validateMatrix(specs[i].Matrix, specs[i].Jobs)
+		if matrixErr != nil {
+			jobs[j].jve.AddError(ValidationError{JobName: jobs[j].Name, Msg: matrixErr.Error(), Exception: Parse})
+		}
+		if m := specs[i].Matrix; m != nil && matrixErr == nil { // MATRIX
+			p := j
+			group := append(append([]string{}, parentJob.Group...), parentJob.Name)
+			delay := "300ms"
+			if specs[i].JobsControl != nil && specs[i].JobsControl.Delay != "" {
+				delay = specs[i].JobsControl.Delay
+			}
+			depends := "@depends"
+			control_dependencies_success := make(map[string]string)
+			control_dependencies_stopped := make(map[string]string)
+			control_dependencies_failed := make(map[string]string)
+			if m.Parent {
+				jobs[p].Type = CONTROLLER
+				jobs[p].Group = group
+				groups[group[0]] = append(groups[group[0]], jobs[p].JobUUID)
+			} else {
+				jobs[p].Disabled = true // only the children are run
+			}
+			for _, combo := range m.combinations() {
+				j++
+				child := specs[i]
+				child.Name = matrixName(specs[i].Name, combo)
+				child.JobUUID = uuid.NewSHA1(parentJob.JobUUID, []byte(child.Name))
+				child.Matrix = nil
+				env := make(EnvList, 0)
+				if specs[i].Env != nil {
+					env = append(env, *specs[i].Env...)
+				}
+				env = append(env, combo...)
+				child.Env = &env
+				newJob(&jobs[j], &child)
+				jobs[j].Group = group
+				jobs[j].Parent = parentJob.Name
+				groups[group[0]] = append(groups[group[0]], jobs[j].JobUUID)
+				if !m.Parent {
+					continue
+				}
+				jobs[j].Type = JOJ
+				jobs[j].JobsControl = parentJob.JobsControl
+				jobs[j].CronStart = &depends
+				jobs[j].CronStartArray = nil
+				jobs[j].StartTime = ""
+				jobs[j].Hold = false
+				jobs[j].Dependency = []Dependency{
+					// start all children when the controlling Job starts
+					Dependency{Dependencies: map[string]string{parentJob.JobUUID.String(): "running"},
+						Action: "start", Condition: "all", Delay: delay},
+
+					// stop job if controlling Job is stopped
+					Dependency{Dependencies: map[string]string{parentJob.JobUUID.String(): "stopped"},
+						Action: "stop", Condition: "all", Delay: "100ms"},
+
+					// reset back to ready state if controlling Job completes
+					Dependency{Dependencies: map[string]string{parentJob.JobUUID.String(): "success|failed"},
+						Action: "ready", Condition: "all", Delay: "1s"},
+
+					// reset back to ready state if controlling Job is reset to ready - by unhold
+					Dependency{Dependencies: map[string]string{parentJob.JobUUID.String(): "ready"},
+						Action: "ready", Condition: "all", Delay: "1s"},
+				}
+				control_dependencies_success[jobs[j].JobUUID.String()] = "success"
+				control_dependencies_stopped[jobs[j].JobUUID.String()] = "stopped"
+				control_dependencies_failed[jobs[j].JobUUID.String()] = "failed|limitexceeded"
+			}
+			if m.Parent {
+				jobs[p].Dependency = []Dependency{
+					Dependency{Dependencies: control_dependencies_success,
+						Action: "completed_success", Condition: "all", Delay: "100ms"},
+					Dependency{Dependencies: control_dependencies_stopped,
+						Action: "completed_stopped", Condition: "any", Delay: "100ms", N: 1},
+					Dependency{Dependencies: control_dependencies_failed,
+						Action: "completed_failed", Condition: "any", Delay: "100ms", N: 1},
+				}
+			}
+			j++
+			continue
+		}
 		groups[jobs[j].Group[0]] = append(groups[jobs[j].Group[0]], jobs[j].JobUUID)
 		if specs[i].Jobs != nil { // CONTROLLER
 			p := j
@@ -555,7 +649,7 @@ func LoadJobSpec(config string, maxhistory int, templates map[string]JobSpec, sc
 				jobs[j].CronStart = &depends
 				control_dependencies_success[currentUUID.String()] = "success" //need to count failures
 				control_dependencies_stopped[currentUUID.String()] = "stopped"
-				control_dependencies_failed[currentUUID.String()] = "failed"
+				control_dependencies_failed[currentUUID.String()] = "failed|limitexceeded"
 
 				// inherit certain fields from parent FIXME: should be a function
 				if jobs[j].Permissions == nil {
@@ -564,6 +658,12 @@ func LoadJobSpec(config string, maxhistory int, templates map[string]JobSpec, sc
 				if jobs[j].User == "" {
 					jobs[j].User = parentJob.User
 				}
+				if jobs[j].RunAs == nil {
+					jobs[j].RunAs = parentJob.RunAs
+				}
+				if jobs[j].Limits == nil {
+					jobs[j].Limits = parentJob.Limits
+				}
 				if jobs[j].Env == nil {
 					jobs[j].Env = parentJob.Env
 					// should be able to override Env as well as update ?
@@ -572,6 +672,25 @@ func LoadJobSpec(config string, maxhistory int, templates map[string]JobSpec, sc
 					jobs[j].DateEnv = parentJob.DateEnv
 					// should be able to override Env as well as update ?
 				}
+				if jobs[j].Shell == "" {
+					jobs[j].Shell = parentJob.Shell
+				}
+				if jobs[j].WorkDir == "" {
+					jobs[j].WorkDir = parentJob.WorkDir
+					jobs[j].CreateWorkDir = parentJob.CreateWorkDir
+				}
+				if jobs[j].Umask == "" {
+					jobs[j].Umask = parentJob.Umask
+				}
+				if jobs[j].FiscalYearStart == "" {
+					jobs[j].FiscalYearStart = parentJob.FiscalYearStart
+				}
+				if jobs[j].DateEnvAsOf == "" {
+					jobs[j].DateEnvAsOf = parentJob.DateEnvAsOf
+				}
+				if jobs[j].Params == nil {
+					jobs[j].Params = parentJob.Params
+				}
 				uuids = append(uuids, jobs[j].JobUUID.String())
 				//trigger = "success"
 				trigger = "success"
@@ -613,7 +732,7 @@ func LoadJobSpec(config string, maxhistory int, templates map[string]JobSpec, sc
 }
 
 func (job *JobSpec) copyTemplate(spec JobSpec) {
-	// never copy Name, Description, Jobs, JobsControl
+	// never copy Name, Description, Jobs, JobsControl, Matrix
 	if spec.Tags != nil {
 		job.Tags = spec.Tags
 	}
@@ -638,6 +757,21 @@ func (job *JobSpec) copyTemplate(spec JobSpec) {
 	job.ShutdownCmd = spec.ShutdownCmd
 	job.ShutdownSig = spec.ShutdownSig
 	job.Shell = spec.Shell
+	if spec.Script != nil {
+		job.Script = spec.Script
+	}
+	if spec.WorkDir != nil {
+		job.WorkDir = spec.WorkDir
+	}
+	if spec.CreateWorkDir != nil {
+		job.CreateWorkDir = spec.CreateWorkDir
+	}
+	if spec.Umask != nil {
+		job.Umask = spec.Umask
+	}
+	if spec.Stdin != nil {
+		job.Stdin = spec.Stdin
+	}
 	if spec.Env != nil {
 		if job.Env == nil {
 			job.Env = spec.Env
@@ -647,6 +781,15 @@ func (job *JobSpec) copyTemplate(spec JobSpec) {
 			}
 		}
 	}
+	if spec.FiscalYearStart != nil {
+		job.FiscalYearStart = spec.FiscalYearStart
+	}
+	if spec.DateEnvAsOf != nil {
+		job.DateEnvAsOf = spec.DateEnvAsOf
+	}
+	if spec.Params != nil {
+		job.Params = spec.Params
+	}
 	if spec.DateEnv != nil {
 		if job.DateEnv == nil {
 			job.DateEnv = spec.DateEnv
@@ -669,7 +812,13 @@ func (job *JobSpec) copyTemplate(spec JobSpec) {
 		job.CalendarDirs = spec.CalendarDirs
 	}
 	job.Rollback = spec.Rollback
+	if spec.CalAdjust != nil {
+		job.CalAdjust = spec.CalAdjust
+	}
 	job.RequireCal = spec.RequireCal
+	if spec.DSTPolicy != nil {
+		job.DSTPolicy = spec.DSTPolicy
+	}
 	if spec.StartDay != nil {
 		job.StartDay = spec.StartDay
 	}
@@ -691,9 +840,36 @@ func (job *JobSpec) copyTemplate(spec JobSpec) {
 	if spec.CronRestart != nil {
 		job.CronRestart = spec.CronRestart
 	}
+	if spec.RRStart != nil {
+		job.RRStart = spec.RRStart
+	}
+	if spec.RREnd != nil {
+		job.RREnd = spec.RREnd
+	}
+	if spec.RRRestart != nil {
+		job.RRRestart = spec.RRRestart
+	}
 	if spec.StartRule != nil {
 		job.StartRule = spec.StartRule
 	}
+	if spec.MaxInstances != nil {
+		job.MaxInstances = spec.MaxInstances
+	}
+	if spec.Pool != nil {
+		job.Pool = spec.Pool
+	}
+	if spec.PoolSlots != nil {
+		job.PoolSlots = spec.PoolSlots
+	}
+	if spec.Priority != nil {
+		job.Priority = spec.Priority
+	}
+	if spec.CatchUp != nil {
+		job.CatchUp = spec.CatchUp
+	}
+	if spec.CatchUpWithin != nil {
+		job.CatchUpWithin = spec.CatchUpWithin
+	}
 	if spec.Dependency != nil {
 		job.Dependency = spec.Dependency
 	}
@@ -745,6 +921,12 @@ func (job *JobSpec) copyTemplate(spec JobSpec) {
 	if spec.Admin != nil {
 		job.Admin = spec.Admin
 	}
+	if spec.RunAs != nil {
+		job.RunAs = spec.RunAs
+	}
+	if spec.Limits != nil {
+		job.Limits = spec.Limits
+	}
 	job.src = spec.src
 }
 func (job *Job) CopyJobSpec(spec *JobSpec) {
@@ -794,9 +976,25 @@ func (job *Job) copyJobSpec(spec *JobSpec) {
 	if spec.JobsControl == nil {
 		job.JobsControl = &JobsControl{}
 	}
+	job.Matrix = spec.Matrix
 	if spec.Shell != nil {
 		job.Shell = *spec.Shell
 	}
+	if spec.Script != nil {
+		job.Script = *spec.Script
+	}
+	if spec.WorkDir != nil {
+		job.WorkDir = *spec.WorkDir
+	}
+	if spec.CreateWorkDir != nil {
+		job.CreateWorkDir = *spec.CreateWorkDir
+	}
+	if spec.Umask != nil {
+		job.Umask = *spec.Umask
+	}
+	if spec.Stdin != nil {
+		job.Stdin = *spec.Stdin
+	}
 	if spec.Env != nil {
 		if job.Env != nil {
 			for _, kv := range *spec.Env {
@@ -817,6 +1015,15 @@ func (job *Job) copyJobSpec(spec *JobSpec) {
 		}
 		job.LocalDateEnv = *spec.DateEnv
 	}
+	if spec.FiscalYearStart != nil {
+		job.FiscalYearStart = *spec.FiscalYearStart
+	}
+	if spec.DateEnvAsOf != nil {
+		job.DateEnvAsOf = *spec.DateEnvAsOf
+	}
+	if spec.Params != nil {
+		job.Params = *spec.Params
+	}
 	if spec.AlertActions != nil {
 		job.AlertActions = *spec.AlertActions
 	}
@@ -829,9 +1036,15 @@ func (job *Job) copyJobSpec(spec *JobSpec) {
 	if spec.Rollback != nil {
 		job.Rollback = *spec.Rollback
 	}
+	if spec.CalAdjust != nil {
+		job.CalAdjust = *spec.CalAdjust
+	}
 	if spec.Rollback != nil {
 		job.RequireCal = *spec.RequireCal
 	}
+	if spec.DSTPolicy != nil {
+		job.DSTPolicy = *spec.DSTPolicy
+	}
 	if spec.CronStart != nil {
 		//job.CronStart = spec.CronStart
 		job.CronStartArray = *spec.CronStart
@@ -849,6 +1062,15 @@ func (job *Job) copyJobSpec(spec *JobSpec) {
 	if spec.CronRestart != nil {
 		job.CronRestart = spec.CronRestart
 	}
+	if spec.RRStart != nil {
+		job.RRStart = *spec.RRStart
+	}
+	if spec.RREnd != nil {
+		job.RREnd = *spec.RREnd
+	}
+	if spec.RRRestart != nil {
+		job.RRRestart = *spec.RRRestart
+	}
 	if spec.StartDay != nil {
 		job.StartDay = *spec.StartDay
 	}
@@ -864,6 +1086,24 @@ func (job *Job) copyJobSpec(spec *JobSpec) {
 	if spec.StartRule != nil {
 		job.StartRule = *spec.StartRule
 	}
+	if spec.MaxInstances != nil {
+		job.MaxInstances = *spec.MaxInstances
+	}
+	if spec.Pool != nil {
+		job.Pool = *spec.Pool
+	}
+	if spec.PoolSlots != nil {
+		job.PoolSlots = *spec.PoolSlots
+	}
+	if spec.Priority != nil {
+		job.Priority = *spec.Priority
+	}
+	if spec.CatchUp != nil {
+		job.CatchUp = *spec.CatchUp
+	}
+	if spec.CatchUpWithin != nil {
+		job.CatchUpWithin = *spec.CatchUpWithin
+	}
 	if spec.Dependency != nil {
 		job.Dependency = spec.Dependency
 	}
@@ -926,6 +1166,12 @@ func (job *Job) copyJobSpec(spec *JobSpec) {
 	if spec.Admin != nil {
 		job.Admin = *spec.Admin
 	}
+	if spec.RunAs != nil {
+		job.RunAs = spec.RunAs
+	}
+	if spec.Limits != nil {
+		job.Limits = spec.Limits
+	}
 	job.LoadLocation()
 	job.History = make([]JobHistory, 10)
 	job.src = spec.src
@@ -1036,11 +1282,21 @@ func LoadConfig2(home string, jobsFiles []string, sconf ServerConfig, clean, kee
 				// alljobs[i] = tmpjob
 				alljobs[i].History = tmpjob.History
 				alljobs[i].JobState = tmpjob.JobState
+
+				// last start is needed to CatchUp triggers missed while down
+				alljobs[i].PrevStart = tmpjob.PrevStart
+				alljobs[i].PrevStop = tmpjob.PrevStop
+				alljobs[i].PrevStopUNIX = tmpjob.PrevStopUNIX
+				alljobs[i].Started = tmpjob.Started
+				alljobs[i].StartedUNIX = tmpjob.StartedUNIX
+				if tmpjob.StartedUNIX > 0 {
+					alljobs[i].prevStart = time.Unix(tmpjob.StartedUNIX, 0)
+				}
 			}
 			if err != nil {
 				ServerLogger.Fatal("failed to load prior state from", rj)
 			}
-			if alljobs[i].JobState == JRunning || alljobs[i].JobState == JRetrying {
+			if alljobs[i].JobState == JRunning || alljobs[i].JobState == JWarning2 || alljobs[i].JobState == JRetrying {
 				alljobs[i].setHold(true)
 				alljobs[i].setJobState(JUnknown)
 				ServerLogger.Printf("job [%s] in inconsistent state!", alljobs[i].JobUUID)
@@ -1176,8 +1432,101 @@ func (job *Job) parseJob(createDir bool) error {
 		if err != nil {
 			return err
 		}
+		job.cronRestartArray = []Cron{job.cronRestart}
+	} else {
+		job.cronRestart = NullCron()
+		job.cronRestartArray = []Cron{job.cronRestart}
+	}
+
+	// RFC 5545 rules are merged with any cron rules for each trigger
+	if job.cronStartArray, err = job.parseRRules(job.RRStart, job.cronStartArray, CronStart); err != nil {
+		return err
+	}
+	if job.cronEndArray, err = job.parseRRules(job.RREnd, job.cronEndArray, CronEnd); err != nil {
+		return err
+	}
+	if job.cronRestartArray, err = job.parseRRules(job.RRRestart, job.cronRestartArray, CronRestart); err != nil {
+		return err
+	}
+	if len(job.RRStart) > 0 && job.cronStart.IsNull() {
+		job.cronStart = job.cronStartArray[0]
+	}
+
+	dstPolicy, err := ParseDSTPolicy(job.DSTPolicy)
+	if err != nil {
+		return err
+	}
+	for _, crons := range [][]Cron{job.cronStartArray, job.cronEndArray, job.cronRestartArray} {
+		for i := range crons {
+			crons[i].DSTPolicy = dstPolicy
+		}
+	}
+	job.cronStart.DSTPolicy = dstPolicy
+	job.cronEnd.DSTPolicy = dstPolicy
+	job.cronRestart.DSTPolicy = dstPolicy
+
+	if _, err := ParseFiscalYearStart(job.FiscalYearStart); err != nil {
+		return err
+	}
+	if err := validateParams(job.Params); err != nil {
+		return err
+	}
+	if err := job.Limits.validate(); err != nil {
+		return err
+	}
+	if _, err := parseUmask(job.Umask); err != nil {
+		return err
+	}
+
+	if job.CalAdjust != "" {
+		if job.Rollback {
+			return fmt.Errorf("CalAdjust and Rollback cannot both be set")
+		}
+		calAdjust, err := ParseCalAdjust(job.CalAdjust)
+		if err != nil {
+			return err
+		}
+		for _, crons := range [][]Cron{job.cronStartArray, job.cronEndArray, job.cronRestartArray} {
+			for i := range crons {
+				crons[i].SetAdjust(calAdjust)
+			}
+		}
+		job.cronStart.SetAdjust(calAdjust)
+		job.cronEnd.SetAdjust(calAdjust)
+		job.cronRestart.SetAdjust(calAdjust)
+	}
+
+	if job.catchUp, err = ParseCatchUp(job.CatchUp); err != nil {
+		return err
+	}
+	if job.dateEnvAsOf, err = ParseAsOfPolicy(job.DateEnvAsOf); err != nil {
+		return err
+	}
+	if job.catchUp == CatchUpAllWithin {
+		if job.CatchUpWithin == "" {
+			return fmt.Errorf("CatchUp all-within-duration requires CatchUpWithin duration")
+		}
+		if job.catchUpWithin, err = time.ParseDuration(job.CatchUpWithin); err != nil {
+			return err
+		}
+	}
+	if job.MinRuntime != "" {
+		if job.minRuntime, err = time.ParseDuration(job.MinRuntime); err != nil {
+			return fmt.Errorf("invalid MinRuntime %s: %s", job.MinRuntime, err)
+		}
+	}
+	if job.MaxRuntime != "" {
+		if job.maxRuntime, err = time.ParseDuration(job.MaxRuntime); err != nil {
+			return fmt.Errorf("invalid MaxRuntime %s: %s", job.MaxRuntime, err)
+		}
 	}
 	job.startRule = job.getStartRule()
+	if job.MaxInstances < 0 {
+		return fmt.Errorf("MaxInstances must be 0 (unlimited) or greater")
+	}
+	if job.PoolSlots < 0 {
+		return fmt.Errorf("PoolSlots must be 0 (default of 1) or greater")
+	}
 	job.JobState = JReady
 	if job.Hold == true {
 		job.JobState = JHold
@@ -1207,6 +1556,28 @@ func (job *Job) parseJob(createDir bool) error {
 
 }
 
+// parseRRules parses rrules and merges them with crons. A null (@manual) cron is
+// replaced entirely, while dependent crons are left unchanged.
+func (job *Job) parseRRules(rrules []string, crons []Cron, schedule CronType) ([]Cron, error) {
+	if len(rrules) == 0 {
+		return crons, nil
+	}
+	if len(crons) == 1 && crons[0].IsNull() {
+		crons = nil
+	}
+	for _, rr := range rrules {
+		cron, err := parseRRuleCron(rr, job.Timezone, job.Calendar, job.CalendarDirs, job.Rollback, job.RequireCal, job.Jitter)
+		if err != nil {
+			cErr := err.(CronError)
+			cErr.Schedule = schedule
+			return crons, cErr
+		}
+		cron.array = len(crons) > 0
+		crons = append(crons, cron)
+	}
+	return crons, nil
+}
+
 func NewJobMap(home string, ojobs []Job) ServerJobs {
 
 	var jobs []Job
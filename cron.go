@@ -1,6 +1,7 @@
 package rpeat
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -61,6 +62,12 @@ type Cron struct {
 	// should date be rolled back to prior period
 	EndOf bool
 
+	// business day convention for dates not in Calendar, used in place of Rollback if set
+	Adjust CalAdjust
+
+	// handling of trigger times affected by daylight saving transitions
+	DSTPolicy DSTPolicy
+
 	// spec is the string form of the passed cron
 	spec string
 
@@ -73,6 +80,61 @@ type Cron struct {
 
 	// is Cron part of an array of crons
 	array bool
+
+	// has Adjust been set
+	adjust bool
+
+	// RFC 5545 recurrence rule used in place of Spec
+	rrule *RRule
+
+	// L, W and # day of month and day of week forms
+	days *cronDays
+}
+
+// DSTPolicy defines how wall clock trigger times affected by daylight saving
+// transitions in the Timezone are handled:
+//
+//	run-once      (default) times in a spring forward gap are shifted by the gap (02:30 runs at 03:30),
+//	              times repeated when clocks fall back run only on the first pass
+//	run-twice     as run-once, but repeated times run on both passes
+//	skip          times in a spring forward gap do not run, repeated times run once
+//	run-at-shift  times in a spring forward gap run at the transition (03:00), repeated times run once
+type DSTPolicy int
+
+const (
+	DSTRunOnce DSTPolicy = iota
+	DSTRunTwice
+	DSTSkip
+	DSTRunAtShift
+)
+
+var dstPolicyNames = [...]string{"run-once", "run-twice", "skip", "run-at-shift"}
+
+func (p DSTPolicy) String() string {
+	if p < DSTRunOnce || p > DSTRunAtShift {
+		return "Unknown"
+	}
+	return dstPolicyNames[p]
+}
+
+// ParseDSTPolicy converts a DSTPolicy name, with the empty string being the default run-once
+func ParseDSTPolicy(policy string) (DSTPolicy, error) {
+	if policy == "" {
+		return DSTRunOnce, nil
+	}
+	for i, name := range dstPolicyNames {
+		if strings.EqualFold(policy, name) {
+			return DSTPolicy(i), nil
+		}
+	}
+	return DSTRunOnce, errors.New(fmt.Sprintf("unrecognized DSTPolicy %s (expecting one of %s)", policy, strings.Join(dstPolicyNames[:], ", ")))
+}
+
+// SetAdjust sets the business day convention used for dates not in the Cron calendar,
+// replacing Rollback
+func (c *Cron) SetAdjust(adj CalAdjust) {
+	c.Adjust = adj
+	c.adjust = true
 }
 
 // Cron Exceptions
@@ -85,6 +147,10 @@ const (
 	UnrecognizedAt
 	ExpansionError
 	CalendarSyntaxError
+	RRuleSyntaxError
+	MalformedLastDay
+	MalformedNearestWeekday
+	MalformedNthWeekday
 )
 
 type CronError struct {
@@ -99,7 +165,7 @@ type CronError struct {
 }
 
 func (e CronException) String() string {
-	names := [...]string{"MissingFields", "Malformed @at", "Malformed @every", "Unrecongnized @", "ExpansionError", "CalendarSyntaxError"}
+	names := [...]string{"MissingFields", "Malformed @at", "Malformed @every", "Unrecongnized @", "ExpansionError", "CalendarSyntaxError", "RRuleSyntaxError", "Malformed L", "Malformed W", "Malformed #"}
 	return names[e]
 }
 
@@ -108,6 +174,8 @@ func (e CronError) Error() string {
 	switch e.Exception {
 	case IncorrectNumberOfFields:
 		s = fmt.Sprintf("%s %s [ %s ] requires 5 or 6, %d fields found", e.Exception, e.Schedule, e.Spec, e.field)
+	case RRuleSyntaxError, MalformedLastDay, MalformedNearestWeekday, MalformedNthWeekday:
+		s = fmt.Sprintf("%s %s [ %s ] %s", e.Exception, e.Schedule, e.Spec, e.Msg)
 	default:
 		s = fmt.Sprintf("%s in %s", e.Spec, e.Exception)
 	}
@@ -127,6 +195,10 @@ func ParseCron(spec string, timezone string, calendar string, calendarDirs []str
 		return cron, nil
 	}
 
+	if isRRuleSpec(spec) {
+		return parseRRuleCron(spec, timezone, calendar, calendarDirs, rollback, required, jitter)
+	}
+
 	if strings.HasPrefix(spec, "@") {
 		if strings.HasPrefix(spec, "@at ") {
 			fields = strings.Fields(spec)
@@ -200,6 +272,7 @@ func ParseCron(spec string, timezone string, calendar string, calendarDirs []str
 		cron = NewCron(timezone)
 		cron.Calendar = calendar
 		cron.CalendarDirs = calendarDirs
+		cron.spec = spec
 
 		secOffset := 0
 		if nfields == 6 {
@@ -219,19 +292,33 @@ func ParseCron(spec string, timezone string, calendar string, calendarDirs []str
 		if fields[3+secOffset] != "*" {
 			cron.Spec[Mon], cron.r[Mon] = expandField(fields[3+secOffset], 1, 12)
 		}
+		// Quartz-style ? (no specific value) is equivalent to *
+		mdayField := strings.Replace(fields[2+secOffset], "?", "*", -1)
+		wdayField := strings.Replace(fields[4+secOffset], "?", "*", -1)
+
+		// L, W and # forms are removed from the fields and handled separately
+		mdays, err := cron.expandDayField(mdayField, Mday)
+		if err != nil {
+			return cron, err
+		}
+		wdays, err := cron.expandDayField(wdayField, Wday)
+		if err != nil {
+			return cron, err
+		}
+
 		// handle Mday and Wday parse:
-		if fields[2+secOffset] != "*" && fields[4+secOffset] == "*" {
+		if mdayField != "*" && wdayField == "*" {
 			//if mday is number and wday == * : set wday = -1
-			cron.Spec[Mday], cron.r[Mday] = expandField(fields[2+secOffset], 1, 31)
+			cron.Spec[Mday], cron.r[Mday] = expandDays(mdays, 1, 31)
 			cron.Spec[Wday] = []int{-1}
-		} else if fields[2+secOffset] == "*" && fields[4+secOffset] != "*" {
+		} else if mdayField == "*" && wdayField != "*" {
 			//if mday == * and wday == number : set mday = -1
-			cron.Spec[Wday], cron.r[Wday] = expandField(fields[4+secOffset], 0, 6)
+			cron.Spec[Wday], cron.r[Wday] = expandDays(wdays, 0, 6)
 			cron.Spec[Mday] = []int{-1}
 		} else {
 			//if mday == * and wday == *  or mday is defined and wday is defined: leave alone
-			cron.Spec[Mday], cron.r[Mday] = expandField(fields[2+secOffset], 1, 31)
-			cron.Spec[Wday], cron.r[Wday] = expandField(fields[4+secOffset], 0, 6)
+			cron.Spec[Mday], cron.r[Mday] = expandDays(mdays, 1, 31)
+			cron.Spec[Wday], cron.r[Wday] = expandDays(wdays, 0, 6)
 		}
 	}
 
@@ -242,19 +329,8 @@ func ParseCron(spec string, timezone string, calendar string, calendarDirs []str
 	return cron, nil
 }
 
-func expandField(field string, start, end int) ([]int, bool) {
-	var e []int
-	var d int
-	var rr []int
-	var err error
-	var r bool
-
-	// TODO: Add LANGUAGE support options
-	field = strings.ToUpper(field)
-	if strings.HasPrefix(field, "R") {
-		r = true
-		field = strings.TrimPrefix(field, "R")
-	}
+// replaceCronNames converts day and month names and aliases to their numeric values
+func replaceCronNames(field string) string {
 	if field == "M-F" || field == "MF" || field == "WEEKDAYS" || field == "WEEKDAY" {
 		field = "1-5"
 	}
@@ -265,13 +341,7 @@ func expandField(field string, start, end int) ([]int, bool) {
 		field = "*"
 	}
 	if strings.ContainsAny(field, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") {
-		field = strings.Replace(field, "SUN", "0", -1)
-		field = strings.Replace(field, "MON", "1", -1)
-		field = strings.Replace(field, "TUE", "2", -1)
-		field = strings.Replace(field, "WED", "3", -1)
-		field = strings.Replace(field, "THU", "4", -1)
-		field = strings.Replace(field, "FRI", "5", -1)
-		field = strings.Replace(field, "SAT", "6", -1)
+		// full names must be replaced before abbreviations, e.g. MONDAY before MON
 		field = strings.Replace(field, "SUNDAY", "0", -1)
 		field = strings.Replace(field, "MONDAY", "1", -1)
 		field = strings.Replace(field, "TUESDAY", "2", -1)
@@ -279,7 +349,25 @@ func expandField(field string, start, end int) ([]int, bool) {
 		field = strings.Replace(field, "THURSDAY", "4", -1)
 		field = strings.Replace(field, "FRIDAY", "5", -1)
 		field = strings.Replace(field, "SATURDAY", "6", -1)
+		field = strings.Replace(field, "SUN", "0", -1)
+		field = strings.Replace(field, "MON", "1", -1)
+		field = strings.Replace(field, "TUE", "2", -1)
+		field = strings.Replace(field, "WED", "3", -1)
+		field = strings.Replace(field, "THU", "4", -1)
+		field = strings.Replace(field, "FRI", "5", -1)
+		field = strings.Replace(field, "SAT", "6", -1)
 
+		field = strings.Replace(field, "JANUARY", "1", -1)
+		field = strings.Replace(field, "FEBRUARY", "2", -1)
+		field = strings.Replace(field, "MARCH", "3", -1)
+		field = strings.Replace(field, "APRIL", "4", -1)
+		field = strings.Replace(field, "JUNE", "6", -1)
+		field = strings.Replace(field, "JULY", "7", -1)
+		field = strings.Replace(field, "AUGUST", "8", -1)
+		field = strings.Replace(field, "SEPTEMBER", "9", -1)
+		field = strings.Replace(field, "OCTOBER", "10", -1)
+		field = strings.Replace(field, "NOVEMBER", "11", -1)
+		field = strings.Replace(field, "DECEMBER", "12", -1)
 		field = strings.Replace(field, "JAN", "1", -1)
 		field = strings.Replace(field, "FEB", "2", -1)
 		field = strings.Replace(field, "MAR", "3", -1)
@@ -292,19 +380,24 @@ func expandField(field string, start, end int) ([]int, bool) {
 		field = strings.Replace(field, "OCT", "10", -1)
 		field = strings.Replace(field, "NOV", "11", -1)
 		field = strings.Replace(field, "DEC", "12", -1)
-		field = strings.Replace(field, "JANUARY", "1", -1)
-		field = strings.Replace(field, "FEBRUARY", "2", -1)
-		field = strings.Replace(field, "MARCH", "3", -1)
-		field = strings.Replace(field, "APRIL", "4", -1)
-		field = strings.Replace(field, "MAY", "5", -1)
-		field = strings.Replace(field, "JUNE", "6", -1)
-		field = strings.Replace(field, "JULY", "7", -1)
-		field = strings.Replace(field, "AUGUST", "8", -1)
-		field = strings.Replace(field, "SEPTEMBER", "9", -1)
-		field = strings.Replace(field, "OCTOBER", "10", -1)
-		field = strings.Replace(field, "NOVEMBER", "11", -1)
-		field = strings.Replace(field, "DECEMBER", "12", -1)
 	}
+	return field
+}
+
+func expandField(field string, start, end int) ([]int, bool) {
+	var e []int
+	var d int
+	var rr []int
+	var err error
+	var r bool
+
+	// TODO: Add LANGUAGE support options
+	field = strings.ToUpper(field)
+	if strings.HasPrefix(field, "R") {
+		r = true
+		field = strings.TrimPrefix(field, "R")
+	}
+	field = replaceCronNames(field)
 
 	step := 1
 	fields := strings.Split(field, "/")
@@ -349,6 +442,215 @@ func expandField(field string, start, end int) ([]int, bool) {
 	return e, r
 }
 
+// cronDays holds the day forms which depend on the month being evaluated:
+//
+//	L      last day of month
+//	L-n    n days before the last day of month
+//	LW     last weekday of month
+//	nW     weekday nearest to day n of month, without leaving the month. Months
+//	       without a day n are skipped
+//	dL     last weekday d (0-6, SUN-SAT) of month, e.g. 5L or FRIL is the last Friday
+//	d#n    nth weekday d of month, e.g. 1#2 or MON#2 is the second Monday
+//
+// If a Calendar is used, days are chosen from the available calendar dates
+// (e.g. LW is the last business day, 5L the last Friday which is a business day),
+// otherwise weekdays are Monday through Friday.
+type cronDays struct {
+	last        []int
+	lastWeekday bool
+	nearest     []int
+	nth         []RRuleDay // N == -1 for last
+}
+
+// expandDayField removes the L, W and # forms from a day of month (Mday) or day of
+// week (Wday) field, recording them in c.days, and returns the remaining values
+func (c *Cron) expandDayField(field string, cf CronFields) (string, error) {
+	field = strings.ToUpper(field)
+	if strings.HasPrefix(field, "R") {
+		return field, nil
+	}
+	field = replaceCronNames(field)
+	if !strings.ContainsAny(field, "LW#") {
+		return field, nil
+	}
+	if c.days == nil {
+		c.days = &cronDays{}
+	}
+	var plain []string
+	for _, v := range strings.Split(field, ",") {
+		var n int
+		var err error
+		switch {
+		case cf == Mday && v == "LW":
+			c.days.lastWeekday = true
+		case cf == Mday && strings.HasPrefix(v, "L"):
+			if v != "L" {
+				if !strings.HasPrefix(v, "L-") {
+					err = fmt.Errorf("expected L or L-n")
+				} else if n, err = strconv.Atoi(v[2:]); err == nil && (n < 0 || n > 30) {
+					err = fmt.Errorf("offset must be between 0 and 30")
+				}
+			}
+			if err != nil {
+				return "", CronError{Spec: c.spec, Msg: fmt.Sprintf("%s: %s", v, err), Exception: MalformedLastDay}
+			}
+			c.days.last = append(c.days.last, n)
+		case cf == Mday && strings.HasSuffix(v, "W"):
+			if n, err = strconv.Atoi(strings.TrimSuffix(v, "W")); err != nil || n < 1 || n > 31 {
+				return "", CronError{Spec: c.spec, Msg: fmt.Sprintf("%s: expected day of month 1-31 before W", v), Exception: MalformedNearestWeekday}
+			}
+			c.days.nearest = append(c.days.nearest, n)
+		case cf == Wday && strings.Contains(v, "#"):
+			dn := strings.Split(v, "#")
+			var wd int
+			if len(dn) == 2 {
+				wd, err = strconv.Atoi(dn[0])
+				if err == nil {
+					n, err = strconv.Atoi(dn[1])
+				}
+			}
+			if len(dn) != 2 || err != nil || wd < 0 || wd > 7 || n < 1 || n > 5 {
+				return "", CronError{Spec: c.spec, Msg: fmt.Sprintf("%s: expected weekday 0-7 # occurrence 1-5", v), Exception: MalformedNthWeekday}
+			}
+			c.days.nth = append(c.days.nth, RRuleDay{N: n, Weekday: time.Weekday(wd % 7)})
+		case cf == Wday && strings.HasSuffix(v, "L"):
+			wd := 6 // L alone is the last day of the week
+			if v != "L" {
+				if wd, err = strconv.Atoi(strings.TrimSuffix(v, "L")); err != nil || wd < 0 || wd > 7 {
+					return "", CronError{Spec: c.spec, Msg: fmt.Sprintf("%s: expected weekday 0-7 before L", v), Exception: MalformedLastDay}
+				}
+			}
+			c.days.nth = append(c.days.nth, RRuleDay{N: -1, Weekday: time.Weekday(wd % 7)})
+		case strings.Contains(v, "#"):
+			return "", CronError{Spec: c.spec, Msg: fmt.Sprintf("%s: # is only valid in the day of week field", v), Exception: MalformedNthWeekday}
+		case strings.Contains(v, "W"):
+			return "", CronError{Spec: c.spec, Msg: fmt.Sprintf("%s: W is only valid in the day of month field", v), Exception: MalformedNearestWeekday}
+		case strings.Contains(v, "L"):
+			return "", CronError{Spec: c.spec, Msg: fmt.Sprintf("%s: unrecognized use of L", v), Exception: MalformedLastDay}
+		default:
+			plain = append(plain, v)
+		}
+	}
+	return strings.Join(plain, ","), nil
+}
+
+// expandDays is expandField for day fields which may be empty once L, W and # forms are removed
+func expandDays(field string, start, end int) ([]int, bool) {
+	if field == "" {
+		return []int{-1}, false
+	}
+	return expandField(field, start, end)
+}
+
+// datesInMonth resolves the L, W and # forms for year and month, using
+// avail (sorted YYYYMMDD) as the available dates if not empty
+func (cd cronDays) datesInMonth(year, mon int, avail []int) []int {
+	last := lastDayOfMonth(year, mon)
+	first := year*10000 + mon*100
+
+	var days []int
+	if len(avail) > 0 {
+		for i := sort.SearchInts(avail, first+1); i < len(avail) && avail[i] <= first+last; i++ {
+			days = append(days, avail[i]-first)
+		}
+	}
+	useCal := len(days) > 0
+	if !useCal {
+		days = makerange(1, last, 1)
+	}
+	weekday := func(d int) time.Weekday {
+		return time.Date(year, time.Month(mon), d, 0, 0, 0, 0, time.UTC).Weekday()
+	}
+	var weekdays []int
+	for _, d := range days {
+		if useCal || (weekday(d) != time.Saturday && weekday(d) != time.Sunday) {
+			weekdays = append(weekdays, d)
+		}
+	}
+
+	var dates []int
+	for _, n := range cd.last {
+		if useCal {
+			if n < len(days) {
+				dates = append(dates, first+days[len(days)-1-n])
+			}
+		} else if last-n >= 1 {
+			dates = append(dates, first+last-n)
+		}
+	}
+	if cd.lastWeekday && len(weekdays) > 0 {
+		dates = append(dates, first+weekdays[len(weekdays)-1])
+	}
+	for _, n := range cd.nearest {
+		if n > last { // e.g. 30W in February
+			continue
+		}
+		nearest := -1
+		for _, d := range weekdays {
+			if nearest == -1 || math.Abs(float64(d-n)) < math.Abs(float64(nearest-n)) {
+				nearest = d
+			}
+		}
+		if nearest != -1 {
+			dates = append(dates, first+nearest)
+		}
+	}
+	for _, nth := range cd.nth {
+		var matches []int
+		for _, d := range days {
+			if weekday(d) == nth.Weekday {
+				matches = append(matches, d)
+			}
+		}
+		if nth.N == -1 && len(matches) > 0 {
+			dates = append(dates, first+matches[len(matches)-1])
+		} else if nth.N > 0 && nth.N <= len(matches) {
+			dates = append(dates, first+matches[nth.N-1])
+		}
+	}
+	return dates
+}
+
+// triggerDates returns all candidate trigger dates (YYYYMMDD) starting from y0 and m0,
+// including any L, W and # forms. See nextYearDates
+func (c Cron) triggerDates(y0, m0 int) []int {
+	mday, mon, wday := c.Mdays(), c.Months(), c.Wdays()
+	if c.days == nil {
+		return nextYearDates(y0, m0, mday, mon, wday)
+	}
+
+	var dates []int
+	if mday[0] != -1 || wday[0] != -1 {
+		dates = nextYearDates(y0, m0, mday, mon, wday)
+	}
+	var avail []int
+	if c.Calendar != "" && c.Calendar != "ALL" {
+		if cal, err := ReadCalendar(c.Calendar, c.CalendarDirs); err == nil {
+			avail = cal.DatesIn
+		}
+	}
+	y, m := y0, m0
+	for i := 0; i <= 12*5; i++ {
+		if monIsIn(m, mon) {
+			dates = append(dates, c.days.datesInMonth(y, m, avail)...)
+		}
+		if m++; m > 12 {
+			m = 1
+			y++
+		}
+	}
+	sort.Ints(dates)
+	n := 0
+	for i := range dates {
+		if i > 0 && dates[i] == dates[i-1] {
+			continue
+		}
+		dates[n] = dates[i]
+		n++
+	}
+	return dates[:n]
+}
+
 func lastDayOfMonth(year, mon int) int {
 	dt := time.Date(year, time.Month(mon+1), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
 	_, _, d := dt.Date()
@@ -450,6 +752,12 @@ func (job Job) isCronDependent() bool {
 func (job Job) isContingent() bool {
 	return job.cronStart.Contingent
 }
+func (job *Job) hasCronEnd() bool {
+	return job.CronEnd != nil || len(job.RREnd) > 0
+}
+func (job *Job) hasCronRestart() bool {
+	return job.CronRestart != nil || len(job.RRRestart) > 0
+}
 func ParseTime(timestring string, timezone string, calendar string, calendarDirs []string) (time.Time, Cron) {
 	loc, _ := time.LoadLocation(timezone)
 	t, err := time.ParseInLocation("20060102150405", timestring, loc)
@@ -549,6 +857,9 @@ func (c Cron) Add(d time.Duration) Cron {
 	return cron
 }
 func (c Cron) String() string {
+	if c.IsRRule() {
+		return fmt.Sprintf("RRule{%s %s %s}", c.spec, c.Timezone, c.Calendar)
+	}
 	return fmt.Sprintf("Cron{%d %d %d %d %d %d %s %s}", c.Spec[Sec][0], c.Spec[Min][0], c.Spec[Hour][0], c.Spec[Mday][0], c.Spec[Mon][0], c.Spec[Wday][0], c.Timezone, c.Calendar)
 }
 func (c Cron) Str() {
@@ -564,17 +875,41 @@ func (c Cron) Str() {
 
 // array version for handling disjoint cron starts
 func NextCronStart(cron []Cron) (d time.Duration, next time.Time) {
+	d, next, _ = NextCronSchedule(cron)
+	return
+}
 
-	for i := 0; i < len(cron); i++ {
-		d_i, next_i := getNextStart(cron[i])
+// NextCronSchedule is NextCronStart also returning the scheduled time of the next
+// trigger, before the Jitter of its cron is added
+func NextCronSchedule(cron []Cron) (d time.Duration, next time.Time, scheduled time.Time) {
+	var jitter int
+	for i, c := range withoutJitter(cron) {
+		d_i, next_i := getNextStart(c)
 		if i == 0 || d > d_i {
 			d = d_i
 			next = next_i
+			jitter = cron[i].jitter
 		}
 	}
+	scheduled = next
+	if jitter > 0 && !next.IsZero() && d != time.Duration(math.MaxInt64) {
+		j := time.Second * time.Duration(rand.Intn(jitter))
+		next = next.Add(j)
+		d += j
+	}
 	return
 }
 
+// withoutJitter returns a copy of cron with Jitter removed
+func withoutJitter(cron []Cron) []Cron {
+	c := make([]Cron, len(cron))
+	copy(c, cron)
+	for i := range c {
+		c[i].jitter = 0
+	}
+	return c
+}
+
 func getNextStart(cron Cron) (d time.Duration, next time.Time) {
 	if cron.IsEvery() {
 		d = cron.every
@@ -0,0 +1,87 @@
+package rpeat
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCronDayForms(t *testing.T) {
+	tests := []struct {
+		spec  string
+		after string
+		want  string // the next triggers, YYYYMMDD hhmm
+	}{
+		{"0 0 12 L * *", "20270201", "20270228 1200,20270331 1200"},
+		{"0 0 12 L-2 * *", "20240201", "20240227 1200,20240329 1200"},
+		{"0 0 12 LW * *", "20260501", "20260529 1200,20260630 1200"},
+		{"0 0 12 L,15 * *", "20260201", "20260215 1200,20260228 1200"},
+		{"0 0 12 15W * *", "20260801", "20260814 1200,20260915 1200"},
+		{"0 0 12 1W * *", "20260801", "20260803 1200,20260901 1200"},
+		{"0 0 12 31W * *", "20261001", "20261030 1200,20261231 1200"},
+		{"0 0 12 30W * *", "20270101", "20270129 1200,20270330 1200"}, // no Feb 30
+		{"0 0 12 ? * 5L", "20261001", "20261030 1200,20261127 1200"},
+		{"0 0 12 ? * FRIL", "20261001", "20261030 1200,20261127 1200"},
+		{"0 0 12 ? * 1#2", "20261001", "20261012 1200,20261109 1200"},
+		{"0 0 12 ? * MON#2", "20261001", "20261012 1200,20261109 1200"},
+		{"0 0 12 ? * 1#5", "20261001", "20261130 1200,20270329 1200"}, // no 5th Monday in December
+		{"0 0 12 ? JAN MON", "20261001", "20270104 1200,20270111 1200"},
+		{"0 30 9 ? * MON-FRI", "20261016 1000", "20261019 0930,20261020 0930"},
+		{"0 0 18 ? * SUN,SAT", "20261016", "20261017 1800,20261018 1800"},
+		{"0 0 12 ? * 7#1", "20261016", "20261101 1200,20261206 1200"}, // 7 is Sunday
+		{"0 0 12 ? * SUN#1,SATL", "20261016", "20261031 1200,20261101 1200"},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.spec, "UTC", "", nil, false, false, 0)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %s", tt.spec, err)
+			continue
+		}
+		layout := "20060102 1504"
+		if len(tt.after) == 8 {
+			layout = "20060102"
+		}
+		after, err := time.Parse(layout, tt.after)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for i := 0; i < 2; i++ {
+			_, next, err := c.NextStartAfter(after)
+			if err != nil {
+				t.Fatalf("%s: NextStartAfter(%s): %s", tt.spec, after, err)
+			}
+			got = append(got, next.Format("20060102 1504"))
+			after = next
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("%s after %s: %s; want %s", tt.spec, tt.after, strings.Join(got, ","), tt.want)
+		}
+	}
+}
+
+func TestCronDayFormErrors(t *testing.T) {
+	tests := []struct {
+		spec      string
+		exception CronException
+	}{
+		{"0 0 12 L-x * *", MalformedLastDay},
+		{"0 0 12 L-31 * *", MalformedLastDay},
+		{"0 0 12 5L * ?", MalformedLastDay},
+		{"0 0 12 ? * 8L", MalformedLastDay},
+		{"0 0 12 32W * *", MalformedNearestWeekday},
+		{"0 0 12 0W * *", MalformedNearestWeekday},
+		{"0 0 12 ? * 1W", MalformedNearestWeekday},
+		{"0 0 12 ? * 1#6", MalformedNthWeekday},
+		{"0 0 12 ? * 1#0", MalformedNthWeekday},
+		{"0 0 12 ? * 1#2#3", MalformedNthWeekday},
+		{"0 0 12 1#2 * ?", MalformedNthWeekday},
+	}
+	for _, tt := range tests {
+		_, err := ParseCron(tt.spec, "UTC", "", nil, false, false, 0)
+		ce, ok := err.(CronError)
+		if !ok || ce.Exception != tt.exception {
+			t.Errorf("ParseCron(%q) error %v; want %s", tt.spec, err, tt.exception)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package rpeat
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var UTC = "UTC"
@@ -11,7 +13,37 @@ var UTC = "UTC"
 // ConvertDate returns an expanded date string taking into account optionally specified
 // forward or backward date shifts, using an optionally specified calendar.
 //
-// d takes the form  [MM|DD|YY|CC|hh|mm|ss]+[,[+-]?[0-9]+[Y|Q|M|W|D]][,CALENDAR]
+// d takes the form  FORMAT[,STEP]...[,CALENDAR[,CALADJUST]]
+//
+// where FORMAT contains one or more of the magic variables below, each of which may
+// appear any number of times
+//
+//   CC YY MM DD   century, year, month and day, e.g. 20 26 03 09
+//   hh mm ss      hour, minute and second
+//   QTR           quarter of the year, 1-4
+//   DDD           day of the year, 001-366
+//   WW IYYY       ISO 8601 week, 01-53, and the year it belongs to
+//   MON Month     month name, e.g. Mar and March
+//   DOW           day of the week, e.g. Mon
+//   EPOCH         seconds since 1970-01-01 UTC
+//   FY FQ         fiscal year, named by the year it ends in, and fiscal quarter using
+//                 fiscalYearStart as the first month (see ParseFiscalYearStart)
+//   BD            business day of the month, 01-23, counting available dates in
+//                 CALENDAR or Monday through Friday if none is given
+//
+// DDD and the variables after it are only replaced as words, i.e. not adjoining other
+// letters unless they are another variable (e.g. FYFQ), so that literal text such as
+// MONTHLY or BDAY is kept
+//
+// and each STEP, applied in order, is either a shift [+-]?[0-9]+[Y|Q|M|W|D|h|m|s] or an anchor
+// moving to the start or end of the week (Monday to Sunday), month, quarter or year:
+//
+//   SOW EOW SOM EOM SOQ EOQ SOY EOY
+//
+// Month, quarter and year shifts add to the month as time.AddDate does, overflowing into
+// the following month (2020-03-31,-1M is 2020-03-02).  Following an anchor they are instead
+// limited to the last day of the resulting month, and keep to it following an EOM, EOQ or
+// EOY anchor.  Day shifts count available dates when CALENDAR is given.
 //
 // e.g.
 //   CCYY-MM           converts to 2020-03 when called between 2020-03-01 and 2020-03-31
@@ -21,69 +53,105 @@ var UTC = "UTC"
 //   CCYY-MM-DD,-1D    converts to 2020-03-29 when called on and 2020-03-30
 //   CCYY-MM-DD,-1D,MF converts to 2020-03-27 when called on and 2020-03-30 (using MF only)
 //   CCYY-MM-DD,+5D,MF converts to 2020-04-06 when called on and 2020-03-30 (using MF only)
+//   CCYY-MM-DD,EOM,-1M,NYSE converts to 2020-02-28 when called in March 2020 (last
+//                           business day of the previous month)
+//   CCYY-MM-DD,SOM,+2D,NYSE converts to 2020-03-04 when called in March 2020 (third
+//                           business day of the month)
 //
-// As this is only converting the date component, hh, mm, or ss are simply converted to
-// 00. Any value not matching one of these magic variables remains in final string
+// Dates not in CALENDAR are moved to the prior available date, or the next following a
+// start of period anchor, or by the business day convention CALADJUST if given (see
+// CalAdjust), which also determines the direction a day shift starts from when called
+// on an unavailable date
+//
+// e.g.
+//   CCYY-MM-DD,+1M,NYSE,modified-following  converts to 2027-01-29 when called on 2026-12-31
+//   CCYY-MM-DD,+1M,NYSE,following           converts to 2027-02-01 when called on 2026-12-31
+//
+// hh, mm and ss are the time of day asof (now unless given), after any h, m or s shifts.
+// Any value not matching one of these magic variables remains in final string
 //
 // e.g.
 //   CCYYMM22          converts to 20200322 when called between 2020-03-01 and 2020-03-01
 //   CCYYMM22,+2D      converts to 20200322 when called on 2020-03-22 (Feature!)
-func ConvertDate(d string, timezone string, calendarPath []string, asof string) (datestring string, err error) {
+//   CCYYMMDDhh,+1h    converts to 2020032800 when called on 2020-03-27 23:59:59
+//   IYYY-WW           converts to 2020-13 when called on 2020-03-27
+//   DOW DD MON CCYY   converts to Fri 27 Mar 2020 when called on 2020-03-27
+//   CCYYMMDD-BD,NYSE  converts to 20200327-20 when called on 2020-03-27
+//   FYFQ              converts to 20202 when called on 2020-03-27 with fiscalYearStart October
+func ConvertDate(d string, timezone string, calendarPath []string, asof string, fiscalYearStart time.Month) (datestring string, err error) {
+	datestring, _, err = ConvertDateSteps(d, timezone, calendarPath, asof, fiscalYearStart)
+	return
+}
+
+// dateAnchors are the start and end of period STEPs of ConvertDate
+var dateAnchors = []string{"SOW", "EOW", "SOM", "EOM", "SOQ", "EOQ", "SOY", "EOY"}
+
+// isDateStep is true if field is an anchor or shift rather than a calendar
+func isDateStep(field string) bool {
+	return stringInSlice(field, dateAnchors) || (field != "" && strings.ContainsRune("+-0123456789", rune(field[0])))
+}
+
+// ConvertDateSteps is ConvertDate, also returning the date after each step of the
+// evaluation of d for debugging
+func ConvertDateSteps(d string, timezone string, calendarPath []string, asof string, fiscalYearStart time.Month) (datestring string, steps []string, err error) {
 
 	dt := strings.Split(d, ",")
-	datestring = dt[0]
-	datestring = strings.Replace(datestring, "CC", "20", 1)
-	datestring = strings.Replace(datestring, "YY", "06", 1)
-	datestring = strings.Replace(datestring, "MM", "01", 1)
-	datestring = strings.Replace(datestring, "DD", "02", 1)
-	datestring = strings.Replace(datestring, "hh", "15", 1)
-	datestring = strings.Replace(datestring, "mm", "04", 1)
-	datestring = strings.Replace(datestring, "ss", "05", 1)
-
-	if strings.Contains(datestring, "CC") ||
-		strings.Contains(datestring, "YY") ||
-		strings.Contains(datestring, "MM") ||
-		strings.Contains(datestring, "DD") ||
-		strings.Contains(datestring, "hh") ||
-		strings.Contains(datestring, "mm") ||
-		strings.Contains(datestring, "ss") {
-		// duplicated magic var (Warning)
-		err = DateEnvError{Exception: DuplicateMagicVar, MagicDate: fmt.Sprintf("only one magic var per type processed: %s", datestring)}
-		return
+	i := 1
+	for i < len(dt) && isDateStep(dt[i]) {
+		i++
 	}
-	unit := ""
-	var n int64
-	n = 0
-	useCal := false
-	var calendar string
-	if len(dt) > 1 {
-		var unitErr error
-		if len(dt[1]) < 2 {
+	shifts := dt[1:i]
+	for _, shift := range shifts {
+		if stringInSlice(shift, dateAnchors) {
+			continue
+		}
+		if len(shift) < 2 {
 			// malformed shift
-			err = DateEnvError{Exception: ErrorInShiftValue, UnitField: "second argument (shift) incorrectly formatted"}
+			err = DateEnvError{Exception: ErrorInShiftValue, UnitField: fmt.Sprintf("shift %q incorrectly formatted", shift)}
 			return
 		}
-		unit = dt[1][len(dt[1])-1:]
-		n, unitErr = strconv.ParseInt(dt[1][:len(dt[1])-1], 0, 64)
-		if unitErr != nil {
+		if _, unitErr := strconv.ParseInt(shift[:len(shift)-1], 0, 64); unitErr != nil {
 			// bad unit value
 			err = DateEnvError{Exception: ErrorInShiftValue, UnitField: unitErr.Error()}
 			return
 		}
-		if len(dt) == 3 {
-			calendar = dt[2]
-			if len(calendar) == 0 {
-				// missing cal
-				err = DateEnvError{Exception: UnknownCalendar, Calendar: "empty calendar"}
-				return
-			}
-			// cal not found
-			if _, calErr := ReadCalendar(calendar, calendarPath); calErr != nil {
-				err = DateEnvError{Exception: UnknownCalendar, Calendar: calErr.Error()}
-				return
-			}
-			useCal = true
+		if !strings.Contains("YQMWDhms", shift[len(shift)-1:]) {
+			err = DateEnvError{Exception: UnknownShiftUnit, UnitField: fmt.Sprintf("Unknown unit: %s", shift[len(shift)-1:])}
+			return
+		}
+	}
+
+	useCal := false
+	var calendar string
+	var cal Cal
+	useAdjust := false
+	var adjust CalAdjust
+	if len(dt)-i > 2 {
+		err = DateEnvError{Exception: CommaError}
+		return
+	}
+	if len(dt)-i >= 1 {
+		calendar = dt[i]
+		if len(calendar) == 0 {
+			// missing cal
+			err = DateEnvError{Exception: UnknownCalendar, Calendar: "empty calendar"}
+			return
 		}
+		// cal not found
+		var calErr error
+		if cal, calErr = ReadCalendar(calendar, calendarPath); calErr != nil {
+			err = DateEnvError{Exception: UnknownCalendar, Calendar: calErr.Error()}
+			return
+		}
+		useCal = true
+	}
+	if len(dt)-i == 2 {
+		var adjErr error
+		if adjust, adjErr = ParseCalAdjust(dt[i+1]); adjErr != nil || dt[i+1] == "" {
+			err = DateEnvError{Exception: UnknownCalAdjust, UnitField: fmt.Sprintf("unrecognized CalAdjust %q", dt[i+1])}
+			return
+		}
+		useAdjust = true
 	}
 
 	/*
@@ -94,39 +162,306 @@ func ConvertDate(d string, timezone string, calendarPath []string, asof string)
 	   t := time.Now().In(loc) //FIXME: this needs to use rpeat.Now to facilitate testing
 	*/
 	t := now(timezone, asof)
+	step := func(name string) {
+		steps = append(steps, fmt.Sprintf("%-24s %s", name, t.Format("2006-01-02 15:04:05 Mon")))
+	}
+	step("asof")
 
-	if len(dt) > 1 {
-		switch unit {
+	forward := false
+	anchored := false
+	atEnd := false
+	for _, shift := range shifts {
+		if stringInSlice(shift, dateAnchors) {
+			t = anchorDate(t, shift)
+			anchored = true
+			atEnd = shift == "EOM" || shift == "EOQ" || shift == "EOY"
+			if !useAdjust {
+				forward = shift[0] == 'S'
+			}
+			step(shift)
+			continue
+		}
+		n, _ := strconv.Atoi(shift[:len(shift)-1])
+		switch shift[len(shift)-1:] {
 		case "Y":
-			t = t.AddDate(int(n), 0, 0)
+			t = shiftMonths(t, n*12, anchored, atEnd)
 		case "Q":
-			t = t.AddDate(0, int(n)*3, 0)
+			t = shiftMonths(t, n*3, anchored, atEnd)
 		case "M":
-			t = t.AddDate(0, int(n), 0)
+			t = shiftMonths(t, n, anchored, atEnd)
 		case "W":
-			t = t.AddDate(0, 0, int(n)*7)
+			t = t.AddDate(0, 0, n*7)
+			atEnd = false
 		case "D":
-			if useCal { // NB: is this covered below?
-				t = addDayWithCal(t, int(n), calendar, calendarPath, false)
-			} else {
-				t = t.AddDate(0, 0, int(n))
+			atEnd = false
+			if useCal {
+				c := cal
+				c.Forward = forward
+				if useAdjust {
+					// n business days from the unadjusted date, the CalAdjust convention
+					// is applied once after all shifts
+					if n == 0 {
+						step(shift + " " + calendar)
+						continue
+					}
+					c.Forward = n < 0
+				}
+				var day time.Time
+				if day, err = addDateWithCal(t, 0, 0, n, c); err != nil {
+					err = DateEnvError{Exception: UnknownCalendar, Calendar: err.Error()}
+					return
+				}
+				t = onDate(t, day)
+				step(shift + " " + calendar)
+				continue
 			}
-		default:
-			err = DateEnvError{Exception: UnknownShiftUnit, UnitField: fmt.Sprintf("Unknown unit: %s", unit)}
-			return
+			t = t.AddDate(0, 0, n)
+		case "h":
+			t = t.Add(time.Duration(n) * time.Hour)
+			atEnd = false
+		case "m":
+			t = t.Add(time.Duration(n) * time.Minute)
+			atEnd = false
+		case "s":
+			t = t.Add(time.Duration(n) * time.Second)
+			atEnd = false
 		}
+		step(shift)
 	}
 
+	if useCal && useAdjust {
+		if adj, _ := cal.adjustDate(dateAsInt(t), adjust); adj != 0 {
+			t = onDate(t, time.Date(adj/10000, time.Month(adj/100%100), adj%100, 0, 0, 0, 0, t.Location()))
+		}
+		step(calendar + " " + adjust.String())
+	} else if useCal {
+		// adjust to appropriate non-holiday
+		c := cal
+		c.Forward = forward
+		var day time.Time
+		if day, err = addDateWithCal(t, 0, 0, 0, c); err != nil {
+			err = DateEnvError{Exception: UnknownCalendar, Calendar: err.Error()}
+			return
+		}
+		t = onDate(t, day)
+		step(calendar)
+	}
+	var c *Cal
 	if useCal {
-		t = addDayWithCal(t, 0, calendar, calendarPath, false) // adjust to appropriate non-holiday
+		c = &cal
 	}
-	datestring = t.Format(datestring)
-
 	// this needs to happen after date shift to be able to calculate correct qtr
-	if strings.Contains(datestring, "QTR") {
-		mo := int(t.Month())
-		qtr := fmt.Sprintf("%d", (mo-1)/3+1)
-		datestring = strings.Replace(datestring, "QTR", qtr, -1)
-	}
+	datestring = formatDate(dt[0], t, c, fiscalYearStart)
 	return
 }
+
+// onDate moves t to the calendar date of day, keeping its time of day
+func onDate(t time.Time, day time.Time) time.Time {
+	y, m, d := day.Date()
+	hh, mm, ss := t.Clock()
+	return time.Date(y, m, d, hh, mm, ss, 0, t.Location())
+}
+
+// anchorDate moves t to the start or end of its week, month, quarter or year
+func anchorDate(t time.Time, anchor string) time.Time {
+	y, m, d := t.Date()
+	hh, mm, ss := t.Clock()
+	q := (m-1)/3*3 + 1
+	switch anchor {
+	case "SOW":
+		d -= (int(t.Weekday()) + 6) % 7
+	case "EOW":
+		d += 6 - (int(t.Weekday())+6)%7
+	case "SOM":
+		d = 1
+	case "EOM":
+		m, d = m+1, 0
+	case "SOQ":
+		m, d = q, 1
+	case "EOQ":
+		m, d = q+3, 0
+	case "SOY":
+		m, d = time.January, 1
+	case "EOY":
+		m, d = time.December, 31
+	}
+	return time.Date(y, m, d, hh, mm, ss, 0, t.Location())
+}
+
+// shiftMonths adds n months to t as AddDate, or if clamp limiting the day to the last of
+// the resulting month, or moving to it if atEnd
+func shiftMonths(t time.Time, n int, clamp, atEnd bool) time.Time {
+	if !clamp {
+		return t.AddDate(0, n, 0)
+	}
+	y, m, d := t.Date()
+	hh, mm, ss := t.Clock()
+	last := time.Date(y, m+time.Month(n)+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	if d > last || atEnd {
+		d = last
+	}
+	return time.Date(y, m+time.Month(n), d, hh, mm, ss, 0, t.Location())
+}
+
+// dateVars are the magic variables of a DateEnv format, longest first where one is the
+// prefix of another
+var dateVars = []string{"EPOCH", "Month", "IYYY", "QTR", "DDD", "MON", "DOW", "CC", "YY", "MM", "DD", "hh", "mm", "ss", "WW", "FY", "FQ", "BD"}
+
+// dateWords are the dateVars only replaced as words, see ConvertDate
+var dateWords = []string{"EPOCH", "Month", "IYYY", "DDD", "MON", "DOW", "WW", "FY", "FQ", "BD"}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// dateVarAt returns the magic variable at i of format, if any. afterVar is true if the
+// text before i is a variable
+func dateVarAt(format string, i int, afterVar bool) string {
+	for _, v := range dateVars {
+		if !strings.HasPrefix(format[i:], v) {
+			continue
+		}
+		if stringInSlice(v, dateWords) {
+			if i > 0 && isLetter(format[i-1]) && !afterVar {
+				continue
+			}
+			if j := i + len(v); j < len(format) && isLetter(format[j]) && dateVarAt(format, j, true) == "" {
+				continue
+			}
+		}
+		return v
+	}
+	return ""
+}
+
+// formatDate replaces each magic variable in format with its value for t, leaving any
+// other text as is
+func formatDate(format string, t time.Time, cal *Cal, fiscalYearStart time.Month) string {
+	var b strings.Builder
+	afterVar := false
+	for i := 0; i < len(format); {
+		v := dateVarAt(format, i, afterVar)
+		afterVar = v != ""
+		if v == "" {
+			b.WriteByte(format[i])
+			i++
+			continue
+		}
+		i += len(v)
+		switch v {
+		case "CC":
+			fmt.Fprintf(&b, "%02d", t.Year()/100)
+		case "YY":
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+		case "MM":
+			fmt.Fprintf(&b, "%02d", t.Month())
+		case "DD":
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case "hh":
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case "mm":
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case "ss":
+			fmt.Fprintf(&b, "%02d", t.Second())
+		case "QTR":
+			fmt.Fprintf(&b, "%d", (int(t.Month())-1)/3+1)
+		case "DDD":
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case "WW":
+			_, week := t.ISOWeek()
+			fmt.Fprintf(&b, "%02d", week)
+		case "IYYY":
+			year, _ := t.ISOWeek()
+			fmt.Fprintf(&b, "%04d", year)
+		case "MON":
+			b.WriteString(t.Format("Jan"))
+		case "Month":
+			b.WriteString(t.Format("January"))
+		case "DOW":
+			b.WriteString(t.Format("Mon"))
+		case "EPOCH":
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case "FY":
+			year := t.Year()
+			if fiscalYearStart > time.January && t.Month() >= fiscalYearStart {
+				year++
+			}
+			fmt.Fprintf(&b, "%04d", year)
+		case "FQ":
+			if fiscalYearStart < time.January {
+				fiscalYearStart = time.January
+			}
+			fmt.Fprintf(&b, "%d", (int(t.Month())-int(fiscalYearStart)+12)%12/3+1)
+		case "BD":
+			fmt.Fprintf(&b, "%02d", businessDay(t, cal))
+		}
+	}
+	return b.String()
+}
+
+// businessDay returns the number of available dates in cal from the start of the month
+// through t, or of weekdays if cal is nil
+func businessDay(t time.Time, cal *Cal) int {
+	if cal != nil {
+		dt := dateAsInt(t)
+		return sort.SearchInts(cal.DatesIn, dt+1) - sort.SearchInts(cal.DatesIn, dt/100*100+1)
+	}
+	n := 0
+	for d := 1; d <= t.Day(); d++ {
+		wd := time.Date(t.Year(), t.Month(), d, 0, 0, 0, 0, time.UTC).Weekday()
+		if wd != time.Saturday && wd != time.Sunday {
+			n++
+		}
+	}
+	return n
+}
+
+// AsOfPolicy is the time DateEnv of scheduled runs is evaluated relative to
+type AsOfPolicy int
+
+const (
+	AsOfNow     AsOfPolicy = iota // time the run starts
+	AsOfTrigger                   // time the trigger was scheduled for (NextStart)
+)
+
+var asOfNames = [...]string{"now", "trigger"}
+
+func (p AsOfPolicy) String() string {
+	if p < AsOfNow || p > AsOfTrigger {
+		return "Unknown"
+	}
+	return asOfNames[p]
+}
+
+// ParseAsOfPolicy converts a DateEnvAsOf name, with the empty string being the default now
+func ParseAsOfPolicy(policy string) (AsOfPolicy, error) {
+	if policy == "" {
+		return AsOfNow, nil
+	}
+	for i, name := range asOfNames {
+		if strings.EqualFold(policy, name) {
+			return AsOfPolicy(i), nil
+		}
+	}
+	return AsOfNow, fmt.Errorf("unrecognized DateEnvAsOf %s (expecting one of %s)", policy, strings.Join(asOfNames[:], ", "))
+}
+
+// ParseFiscalYearStart returns the first month of the fiscal year from a month name
+// (e.g. "October" or "Oct") or number, defaulting to January if empty
+func ParseFiscalYearStart(month string) (time.Month, error) {
+	if month == "" {
+		return time.January, nil
+	}
+	if n, err := strconv.Atoi(month); err == nil {
+		if n < 1 || n > 12 {
+			return time.January, fmt.Errorf("FiscalYearStart must be a month 1-12, got %d", n)
+		}
+		return time.Month(n), nil
+	}
+	for m := time.January; m <= time.December; m++ {
+		if strings.EqualFold(month, m.String()) || strings.EqualFold(month, m.String()[:3]) {
+			return m, nil
+		}
+	}
+	return time.January, fmt.Errorf("unrecognized FiscalYearStart %q", month)
+}
@@ -0,0 +1,70 @@
+package rpeat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConvertDateMonthShifts(t *testing.T) {
+	tests := []struct {
+		datevar string
+		asof    string
+		want    string
+	}{
+		// without an anchor, shifts overflow as AddDate
+		{"CCYY-MM-DD,-1M", "20200331120000", "2020-03-02"},
+		{"CCYY-MM-DD,+1M", "20200131120000", "2020-03-02"},
+		{"CCYY-MM-DD,+1Q", "20201130120000", "2021-03-02"},
+		{"CCYY-MM-DD,+1Y", "20200229120000", "2021-03-01"},
+		{"CCYY-MM-DD,-1M", "20200315120000", "2020-02-15"},
+		// following an anchor, shifts are limited to the end of the month
+		{"CCYY-MM-DD,SOM,-1M", "20200331120000", "2020-02-01"},
+		{"CCYY-MM-DD,EOM,-1M", "20200331120000", "2020-02-29"},
+		{"CCYY-MM-DD,EOM,-1M,+1M", "20200331120000", "2020-03-31"},
+		{"CCYY-MM-DD,EOQ,+1Q", "20200215120000", "2020-06-30"},
+		{"CCYY-MM-DD,SOW,+1M", "20200131120000", "2020-02-27"},
+	}
+	for _, tt := range tests {
+		got, err := ConvertDate(tt.datevar, "America/New_York", nil, tt.asof, 0)
+		if err != nil {
+			t.Errorf("ConvertDate(%q) asof %s: %s", tt.datevar, tt.asof, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ConvertDate(%q) asof %s = %s; want %s", tt.datevar, tt.asof, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDateLiteralText(t *testing.T) {
+	asof := time.Date(2020, 3, 27, 9, 5, 7, 0, time.UTC)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		// variables replaced anywhere, as they always have been
+		{"MONTHLY_CCYYMM", "MONTHLY_202003"},
+		{"report_CCYYMMDD.csv", "report_20200327.csv"},
+		{"QTRly", "1ly"},
+		// word variables kept in literal text
+		{"BDAY_DD", "BDAY_27"},
+		{"Monthly", "Monthly"},
+		{"DOWNLOAD", "DOWNLOAD"},
+		{"FYI", "FYI"},
+		{"EPOCHS", "EPOCHS"},
+		// word variables delimited by other text or variables
+		{"DOW DD MON CCYY", "Fri 27 Mar 2020"},
+		{"CCYYMMDD-BD", "20200327-20"},
+		{"IYYY-WW", "2020-13"},
+		{"FYFQ", "20201"},
+		{"MONYY", "Mar20"},
+		{"CCYYDDD", "2020087"},
+		{"Month_CCYY", "March_2020"},
+		{"EPOCH", "1585299907"},
+	}
+	for _, tt := range tests {
+		if got := formatDate(tt.format, asof, nil, time.January); got != tt.want {
+			t.Errorf("formatDate(%q) = %q; want %q", tt.format, got, tt.want)
+		}
+	}
+}
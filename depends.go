@@ -11,6 +11,7 @@ var _ = strings.Split
 
 type depEvt struct {
 	JobUUID  uuid.UUID
+	RunUUID  uuid.UUID
 	Name     string
 	JobState JState
 }
@@ -193,14 +194,14 @@ func (client *DependencyClient) CheckDependency(d Dependency, e *depEvt) (isOK,
 				}
 				client.statenames[uuid] = jstate // set statename of dependency e.g. success or failed
 				client.states[uuid] = true       // set state to 'true'
-				if jstate == "failed" && d.Action == "completed_failed" {
+				if (jstate == "failed" || jstate == "limitexceeded") && d.Action == "completed_failed" {
 					client.job.JobsControl.lock.Lock()
 					client.job.JobsControl.nfailures++
 					client.job.JobsControl.lock.Unlock()
 				}
 				//log.Printf("[[[ %s ]]] Job:%s uuid:%s jstate(trigger):%s Action:%s",fmt.Sprintf(Orange, "processing depEvt"),client.job.Name,uuid,jstate,d.Action)
 			} else {
-				if stringInSlice(jstate, []string{"failed", "retrying", "held", "stopped", "warning", "warning2", "warning3", "depwarning", "depfailed", "depretry"}) {
+				if stringInSlice(jstate, []string{"failed", "limitexceeded", "retrying", "held", "stopped", "warning", "warning2", "warning3", "depwarning", "depfailed", "depretry"}) {
 					isDepNotOK = true
 					client.statenames[uuid] = jstate // set statename of dependency e.g. success or failed
 					return
@@ -372,7 +373,7 @@ func (client *DependencyClient) watch() {
 				case JRetrying:
 					client.job.setHold(false)
 					client.job.setJobState(JDepRetry)
-				case JFailed:
+				case JFailed, JLimitExceeded:
 					client.job.setHold(false)
 					client.job.setJobState(JDepFailed)
 				default:
@@ -407,6 +408,14 @@ type DependencyGraph struct {
 }
 
 func (job *Job) getDependencyGraph(sd *ServerData) DependencyGraphs {
+	return job.dependencyGraph(sd, make(map[uuid.UUID]bool))
+}
+
+// dependencyGraph builds the graph of job, not descending into jobs already on the path
+// as controllers (Jobs and Matrix) and their jobs depend on each other
+func (job *Job) dependencyGraph(sd *ServerData, path map[uuid.UUID]bool) DependencyGraphs {
+	path[job.JobUUID] = true
+	defer delete(path, job.JobUUID)
 	jobmap := sd.jobs
 	var depGraph []DependencyGraph
 	if job.Dependency != nil {
@@ -420,8 +429,8 @@ func (job *Job) getDependencyGraph(sd *ServerData) DependencyGraphs {
 			depGraph[i].TriggerNames = make(JobTrigger)
 			for trigger, state := range dep.Dependencies {
 				if j, ok := jobmap[sd.jobNameUUID[trigger].String()]; ok {
-					if j.JobUUID != job.JobUUID {
-						depGraph[i].Triggers[j.JobUUID.String()] = j.getDependencyGraph(sd)
+					if !path[j.JobUUID] {
+						depGraph[i].Triggers[j.JobUUID.String()] = j.dependencyGraph(sd, path)
 					}
 					depGraph[i].TriggerUUIDs[j.JobUUID.String()] = state
 					depGraph[i].TriggerNames[j.Name] = state
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"html/template"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -92,6 +93,7 @@ var ClientHeaderHTML = `
     </div>
     <div style="text-align: right;">
         <button class=server-button style='border: 1px solid grey; background:transparent;'><a style="color:inherit; text-decoration:none;" href="https://rpeat.io/docs" target="_blank">rpeat.io docs</a></button>
+        <button class=server-button onclick="location.href='{{ .Base }}/calendars';">calendars</button>
         <button class=server-button onclick="reqServerInfo();">server details</button>
         <button class=server-button onclick="reqServerRestart();">reload server</button>
     </div>
@@ -175,13 +177,62 @@ var JobsHTML = `
 </body>
 </html>`
 
+var CalendarsHTML = `
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<link rel="icon" type="image/x-icon" href="/assets/favicon.ico" />
+<meta charset="utf-8"/>
+<title>{{ .Config.Name }} calendars</title>
+<style>
+  {{ template "ClientCSS" }}
+</style>
+
+<script>
+  {{ template "ClientJS" . }}
+</script>
+
+</head>
+<body>
+
+<div id="dashboard">
+  {{ template "ClientHeaderHTML" . }}
+  <div class=calendar-nav>
+    <a href="?month={{ .Prev }}{{ .Query }}">&lt;</a>
+    <span class=calendar-title>{{ .Month }}</span>
+    <a href="?month={{ .Next }}{{ .Query }}">&gt;</a>
+  </div>
+  <div class=calendar-list>
+    {{ range .Calendars }}<a class="calendar-link{{ if index $.Selected .Name }} calendar-selected{{ end }}" href="?month={{ $.This }}&calendar={{ .Name }}" title="{{ .Source }}{{ if .First }} {{ .First }}-{{ .Last }}{{ end }}{{ if .Error }} {{ .Error }}{{ end }}">{{ .Name }}</a> {{ end }}
+  </div>
+  {{ if not .Months }}<div class=calendar-list>no calendars are used by jobs - select a calendar above</div>{{ end }}
+  {{ range .Months }}
+  <table class=calendar-month>
+    <tr class=group><th colspan=7>{{ .Info.Name }}{{ if .Info.Jobs }} <span class=calendar-jobs>({{ stringify .Info.Jobs }})</span>{{ end }}</th></tr>
+    {{ if .Error }}<tr><td colspan=7 class=calendar-error>{{ .Error }}</td></tr>{{ else }}
+    <tr><th>Sun</th><th>Mon</th><th>Tue</th><th>Wed</th><th>Thu</th><th>Fri</th><th>Sat</th></tr>
+    {{ range .Weeks }}<tr>{{ range . }}{{ if .Day }}<td class="calendar-day{{ if not .Available }} calendar-closed{{ end }}{{ if .Today }} calendar-today{{ end }}" title="{{ .Date }}{{ if .Holiday }} {{ .Holiday }}{{ end }}"><div class=calendar-date>{{ .Day }}</div>{{ if .Holiday }}<div class=calendar-holiday>{{ .Holiday }}</div>{{ end }}{{ range .Jobs }}<div class=calendar-job>{{ . }}</div>{{ end }}</td>{{ else }}<td></td>{{ end }}{{ end }}</tr>
+    {{ end }}{{ end }}
+  </table>
+  {{ end }}
+</div>
+
+<div id="info"></div>
+</body>
+</html>`
+
 func HistoryBars(job Job) template.HTML {
 	bars := make([]string, 0, len(job.History))
 	for _, h := range job.History {
 		if !h.isNull() {
 			jobstate := fmt.Sprintf(`<img src="/assets/%s.png" alt="%s">`, h.JobStateString, h.JobStateString)
-			bars = append(bars, fmt.Sprintf(`<tr class=history-row id="%s" onclick="openLog('%s','%s',100);"><td class=runuuid>%s</td><td class=nextstart>%s</td><td class=nextstart>%s</td><td class=elapsed>%s</td><td class="kstate k%s">%s</td><td>%d</td><td>view log</td></tr>`,
-				h.RunUUID, job.JobUUID.String(), h.RunUUID, h.RunUUID, h.Start, h.Stop, h.Elapsed, h.JobStateString, jobstate, h.ExitCode))
+			usage := ""
+			if h.Usage != nil {
+				usage = template.HTMLEscapeString(h.Usage.String())
+			}
+			bars = append(bars, fmt.Sprintf(`<tr class=history-row id="%s" title="%s" onclick="openLog('%s','%s',100);"><td class=runuuid>%s</td><td class=nextstart>%s</td><td class=nextstart>%s</td><td class=elapsed>%s</td><td class="kstate k%s">%s</td><td>%d</td><td>view log</td></tr>`,
+				h.RunUUID, usage, job.JobUUID.String(), h.RunUUID, h.RunUUID, h.Start, h.Stop, h.Elapsed, h.JobStateString, jobstate, h.ExitCode))
 		} else {
 			bars = append(bars, `<tr class=history-row><td class=runuuid></td><td class=nextstart></td><td class=nextstart></td><td class=elapsed></td><td class="kstate"></td><td></td><td></td></tr>`)
 		}
@@ -189,6 +240,63 @@ func HistoryBars(job Job) template.HTML {
 	return template.HTML(strings.Join(bars, "\n"))
 }
 
+// UsageChart plots MaxRSS and CPU of the runs in History, oldest first, each scaled to
+// its largest value. History is copied under the job lock as runs may be recorded
+// while the page is rendered
+func UsageChart(job *Job) template.HTML {
+	job.Lock()
+	history := append([]JobHistory(nil), job.History...)
+	job.Unlock()
+	var runs []JobHistory
+	for i := len(history) - 1; i >= 0; i-- {
+		if h := history[i]; !h.isNull() && h.Usage != nil {
+			runs = append(runs, h)
+		}
+	}
+	if len(runs) < 2 {
+		return ""
+	}
+	var maxrss, maxcpu float64
+	for _, h := range runs {
+		maxrss = math.Max(maxrss, float64(h.Usage.MaxRSS))
+		maxcpu = math.Max(maxcpu, h.Usage.CPU())
+	}
+	const width, height, pad = 600.0, 120.0, 10.0
+	x := func(i int) float64 {
+		return pad + float64(i)*(width-2*pad)/float64(len(runs)-1)
+	}
+	y := func(v, max float64) float64 {
+		if max == 0 {
+			return height - pad
+		}
+		return height - pad - v/max*(height-2*pad)
+	}
+	var rss, cpu, points []string
+	for i, h := range runs {
+		title := template.HTMLEscapeString(fmt.Sprintf("%s %s", h.Start, h.Usage))
+		for _, p := range []struct {
+			y     float64
+			line  *[]string
+			color string
+		}{
+			{y(float64(h.Usage.MaxRSS), maxrss), &rss, "orange"},
+			{y(h.Usage.CPU(), maxcpu), &cpu, "steelblue"},
+		} {
+			*p.line = append(*p.line, fmt.Sprintf("%.1f,%.1f", x(i), p.y))
+			points = append(points, fmt.Sprintf(`<circle cx="%.1f" cy="%.1f" r="3" fill="%s"><title>%s</title></circle>`, x(i), p.y, p.color, title))
+		}
+	}
+	svg := fmt.Sprintf(`<svg class=usage-chart viewBox="0 0 %.0f %.0f" width="100%%" height="%.0f">
+<polyline fill="none" stroke="orange" stroke-width="2" points="%s"/>
+<polyline fill="none" stroke="steelblue" stroke-width="2" points="%s"/>
+%s
+<text x="%.0f" y="12" font-size="10" fill="orange">maxrss (max %s)</text>
+<text x="%.0f" y="24" font-size="10" fill="steelblue">cpu (max %.2fs)</text>
+</svg>`, width, height, height, strings.Join(rss, " "), strings.Join(cpu, " "), strings.Join(points, "\n"),
+		pad, formatSize(int64(maxrss)), pad, maxcpu)
+	return template.HTML(svg)
+}
+
 func DateEnvEval(job Job) template.HTML {
 	DateEnv := make([]string, 0)
 	loc, _ := time.LoadLocation(job.Timezone)
@@ -205,7 +313,8 @@ func DateEnvEval(job Job) template.HTML {
 		}
 		dname := kv[0]
 		dval := kv[1]
-		dt, _ := ConvertDate(dval, job.Timezone, job.CalendarDirs, asof)
+		fiscalYearStart, _ := ParseFiscalYearStart(job.FiscalYearStart)
+		dt, _ := ConvertDate(dval, job.Timezone, job.CalendarDirs, asof, fiscalYearStart)
 		dt = fmt.Sprintf("%s=%s", dname, dt)
 		ServerLogger.Printf("DateEnv: %s", dt)
 		DateEnv = append(DateEnv, dt)
@@ -281,6 +390,7 @@ var JobView = `
       <a class=cronstart><b>CronStart</b>: {{ .Job.CronStart }}</a>
       <a class=cronend><b>CronEnd</b>: {{ .Job.CronEnd }}</a>
       <a class=cronrestart><b>CronRestart</b>: {{ .Job.CronRestart }}</a>
+      {{if .Job.RRStart}}<a class=rrstart><b>RRStart</b>: {{ stringify .Job.RRStart }}</a>{{end}}
     {{end}}
     </div>
   </td>
@@ -289,6 +399,7 @@ var JobView = `
   <td class="kstate k{{ .Job.JobState }}">
     <span class=dropdown>
       <img src="/assets/{{ .Job.JobState }}.png" alt="{{ .Job.JobState }}">
+      {{ if .Job.Warning }}<span class=runtime-warning title="{{ .Job.Warning }}">!</span>{{ end }}
     </span>
   </td>
   {{ $perms := index .Authorized .UUID }}
@@ -299,6 +410,22 @@ var JobView = `
 </tr>
 </table>
 </div>
+{{ if .Job.Params }}
+<div id="params" class="job-params">
+  <table class=job-info>
+    <tr class=group><th colspan=4>Params</th></tr>
+    {{ range .Job.Params }}
+    <tr>
+      <td>{{ .Name }}</td>
+      <td>{{ if .Values }}{{ $default := .Default }}<select class=param-input data-name="{{ .Name }}" data-default="{{ .Default }}">{{ range .Values }}<option{{ if eq . $default }} selected{{ end }}>{{ . }}</option>{{ end }}</select>{{ else }}<input class=param-input data-name="{{ .Name }}" data-default="{{ .Default }}" value="{{ .Default }}"{{ if .Regex }} pattern="{{ .Regex }}"{{ end }}>{{ end }}</td>
+      <td>{{ or .Type "string" }}</td>
+      <td>{{ .Description }}</td>
+    </tr>
+    {{ end }}
+    <tr><td></td><td colspan=3><button class="server-button" onclick='startWithParams("{{ .Job.JobUUID }}")'>start</button> <span id="params-status"></span></td></tr>
+  </table>
+</div>
+{{ end }}
 <div id="history" class="job-history">
   <table class=history-table id="{{ .Job.JobUUID }}-history">
     <tr>
@@ -313,6 +440,14 @@ var JobView = `
     {{ historyBars .Job }}
   </table>
 </div>
+{{ with usageChart .Job }}
+<div id="usage" class="job-history">
+  <table class=job-info>
+    <tr class=group><th>Usage</th></tr>
+    <tr><td>{{ . }}</td></tr>
+  </table>
+</div>
+{{ end }}
 
 <div id="logs" class="job-logs">
   <span style='font-family: sans-serif; font-size: 80%;'>stdout </span>
@@ -338,6 +473,11 @@ var JobView = `
   <tr class=group><th colspan=2>Configuration</th></tr>
   <tr class=header><th style="width: 20%;"></th><th style="width: 80%;"></th></tr>
   <tr><td>Cmd</td><td> {{ .Job.Cmd }}</td></tr>
+  <tr><td>Shell</td><td> {{ .Job.Shell }}</td></tr>
+  {{ if .Job.Script }}<tr><td>Script</td><td><pre class=script>{{ .Job.Script }}</pre></td></tr>{{ end }}
+  {{ with .Job.WorkDir }}<tr><td>WorkDir</td><td> {{ . }}{{ if $.Job.CreateWorkDir }} (created){{ end }}</td></tr>{{ end }}
+  {{ with .Job.Umask }}<tr><td>Umask</td><td> {{ . }}</td></tr>{{ end }}
+  {{ with .Job.Stdin }}<tr><td>Stdin</td><td><pre class=script>{{ . }}</pre></td></tr>{{ end }}
   <tr><td>Cmd (Evaluated)</td><td> {{ .Job.CmdEval }}</td></tr>
   <tr><td>Description</td><td> {{ .Job.Description }}</td></tr>
   <tr><td>Comment</td><td> {{ .Job.Comment }}</td></tr>
@@ -349,6 +489,8 @@ var JobView = `
   <tr><td>Group</td><td> {{ stringify .Job.Group }}</td></tr>
   <tr><td>Type</td><td> {{ .Job.Type }}</td></tr>
   <tr><td>User</td><td> {{ .Job.User }}</td></tr>
+  {{ with .Job.RunAs }}<tr><td>RunAs</td><td> {{ .User }}{{ if .Group }}:{{ .Group }}{{ end }}{{ if .Groups }} ({{ stringify .Groups }}){{ end }}</td></tr>{{ end }}
+  {{ with .Job.Limits }}<tr><td>Limits</td><td> {{ . }}</td></tr>{{ end }}
   <tr><td>Admin</td><td> {{ stringify .Job.Admin }}</td></tr>
   <tr><td>Permissions</td><td> {{ stringify .Job.Permissions }}</td></tr>
   <tr><td>Disabled</td><td> {{ .Job.Disabled }}</td></tr>
@@ -356,6 +498,9 @@ var JobView = `
   <tr><td>CronStart</td><td> {{ .Job.CronStart }}</td></tr>
   <tr><td>CronEnd</td><td> {{ .Job.CronEnd }}</td></tr>
   <tr><td>CronRestart</td><td> {{ .Job.CronRestart }}</td></tr>
+  <tr><td>RRStart</td><td> {{ stringify .Job.RRStart }}</td></tr>
+  <tr><td>RREnd</td><td> {{ stringify .Job.RREnd }}</td></tr>
+  <tr><td>RRRestart</td><td> {{ stringify .Job.RRRestart }}</td></tr>
   <tr><td>StartDay</td><td> {{ .Job.StartDay }}</td></tr>
   <tr><td>StartTime</td><td> {{ .Job.StartTime }}</td></tr>
   <tr><td>EndDay</td><td> {{ .Job.EndDay }}</td></tr>
@@ -364,6 +509,9 @@ var JobView = `
   <tr><td>Calendar</td><td> {{ .Job.Calendar }}</td></tr>
   <tr><td>CalendarDirs</td><td> {{ stringify .Job.CalendarDirs }}</td></tr>
   <tr><td>StartRule</td><td> {{ .Job.StartRule }}</td></tr>
+  <tr><td>MaxInstances</td><td> {{ .Job.MaxInstances }} ({{ .Job.Instances }} running)</td></tr>
+  <tr><td>Priority</td><td> {{ .Job.Priority }}{{ if .Job.QueuePosition }} (effective {{ .Job.EffectivePriority }}, queue position {{ .Job.QueuePosition }}){{ end }}</td></tr>
+  <tr><td>CatchUp</td><td> {{ .Job.CatchUp }} {{ .Job.CatchUpWithin }}</td></tr>
   <tr><td>ShutdownCmd</td><td> {{ .Job.ShutdownCmd }}</td></tr>
   <tr><td>ShutdownCmd (Evaluated)</td><td> {{ .Job.ShutdownCmdEval }}</td></tr>
   <tr><td>StdoutFile</td><td> {{ stringify .Job.StdoutFile }}</td></tr>
@@ -388,6 +536,26 @@ var JobView = `
     document.getElementById(tabName).style.display = "block";  
   }
   tailLog("{{ .Job.JobUUID }}","{{ .Job.RunUUID }}", 100, true, true);
+
+  // manual start overriding the Params changed from their Default
+  function startWithParams(id) {
+    var params = {};
+    var inputs = document.getElementsByClassName("param-input");
+    for (var i = 0; i < inputs.length; i++) {
+      if (inputs[i].value != inputs[i].getAttribute("data-default")) {
+        params[inputs[i].getAttribute("data-name")] = inputs[i].value;
+      }
+    }
+    var xhttp = new XMLHttpRequest();
+    xhttp.onreadystatechange = function() {
+      if (this.readyState == 4 && this.status == 200) {
+        document.getElementById("params-status").innerHTML = JSON.parse(xhttp.responseText).Status;
+      }
+    };
+    xhttp.open("POST", "{{ .Base }}/api/start", true);
+    xhttp.setRequestHeader("Content-Type", "application/json;charset=UTF-8");
+    xhttp.send(JSON.stringify({ "jobid": id, "params": params }));
+  }
 </script>
 `
 
@@ -412,7 +580,7 @@ var JobsTable = `
   {{ $job := index $jobs $id }}
   {{ $perms := index $authorized $id }}
     <tr class="job" id="{{ $job.JobUUID }}">
-      <td style="text-align: left;" class=name>{{ $job.Name }}</td>
+      <td style="text-align: left;" class=name>{{ if $job.Parent }}&nbsp;&nbsp;&#8627; {{ end }}{{ $job.Name }}</td>
       <td class="details dropdown">
          <a class=details target="_blank" href="{{ $.Base }}/job/{{ slugify $job.Name }}"><button class='inspect-button'></button></a>
          <div class=dropdown-content>
@@ -432,6 +600,7 @@ var JobsTable = `
           <a class=cronstart><b>CronStart</b>: {{ $job.CronStart }}</a>
           <a class=cronend><b>CronEnd</b>: {{ $job.CronEnd }}</a>
           <a class=cronrestart><b>CronRestart</b>: {{ $job.CronRestart }}</a>
+          {{if $job.RRStart}}<a class=rrstart><b>RRStart</b>: {{ stringify $job.RRStart }}</a>{{end}}
         {{end}}
         </div>
       </td>
@@ -440,11 +609,16 @@ var JobsTable = `
       <td class="kstate k{{ $job.JobState }}">
         <span class=dropdown>
           <img src="/assets/{{ $job.JobState }}.png" alt="{{ $job.JobState }}">
+          {{ if $job.Warning }}<span class=runtime-warning title="{{ $job.Warning }}">!</span>{{ end }}
+          {{ if $job.QueuePosition }}<div class=dropdown-content><div>queued<hr/>queue position: {{ $job.QueuePosition }}<br/>priority: {{ $job.EffectivePriority }}</div></div>{{ end }}
+          {{ if $job.Blackout }}<div class=dropdown-content><div>blackout<hr/>{{ $job.Blackout }}</div></div>{{ end }}
+          {{ if $job.ConfigError }}<div class=dropdown-content><div>configerror<hr/>{{ $job.ConfigError }}</div></div>{{ end }}
+          {{ if $job.LimitExceeded }}<div class=dropdown-content><div>limitexceeded<hr/>{{ $job.LimitExceeded }}</div></div>{{ end }}
         </span>
       </td>
       {{ getElapsed $job }}
       {{ getControls $job $perms }}
-      <td class=history-trail>{{ range $history := $job.History }}<span class=dropdown><img id="{{ $history.RunUUID }}" src="/assets/{{ $history.JobStateString }}.png" alt="{{ $history.JobStateString }}"><div class='dropdown-content'><div>state: {{$history.JobStateString}}</div><hr/><div>start: {{$history.Start}}</div><div>stop: {{$history.Stop}}</div><div>elapsed: {{$history.Elapsed}}</div><div>unscheduled: {{$history.Unscheduled}}</div><div>exit: {{$history.ExitCode}}</div></div></span>{{ end }}
+      <td class=history-trail>{{ range $history := $job.History }}<span class=dropdown><img id="{{ $history.RunUUID }}" src="/assets/{{ $history.JobStateString }}.png" alt="{{ $history.JobStateString }}"><div class='dropdown-content'><div>state: {{$history.JobStateString}}</div><hr/><div>start: {{$history.Start}}</div><div>stop: {{$history.Stop}}</div><div>elapsed: {{$history.Elapsed}}</div><div>unscheduled: {{$history.Unscheduled}}</div>{{if $history.AsOf}}<div>{{if $history.Backfill}}backfill {{end}}asof: {{$history.AsOf}}</div>{{end}}{{if $history.Warning}}<div>warning: {{$history.Warning}}</div>{{end}}<div>exit: {{$history.ExitCode}}</div></div></span>{{ end }}
       </td>
     </tr>
     <script>newJob("{{ $job.JobUUID }}",{{ $job.History }});</script>
@@ -680,7 +854,7 @@ function reqServerInfo() {
     if (this.readyState == 4 && this.status == 200) {
       var obj = JSON.parse(xhttp.responseText);
       server_status = obj;
-      let count = {ready:0,onhold:0,retrywait:0,failed:0,end:0,success:0,manualsuccess:0,running:0,depwarning:0,depfailed:0,missedwarning:0,warning:0,stopped:0,allsuccess:0};
+      let count = {ready:0,onhold:0,retrywait:0,failed:0,end:0,success:0,manualsuccess:0,running:0,depwarning:0,depfailed:0,missedwarning:0,warning:0,warning2:0,queued:0,blackout:0,configerror:0,limitexceeded:0,stopped:0,allsuccess:0};
       Object.entries(server_status.jobs).forEach(([k,v]) => {let s = v.JobStateString; count[s]++;})
       let njobs = Object.values(count).reduce((x,s) => x+s);
       count.allsuccess = (count.success+count.manualsuccess+count.end);
@@ -803,6 +977,9 @@ function jobstateAbb(stateString) {
         case "failed":
           symbol = "F";
           break;
+        case "limitexceeded":
+          symbol = "L";
+          break;
         case "retryfailed":
           symbol = "R";
           break;
@@ -831,7 +1008,7 @@ function updateHistory(id, job) {
       var e = document.getElementById(id)
       if (element.JobStateString != "") {
         var jss = element.JobStateString;
-        e.querySelector(".history-trail").innerHTML = inner + "<span class=dropdown id='"+element.RunUUID+"'><img src='/assets/"+jss+".png' alt=''><div class='dropdown-content'><div>state: "+jss+"</div><hr/><div>start: "+element.Start+"</div><div>stop: "+element.Stop+"</div><div>elapsed: "+element.Elapsed+"</div><div>unscheduled: "+element.Unscheduled+"</div><div>exitCode: "+element.ExitCode+"</div></div></span>";
+        e.querySelector(".history-trail").innerHTML = inner + "<span class=dropdown id='"+element.RunUUID+"'><img src='/assets/"+jss+".png' alt=''><div class='dropdown-content'><div>state: "+jss+"</div><hr/><div>start: "+element.Start+"</div><div>stop: "+element.Stop+"</div><div>elapsed: "+element.Elapsed+"</div><div>unscheduled: "+element.Unscheduled+"</div>"+(element.AsOf ? "<div>"+(element.Backfill ? "backfill " : "")+"asof: "+element.AsOf+"</div>" : "")+(element.Warning ? "<div>warning: "+element.Warning+"</div>" : "")+(element.LimitExceeded ? "<div>limit: "+element.LimitExceeded+"</div>" : "")+(element.Usage ? "<div>cpu: "+(element.Usage.UserCPU+element.Usage.SysCPU).toFixed(2)+"s maxrss: "+(element.Usage.MaxRSS/1048576).toFixed(1)+"M</div>" : "")+"<div>exitCode: "+element.ExitCode+"</div></div></span>";
         inner = e.querySelector(".history-trail").innerHTML;
       }
   });
@@ -857,7 +1034,11 @@ function dhmsSince(obj, el) {
 function updateElapsed(id) {
     var e = document.getElementById(id);
     var started = parseInt(e.querySelector("td.started").dataset.unix) * 1000;
-    if (e.querySelector("td.kstate").className == "kstate krunning") {
+    var isRunning = function() {
+      let c = e.querySelector("td.kstate").className;
+      return c == "kstate krunning" || c == "kstate kwarning2";
+    }
+    if (isRunning()) {
       var ival = setInterval(function() {
         if ( !isNaN(servertimeUNIX) ) {  // protect against delayed ws
           var elapsed = dhms(Math.ceil(Math.round((servertimeUNIX - started) / 1000)));
@@ -865,7 +1046,7 @@ function updateElapsed(id) {
           e.querySelector("td.elapsed").innerHTML = elapsed;
         }
 
-        if (e.querySelector("td.kstate").className != "kstate krunning") {
+        if (!isRunning()) {
           clearInterval(ival)
           e.querySelector("td.elapsed").style.opacity ="25%";
         }
@@ -906,7 +1087,13 @@ async function updateJob(id, job, update, imgpath="assets") {
     jstate = "onhold";
   }
   j.querySelector("td.kstate").className = "kstate k"+jstate;
-  j.querySelector("td.kstate").innerHTML = '<td><span class=dropdown><img src="/assets/'+jstate+'.png" alt="'+jstate+'"><div class=dropdown-content><div>'+jstate+'</div></span></td>'
+  let warning = job["Warning"] ? '<span class=runtime-warning title="'+job["Warning"]+'">!</span>' : '';
+  let queued = "";
+  if (job["QueuePosition"]) {
+    let wait = isNaN(servertimeUNIX) ? "" : " (waiting "+dhms(Math.max(0, Math.round((servertimeUNIX - job["QueuedUNIX"] * 1000) / 1000)))+")";
+    queued = '<hr/>queue position: '+job["QueuePosition"]+wait+'<br/>priority: '+(job["EffectivePriority"] || 0)+(job["EffectivePriority"] != job["Priority"] ? ' (aged from '+(job["Priority"] || 0)+')' : '');
+  }
+  j.querySelector("td.kstate").innerHTML = '<td><span class=dropdown><img src="/assets/'+jstate+'.png" alt="'+jstate+'">'+warning+'<div class=dropdown-content><div>'+jstate+(job["Warning"] ? '<hr/>'+job["Warning"] : '')+(job["Blackout"] ? '<hr/>'+job["Blackout"] : '')+(job["ConfigError"] ? '<hr/>'+job["ConfigError"] : '')+(job["LimitExceeded"] ? '<hr/>'+job["LimitExceeded"] : '')+queued+'</div></span></td>'
   var e = j.querySelector("a.runid");
   if (e !== null) { e.innerHTML = "Run ID: " + job["RunUUID"]; };
   e = j.querySelector("td.runid");
@@ -929,8 +1116,8 @@ async function updateJob(id, job, update, imgpath="assets") {
     j.querySelector("td.hold").innerHTML = "<button class='hold-button'></button>";
   }
   var runstate = ["running","retrying"];
-  var endstate = ["success","manualsuccess","end","failed","retrywait","retryfailed","stopped","missed"];
-  var errstate = ["failed","retryfailed","retryfailed","stopped","missedwarning"];
+  var endstate = ["success","manualsuccess","warning","end","failed","limitexceeded","retrywait","retryfailed","stopped","missed"];
+  var errstate = ["failed","limitexceeded","retryfailed","retryfailed","stopped","missedwarning"];
   if (errstate.includes(job["JobStateString"])) {
     // disable audible alerts for the moment
     //playAlert();
@@ -938,7 +1125,7 @@ async function updateJob(id, job, update, imgpath="assets") {
   if (job["JobStateString"] == "restart") {
     await sleep(1000)
   };
-  if (job["JobStateString"] == "running") {
+  if (job["JobStateString"] == "running" || job["JobStateString"] == "warning2") {
     //var started = Date.parse(j.querySelector(".started").innerHTML)
     var started = parseInt(j.querySelector("td.started").dataset.unix) * 1000
     updateElapsed(id);
@@ -1442,6 +1629,17 @@ td.kstate, th.kstate {
   height: 100%;
   vertical-align: middle;
 }
+.runtime-warning {
+  display: inline-block;
+  margin-left: 2px;
+  padding: 0px 4px;
+  border-radius: 6px;
+  font-size: 70%;
+  font-weight: bold;
+  color: white;
+  background-color: #e0a800;
+  vertical-align: top;
+}
 .history-trail {
   width: 205px;
   text-align: left;
@@ -1601,6 +1799,59 @@ td.controls-off button {
    -o-animation: pulse 2s infinite;
     animation: pulse 2s infinite;
 }
+table.calendar-month {
+  width: 1125px;
+  table-layout: fixed;
+  margin-bottom: 2em;
+}
+td.calendar-day {
+  vertical-align: top;
+  text-align: left;
+  height: 4em;
+  font-size: 80%;
+  border: 1px solid #ddd;
+}
+td.calendar-closed {
+  background-color: #eee;
+  color: #999;
+}
+td.calendar-today {
+  border: 2px solid orange;
+}
+.calendar-holiday {
+  font-style: italic;
+}
+.calendar-job {
+  color: white;
+  background-color: #4a90d9;
+  border-radius: 3px;
+  margin-top: 2px;
+  padding: 0 3px;
+  overflow: hidden;
+  white-space: nowrap;
+}
+.calendar-nav, .calendar-list {
+  width: 1125px;
+  margin: auto;
+  margin-bottom: 1em;
+  text-align: center;
+  font-family: sans-serif;
+}
+.calendar-title {
+  padding: 0 2em;
+}
+.calendar-link, .calendar-nav a {
+  color: inherit;
+  text-decoration: none;
+  padding: 0 .5ch;
+}
+.calendar-selected {
+  font-weight: bold;
+  text-decoration: underline;
+}
+.calendar-jobs, .calendar-error {
+  font-size: 80%;
+}
 #poweredby {
   width: 128px;
   height: 16px;
@@ -0,0 +1,376 @@
+package rpeat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CalendarRules defines a calendar by rules rather than a list of dates, so it can be
+// evaluated for any year. The dates of the calendar are all days other than Weekend days
+// and Holidays. A calendar file containing a JSON object is read as CalendarRules, e.g.
+//
+//	{
+//	  "Name": "US-Bank",
+//	  "Weekend": ["Sat", "Sun"],
+//	  "Holidays": [
+//	    {"Name": "New Year's Day", "Month": 1, "Day": 1, "Observed": "nearest"},
+//	    {"Name": "Martin Luther King Jr. Day", "Month": 1, "Weekday": "Mon", "Nth": 3},
+//	    {"Name": "Memorial Day", "Month": 5, "Weekday": "Mon", "Nth": -1},
+//	    {"Name": "Good Friday", "Easter": -2},
+//	    {"Name": "Juneteenth", "Month": 6, "Day": 19, "Observed": "nearest", "From": 2022},
+//	    {"Name": "National Day of Mourning", "Date": "2025-01-09"}
+//	  ]
+//	}
+//
+// Base extends a built-in calendar (see BuiltinCalendars), and Weekend defaults to
+// Saturday and Sunday with [] for none. Dates are generated for CalendarYears either
+// side of the current year.
+type CalendarRules struct {
+	Name     string        `json:"Name,omitempty"`
+	Base     string        `json:"Base,omitempty"`
+	Weekend  []string      `json:"Weekend,omitempty"`
+	Holidays []HolidayRule `json:"Holidays,omitempty"`
+}
+
+// HolidayRule is a single holiday of CalendarRules, given as one of
+//
+//	Date          a single date "2006-01-02"
+//	Month, Day    a fixed date each year
+//	Month, Weekday, Nth
+//	              the Nth (1-5, or -1 for the last) Weekday of the month, e.g. "Mon"
+//	Easter        days relative to Easter Sunday (Western), e.g. -2 for Good Friday
+//
+// Observed moves a holiday falling on a weekend, or on an earlier holiday:
+//
+//	none      (default) not moved
+//	nearest   Saturday to Friday and Sunday to Monday
+//	next      to the following business day
+//	previous  to the preceding business day
+//	sunday    only Sunday to Monday (Saturday holidays are not observed)
+//
+// From and To limit the rule to the years in which it applies.
+type HolidayRule struct {
+	Name     string `json:"Name,omitempty"`
+	Date     string `json:"Date,omitempty"`
+	Month    int    `json:"Month,omitempty"`
+	Day      int    `json:"Day,omitempty"`
+	Weekday  string `json:"Weekday,omitempty"`
+	Nth      int    `json:"Nth,omitempty"`
+	Easter   *int   `json:"Easter,omitempty"`
+	Observed string `json:"Observed,omitempty"`
+	From     int    `json:"From,omitempty"`
+	To       int    `json:"To,omitempty"`
+}
+
+// CalendarYears is the number of years either side of the current year for which
+// rule based calendars are evaluated
+var CalendarYears = 10
+
+var weekdayNames = map[string]time.Weekday{"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	if len(s) >= 3 {
+		if wd, ok := weekdayNames[strings.ToLower(s[:3])]; ok {
+			return wd, nil
+		}
+	}
+	return time.Sunday, errors.New(fmt.Sprintf("invalid weekday %s", s))
+}
+
+// ParseCalendarRules parses a JSON calendar definition, validating each rule
+func ParseCalendarRules(b []byte) (*CalendarRules, error) {
+	var rules CalendarRules
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return nil, err
+	}
+	if err := rules.validate(); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+func (rules *CalendarRules) validate() error {
+	if rules.Base != "" {
+		if _, ok := BuiltinCalendars[rules.Base]; !ok {
+			return errors.New(fmt.Sprintf("unknown Base calendar %s", rules.Base))
+		}
+	}
+	for _, wd := range rules.Weekend {
+		if _, err := parseWeekday(wd); err != nil {
+			return err
+		}
+	}
+	for i, h := range rules.Holidays {
+		if err := h.validate(); err != nil {
+			return errors.New(fmt.Sprintf("Holidays[%d] %s: %s", i, h.Name, err))
+		}
+	}
+	return nil
+}
+
+func (h HolidayRule) validate() error {
+	n := 0
+	if h.Date != "" {
+		if _, err := time.Parse("2006-01-02", h.Date); err != nil {
+			return errors.New(fmt.Sprintf("invalid Date %s", h.Date))
+		}
+		n++
+	}
+	if h.Easter != nil {
+		n++
+	}
+	if h.Month != 0 {
+		if h.Month < 1 || h.Month > 12 {
+			return errors.New(fmt.Sprintf("invalid Month %d", h.Month))
+		}
+		if h.Weekday != "" {
+			if _, err := parseWeekday(h.Weekday); err != nil {
+				return err
+			}
+			if h.Nth == 0 || h.Nth < -1 || h.Nth > 5 {
+				return errors.New(fmt.Sprintf("invalid Nth %d (expecting 1-5 or -1 for last)", h.Nth))
+			}
+		} else if h.Day < 1 || h.Day > 31 {
+			return errors.New(fmt.Sprintf("invalid Day %d", h.Day))
+		}
+		n++
+	}
+	if n != 1 {
+		return errors.New("requires one of Date, Month or Easter")
+	}
+	switch h.Observed {
+	case "", "none", "nearest", "next", "previous", "sunday":
+	default:
+		return errors.New(fmt.Sprintf("invalid Observed %s (expecting none, nearest, next, previous or sunday)", h.Observed))
+	}
+	return nil
+}
+
+// weekend returns the weekend days, Saturday and Sunday by default
+func (rules *CalendarRules) weekend() map[time.Weekday]bool {
+	names := rules.Weekend
+	if names == nil {
+		names = []string{"Sat", "Sun"}
+	}
+	weekend := make(map[time.Weekday]bool)
+	for _, name := range names {
+		wd, _ := parseWeekday(name)
+		weekend[wd] = true
+	}
+	return weekend
+}
+
+// rules returns the holiday rules, including those of Base
+func (rules *CalendarRules) rules() []HolidayRule {
+	if rules.Base == "" {
+		return rules.Holidays
+	}
+	base := BuiltinCalendars[rules.Base]
+	return append(base.rules(), rules.Holidays...)
+}
+
+// HolidaysIn returns the holidays of year as YYYYMMDD dates with their names
+func (rules *CalendarRules) HolidaysIn(year int) map[int]string {
+	weekend := rules.weekend()
+	holidays := make(map[int]string)
+	isBusinessDay := func(t time.Time) bool {
+		_, holiday := holidays[dateAsInt(t)]
+		return !weekend[t.Weekday()] && !holiday
+	}
+	for _, h := range rules.rules() {
+		if (h.From != 0 && year < h.From) || (h.To != 0 && year > h.To) {
+			continue
+		}
+		t, ok := h.date(year)
+		if !ok {
+			continue
+		}
+		_, taken := holidays[dateAsInt(t)]
+		if weekend[t.Weekday()] || taken {
+			switch h.Observed {
+			case "nearest":
+				if t.Weekday() == time.Saturday {
+					t = t.AddDate(0, 0, -1)
+				} else if t.Weekday() == time.Sunday {
+					t = t.AddDate(0, 0, 1)
+				}
+				for !isBusinessDay(t) {
+					t = t.AddDate(0, 0, 1)
+				}
+			case "next":
+				for !isBusinessDay(t) {
+					t = t.AddDate(0, 0, 1)
+				}
+			case "previous":
+				for !isBusinessDay(t) {
+					t = t.AddDate(0, 0, -1)
+				}
+			case "sunday":
+				if t.Weekday() == time.Sunday {
+					t = t.AddDate(0, 0, 1)
+				}
+			}
+		}
+		if _, taken := holidays[dateAsInt(t)]; !taken {
+			holidays[dateAsInt(t)] = h.Name
+		}
+	}
+	return holidays
+}
+
+// date returns the unadjusted date of the holiday in year, false if it does not occur
+func (h HolidayRule) date(year int) (time.Time, bool) {
+	switch {
+	case h.Date != "":
+		t, _ := time.Parse("2006-01-02", h.Date)
+		return t, t.Year() == year
+	case h.Easter != nil:
+		return easter(year).AddDate(0, 0, *h.Easter), true
+	case h.Weekday != "":
+		wd, _ := parseWeekday(h.Weekday)
+		return nthWeekday(year, time.Month(h.Month), wd, h.Nth)
+	default:
+		t := time.Date(year, time.Month(h.Month), h.Day, 0, 0, 0, 0, time.UTC)
+		return t, t.Month() == time.Month(h.Month) // e.g. Feb 29
+	}
+}
+
+// nthWeekday returns the nth (-1 for last) weekday of month
+func nthWeekday(year int, month time.Month, wd time.Weekday, nth int) (time.Time, bool) {
+	if nth < 0 {
+		t := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+		for t.Weekday() != wd {
+			t = t.AddDate(0, 0, -1)
+		}
+		return t, true
+	}
+	t := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	for t.Weekday() != wd {
+		t = t.AddDate(0, 0, 1)
+	}
+	t = t.AddDate(0, 0, 7*(nth-1))
+	return t, t.Month() == month
+}
+
+// easter returns Easter Sunday (Gregorian) using the anonymous Gregorian algorithm
+func easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// Cal evaluates the rules for the years from through to as a calendar named calendar
+func (rules *CalendarRules) Cal(calendar string, from, to int) Cal {
+	weekend := rules.weekend()
+	holidays := make(map[int]string)
+	for y := from - 1; y <= to+1; y++ { // observed holidays may cross a year end
+		for dt, name := range rules.HolidaysIn(y) {
+			holidays[dt] = name
+		}
+	}
+	cal := NewCal(calendar)
+	cal.Rules = rules
+	cal.Holidays = make(map[int]string)
+	for t := time.Date(from, 1, 1, 0, 0, 0, 0, time.UTC); t.Year() <= to; t = t.AddDate(0, 0, 1) {
+		dt := dateAsInt(t)
+		if name, ok := holidays[dt]; ok {
+			cal.Holidays[dt] = name
+			continue
+		}
+		if !weekend[t.Weekday()] {
+			cal.DatesIn = append(cal.DatesIn, dt)
+		}
+	}
+	sort.Ints(cal.DatesIn)
+	return cal
+}
+
+// currentCal evaluates the rules for CalendarYears either side of the current year
+func (rules *CalendarRules) currentCal(calendar string) Cal {
+	y := now("UTC", "").Year()
+	return rules.Cal(calendar, y-CalendarYears, y+CalendarYears)
+}
+
+// builtinCalendar returns the calendar of BuiltinCalendars named calendar
+func builtinCalendar(calendar string) (Cal, bool) {
+	rules, ok := BuiltinCalendars[calendar]
+	if !ok {
+		return Cal{}, false
+	}
+	return rules.currentCal(calendar), true
+}
+
+func easterOffset(d int) *int {
+	return &d
+}
+
+// BuiltinCalendars are rule based calendars available by name without a calendar file.
+// A file of the same name in CalendarDirs takes precedence
+//
+//	NYSE      New York Stock Exchange trading days
+//	US-FED    Federal Reserve (US bank) business days
+//	UK        England and Wales bank holidays
+//	TARGET    TARGET2 (Euro payments) business days
+//	WEEKDAYS  Monday to Friday
+var BuiltinCalendars = map[string]*CalendarRules{
+	"NYSE": {Name: "NYSE", Holidays: []HolidayRule{
+		{Name: "New Year's Day", Month: 1, Day: 1, Observed: "sunday"},
+		{Name: "Martin Luther King Jr. Day", Month: 1, Weekday: "Mon", Nth: 3, From: 1998},
+		{Name: "Washington's Birthday", Month: 2, Weekday: "Mon", Nth: 3},
+		{Name: "Good Friday", Easter: easterOffset(-2)},
+		{Name: "Memorial Day", Month: 5, Weekday: "Mon", Nth: -1},
+		{Name: "Juneteenth", Month: 6, Day: 19, Observed: "nearest", From: 2022},
+		{Name: "Independence Day", Month: 7, Day: 4, Observed: "nearest"},
+		{Name: "Labor Day", Month: 9, Weekday: "Mon", Nth: 1},
+		{Name: "Thanksgiving Day", Month: 11, Weekday: "Thu", Nth: 4},
+		{Name: "Christmas Day", Month: 12, Day: 25, Observed: "nearest"},
+	}},
+	"US-FED": {Name: "US-FED", Holidays: []HolidayRule{
+		{Name: "New Year's Day", Month: 1, Day: 1, Observed: "sunday"},
+		{Name: "Martin Luther King Jr. Day", Month: 1, Weekday: "Mon", Nth: 3},
+		{Name: "Washington's Birthday", Month: 2, Weekday: "Mon", Nth: 3},
+		{Name: "Memorial Day", Month: 5, Weekday: "Mon", Nth: -1},
+		{Name: "Juneteenth", Month: 6, Day: 19, Observed: "sunday", From: 2021},
+		{Name: "Independence Day", Month: 7, Day: 4, Observed: "sunday"},
+		{Name: "Labor Day", Month: 9, Weekday: "Mon", Nth: 1},
+		{Name: "Columbus Day", Month: 10, Weekday: "Mon", Nth: 2},
+		{Name: "Veterans Day", Month: 11, Day: 11, Observed: "sunday"},
+		{Name: "Thanksgiving Day", Month: 11, Weekday: "Thu", Nth: 4},
+		{Name: "Christmas Day", Month: 12, Day: 25, Observed: "sunday"},
+	}},
+	"UK": {Name: "UK", Holidays: []HolidayRule{
+		{Name: "New Year's Day", Month: 1, Day: 1, Observed: "next"},
+		{Name: "Good Friday", Easter: easterOffset(-2)},
+		{Name: "Easter Monday", Easter: easterOffset(1)},
+		{Name: "Early May Bank Holiday", Month: 5, Weekday: "Mon", Nth: 1},
+		{Name: "Spring Bank Holiday", Month: 5, Weekday: "Mon", Nth: -1},
+		{Name: "Summer Bank Holiday", Month: 8, Weekday: "Mon", Nth: -1},
+		{Name: "Christmas Day", Month: 12, Day: 25, Observed: "next"},
+		{Name: "Boxing Day", Month: 12, Day: 26, Observed: "next"},
+	}},
+	"TARGET": {Name: "TARGET", Holidays: []HolidayRule{
+		{Name: "New Year's Day", Month: 1, Day: 1},
+		{Name: "Good Friday", Easter: easterOffset(-2)},
+		{Name: "Easter Monday", Easter: easterOffset(1)},
+		{Name: "Labour Day", Month: 5, Day: 1},
+		{Name: "Christmas Day", Month: 12, Day: 25},
+		{Name: "Christmas Holiday", Month: 12, Day: 26},
+	}},
+	"WEEKDAYS": {Name: "WEEKDAYS"},
+}
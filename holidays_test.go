@@ -0,0 +1,146 @@
+package rpeat
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestEaster(t *testing.T) {
+	tests := map[int]string{
+		2000: "20000423",
+		2008: "20080323",
+		2019: "20190421",
+		2024: "20240331",
+		2025: "20250420",
+		2038: "20380425",
+	}
+	for year, want := range tests {
+		if got := easter(year).Format("20060102"); got != want {
+			t.Errorf("easter(%d) = %s; want %s", year, got, want)
+		}
+	}
+}
+
+func TestNthWeekday(t *testing.T) {
+	tests := []struct {
+		year  int
+		month time.Month
+		wd    time.Weekday
+		nth   int
+		want  string
+	}{
+		{2024, time.January, time.Monday, 3, "20240115"},
+		{2024, time.November, time.Thursday, 4, "20241128"},
+		{2024, time.May, time.Monday, -1, "20240527"},
+		{2021, time.May, time.Monday, -1, "20210531"},
+		{2021, time.August, time.Monday, -1, "20210830"},
+		{2024, time.February, time.Thursday, 5, "20240229"},
+		{2021, time.February, time.Monday, 5, ""}, // no 5th Monday
+	}
+	for _, tt := range tests {
+		d, ok := nthWeekday(tt.year, tt.month, tt.wd, tt.nth)
+		got := ""
+		if ok {
+			got = d.Format("20060102")
+		}
+		if got != tt.want {
+			t.Errorf("nthWeekday(%d, %s, %s, %d) = %q; want %q", tt.year, tt.month, tt.wd, tt.nth, got, tt.want)
+		}
+	}
+}
+
+func TestHolidayObserved(t *testing.T) {
+	tests := []struct {
+		name     string
+		year     int
+		holidays []HolidayRule
+		want     []int
+	}{
+		{"weekday not moved", 2024, []HolidayRule{{Month: 7, Day: 4, Observed: "nearest"}}, []int{20240704}},
+		{"none", 2026, []HolidayRule{{Month: 7, Day: 4}}, []int{20260704}},
+		{"nearest saturday", 2026, []HolidayRule{{Month: 7, Day: 4, Observed: "nearest"}}, []int{20260703}},
+		{"nearest sunday", 2021, []HolidayRule{{Month: 7, Day: 4, Observed: "nearest"}}, []int{20210705}},
+		{"next saturday", 2026, []HolidayRule{{Month: 7, Day: 4, Observed: "next"}}, []int{20260706}},
+		{"previous sunday", 2021, []HolidayRule{{Month: 7, Day: 4, Observed: "previous"}}, []int{20210702}},
+		{"sunday on saturday", 2026, []HolidayRule{{Month: 7, Day: 4, Observed: "sunday"}}, []int{20260704}},
+		{"sunday on sunday", 2021, []HolidayRule{{Month: 7, Day: 4, Observed: "sunday"}}, []int{20210705}},
+		{"next collision", 2021, []HolidayRule{
+			{Month: 12, Day: 25, Observed: "next"},
+			{Month: 12, Day: 26, Observed: "next"},
+		}, []int{20211227, 20211228}},
+		{"next collision on weekday", 2022, []HolidayRule{
+			{Month: 12, Day: 25, Observed: "next"},
+			{Month: 12, Day: 26, Observed: "next"},
+		}, []int{20221226, 20221227}},
+		{"nearest collision", 2021, []HolidayRule{
+			{Month: 12, Day: 24, Observed: "nearest"},
+			{Month: 12, Day: 25, Observed: "nearest"},
+		}, []int{20211224, 20211227}},
+		{"previous collision", 2021, []HolidayRule{
+			{Month: 12, Day: 24, Observed: "previous"},
+			{Month: 12, Day: 25, Observed: "previous"},
+		}, []int{20211223, 20211224}},
+		{"nth and easter", 2024, []HolidayRule{
+			{Month: 5, Weekday: "Mon", Nth: -1},
+			{Easter: easterOffset(-2)},
+			{Easter: easterOffset(1)},
+		}, []int{20240329, 20240401, 20240527}},
+		{"date", 2025, []HolidayRule{{Date: "2025-01-09"}, {Date: "2024-01-09"}}, []int{20250109}},
+		{"from to", 2021, []HolidayRule{
+			{Month: 6, Day: 18, From: 2022},
+			{Month: 6, Day: 17, To: 2020},
+			{Month: 6, Day: 16, From: 2021, To: 2021},
+		}, []int{20210616}},
+	}
+	for _, tt := range tests {
+		for i := range tt.holidays {
+			tt.holidays[i].Name = fmt.Sprintf("holiday %d", i)
+		}
+		rules := &CalendarRules{Holidays: tt.holidays}
+		if err := rules.validate(); err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		var got []int
+		for dt := range rules.HolidaysIn(tt.year) {
+			got = append(got, dt)
+		}
+		sort.Ints(got)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: holidays %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuiltinCalendars(t *testing.T) {
+	tests := []struct {
+		calendar string
+		year     int
+		closed   []int // weekdays that are not business days
+	}{
+		{"NYSE", 2021, []int{20210101, 20210118, 20210215, 20210402, 20210531, 20210705, 20210906, 20211125, 20211224}},
+		{"NYSE", 2022, []int{20220117, 20220221, 20220415, 20220530, 20220620, 20220704, 20220905, 20221124, 20221226}},
+		{"UK", 2021, []int{20210101, 20210402, 20210405, 20210503, 20210531, 20210830, 20211227, 20211228}},
+		{"UK", 2022, []int{20220103, 20220415, 20220418, 20220502, 20220530, 20220829, 20221226, 20221227}},
+		{"US-FED", 2022, []int{20220117, 20220221, 20220530, 20220620, 20220704, 20220905, 20221010, 20221111, 20221124, 20221226}},
+		{"TARGET", 2022, []int{20220415, 20220418, 20221226}},
+		{"WEEKDAYS", 2022, nil},
+	}
+	for _, tt := range tests {
+		cal := BuiltinCalendars[tt.calendar].Cal(tt.calendar, tt.year, tt.year)
+		open := make(map[int]bool)
+		for _, dt := range cal.DatesIn {
+			open[dt] = true
+		}
+		var closed []int
+		for d := time.Date(tt.year, 1, 1, 0, 0, 0, 0, time.UTC); d.Year() == tt.year; d = d.AddDate(0, 0, 1) {
+			if wd := d.Weekday(); wd != time.Saturday && wd != time.Sunday && !open[dateAsInt(d)] {
+				closed = append(closed, dateAsInt(d))
+			}
+		}
+		if fmt.Sprint(closed) != fmt.Sprint(tt.closed) {
+			t.Errorf("%s %d closed %v; want %v", tt.calendar, tt.year, closed, tt.closed)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package rpeat
 
 var Icons = map[string]string{
 	"favicon":        "iVBORw0KGgoAAAANSUhEUgAAACAAAAAgCAYAAABzenr0AAAACXBIWXMAAA+aAAAPmgEgInbFAAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAABk1JREFUWIW1l1lsVdcVhr+19znn2r6+Hq5HsBiCMSZhaKOkCakpJqKhTdQSBhGpqdSqDyVPfYuUx0bqC33pSytVtFFUKVVTCRUEpSEJGaoG7IpQEkiY7WAIGIxnfI3vcM5efbjGF4OvTZBYT+fss9b6/zXsvdeBhyR6ZMuLemTztrn05KGAH9vhk75xGqUK9R+Tdbv7i+mah0GAbP8ORJYiksTk/jib6n1nQA9vakW8Dag+jbAAaARNIyaLc92IOY64T3DujGIu6rWb1dG1EeM/sQhEfirf3fO3b0xA33k+RmXJy6C/Ann8PrkOq2p19t1T6M0JgnWtzjQmxvHMY7Jmz5W7lb2i4J9sXo+RXcAyENzQOO7qMO5GCh1Lo5kwH0FgkUQJUh3HLk5ikvFq19WPjk4AkDt60QQvrCoTcX9R5TkRdNYMqL5u6DyxE+VVQNzVEcJTvbih8TlD959pxs6vIvOvk2g6N7VuH6nBf3oJqPxS1u55oygBPbbDJ9v/V5SXyIaaO9oj0ZXhOYEBTHWcYOOjhF/0Ep7uvZfcuha1jZW3cPqotO/7espumla6//coL2kqQ+bQmRnBpcTHNFVhm+uwLQ2YpiokHuCtbkIzIeG56zMSDD/tEY20BE/+PGMGtGPzDlR2kc5p5v3Torey0yOcX4Vd3oitTxTNQu5YD1FX0S2PXVKL/9QjgPxM2va8NUVAj2ypB86jWpn98CxuIFWw8i3+U4uxC5IM96U4e+w61y6NMjaUBoHyyhgbX15BeYklc+AkOJ0Je0qC9a3O1Cdu4rvlsmZ/3+Qu0F+DVEbdA9PBA4vf3goVJXQc6OLC533TvSkkG8tJJEvJdXbPCQ6Q+7THxJ5fmSBn/wBsF/14ezlB2KtOE9l9J9BMoXv9tqXQWMmht0/Rd/nmPc5EhBdf+TYV5T65/36FeBaMgGdABPEMeBZ8i/gWPIv4BknGVUp8QdnmEQu3oiTcleFp4KapCrugms6D3TOCAzSvrqOytux2au9VUBy4FCqjwBiQwsgIMPmurR5KO4C7q+PtsgZGByc4f3zmrgYY6E1x4I0TqCq5TISiZNMRKLRtWqoLWpJDUtowX578U66YDw/4DoAbLNReAg9bX0HXRz0wS1lH+m8V/Xbh8z5Z2FpTS6b/SaCzmJ4B5qPKndtOqkpBYKB3rDj6HNJ/ZdJW9YnZ9AwQJ3LTIpUgvzkmxsMHJpBNR2jeZ/GDY5JAFsl37W3RMALADx58XPBjNu/SSno2PQ+4jJWVUuKjE/ky6OgEKNQ1VTDQm5rNvqgkG+L5B8dOPbxlG8JhjPmACvMfWbF7qt4GOANgauJTxjqRw43eYsnK2gcCB2heXY9GDk2HAUIb8BrOHWIkHNQjW3fdSeAg5M/6OyU620dtU4KFrclvDF7dEGfJyjqiCzfI7PuMzMEvyf3vMnozDVCO6lCBgI3eAXJ2YbVKYAsELg0S9ado+1ELFcnS+wYvKfNp39oK6Szhl72g+ZJGXX3c7ko88/YUAVmzvw94C8+KXdZY8KRK2NmNF0W88ItVzFtcOXfk9WX88OerSCQCwsNdMNnMAHZRDVJZCnBc1vzj5O31/G3YsWkpzp5GnZd577TcHqcApNTHW9uCTca5dG6Q85/1cb1nBBfloxEj1M4rp+XxBppX1cN4hrDzK9xwYYKSmEfwgxVIWQCwQdr2fjSNAIAe2fIasFNHJ8h+eAbNFtgjgl1ah10+DxMPUFUmxrKoKiVlAdY3uHQO19VPeOYaRG6abdDegmmsBPi7tO39yZ1ZKxDQ1w0dJ94FnnOD42T/fQ5yEdylbWrKkeoyJB7L1zQX4QZSuMHx6cAARvCfWYJdkATlJDmvTZ7dnbrLZUH0vY1xyuMHge+5sQxhRxduuPh5P5tIPMBf04ypKwe4gHrfl7W7L9+jd/fC5HzwJrAdpxp135DwVC+avs9j2Td4LQ14K+Yp1gpwFPixtO29MSPRYn60Y+srON2JUIVzGl0dFff1EG4whY5Pnxcl5mPq4ph5VdhFScWzAmRBf0Os/rezXcez/xl9vLmKQF5F2YFQN7UeOsiG+R6IeflJaOojtzDyJrnc76T9nxdn8z8ngQKR9R6xmg2o2wh8C1gJBEAcGEC5iOELkPdx4Qeydv993+P/B4Bkoa3L5HXqAAAAAElFTkSuQmCC",
+	"blackout":       "iVBORw0KGgoAAAANSUhEUgAAABEAAAARCAYAAAA7bUf6AAAACXBIWXMAAAakAAAGpAHF3nU5AAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAgdJREFUOI2dlE1rE1EUhp9z0wk0aa2mqQ10oyJIUl1IDW79BxGRGBEs2QnxYydYQQZRFHHThYSsIgpGgwuzzk8wG2MSEb82UfxIv2iTgNPO7WKYSdKCoT6budw55z1z5r7nCjvQN4+NE/Cl0SQQokAYaKH5gOYNSvJi1jf6c2RAwIym0LIIHAQgGILRCeiuQXvZzfgFXBezUdwlos3YAjb3MPxCPAWnLsDkoV6F1jeoFOFtATYtDdyWu40Hnog2oylsecF4WLj4BGaO7+yyx/f3UMjA+pJGkRKzURRtzo6B/ozPP036+b8FXJpVyF+GLes33a2jPvPM1BU05zl9CU6e8+Ky2SzlcplIJEIul6NWqxGPx52X+6ahuwrNahC/74dCkwBgLjlQzLIsLMvCtm1vPYAbr3VCATGCIQgfHt5GP1NHILAfIKaAEKMTexNwCRwAYVIhLNFd+z+RzgogLQU0aC87PtgLf75AZxWgobApAY6R+jAMA8MwUEp56wEqr5ynTcnxidafGPFHSD+DmRPDv6L5DvLznk+UmPUNhBtYfzWFjGOkYQKFq471RTLy6ON6b3buxG4B9xkxnNmZSzrH6P2Dr1B56bS9aWmULIhZfwi7pjiWRLMIRADnCN0p7qy4YT8RuSZm/bW7MSDiCM2OYTOP6LNADPc+EaljU6LjeyqPq+3+nG3kRb3ga4/7+wAAAABJRU5ErkJggg==",
+	"configerror":    "iVBORw0KGgoAAAANSUhEUgAAABEAAAARCAYAAAA7bUf6AAAACXBIWXMAAAakAAAGpAHF3nU5AAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAmdJREFUOI2dlM1PE1EUxX9vZiLOtMZQKBSoJqZdKA1xUeZ/wEDARUNgRXBL1LW41ejOuIUFhhAbPhZKIGHNlu6waOJ37Ze20hDbGaodnou2Q1t3nt3Lu/e8e8699wk68A4u/YF5AVPADaAXKAJvBbwCViJQbs0RrYc3MAM8B/oANI8HVddxbJtapdIM+y7h3ghs/ENyCIsCHglNEz7TxDc6yoWeHveBarFIKZHg+OAA6ThSwMMIPHFJGhW81LxecXV2Fn1oqFOlCzuTIRWPUyuXpYSZEdgQSfBK+CA0rf/a/Pw5gZQgWtS2nO10ms8rK0jH+aFBWDmDO0C/zzRdgj8nJ3xaXsbOZgE4zef5uLTE71IJAD0YxGeaAH0OzCmNLtAdjbovfltfx85m+bq2xkkyyZfVVU5zOdKbm/WKWuLPYEoBhjWPh67e3obVgsHJSVTDwLEs0ltbOJaFqusMTEy4krr8flTDQMCwAvhUXW8z72IgwMD4eD2h4UVgbAx9cLAtTjMMgB4F+OnYdtvlaT5Pbnf33Ewpye/tuR41UbMsBBQV4KhWqVAtFl1PstvbdQmGQTAWq0uzbXI7O64n1UIBx7IAjtQFuAzcEoqCNxwGIfCGw1ipFMFYDG8ohDcUws5kuDI9TVN6YX8fO5MBeNack/dC0wL/PScRKEu4L2s1mYrHsdNpt0ttaCFIxePN0V+4Dr/cyCQ8kPBYqKrwmSbd0Shdfr/LUS0UOE4kKCUSTYLFCDyFji0+hGlR3+IAgGoY7hY3TATIC7gbgS23SDrQ8GhOwm0BwzT+EwFJCa8deHETKq05fwF09Af32GKF8gAAAABJRU5ErkJggg==",
 	"depfailed":      "iVBORw0KGgoAAAANSUhEUgAAABEAAAARCAYAAAA7bUf6AAAACXBIWXMAAAakAAAGpAHF3nU5AAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAjZJREFUOI1tlL9O21AUxn/HvmpkJxkwBCIREEgFqQRUpOCNgTcICyhMKGVgavfStVW78gR0KgUxlNcI3ig0UpEqVYnUNiksSUxEjDvYcQjuWe6955+/+93vWHhkVUjfQVmgCDwDxoAm8E3gC3CQh9bDGnl4+AolYB8YB1DJJLph4LkuvXa7n/bbh1dLcBxrcg57Am9FKbFsG2tlhSejo9EHus0mN47D9dkZvuf5Am/y8D5qEiL4pFIpmd7awpicfHzLyNx6nZ+Hh/RaLd+H0hIcywWkfLgSpSZmy+XhBqYJ8/NwdQWtAQ1urcaPgwN8z/uj4Kl2Dy+ACcu24wjW16FYhI2NIbeRy2HZNsC4B9ta+AqMFApx7N1usN7exkL9/HsoasCCSiZJjI39hwAXjo5g8DKRJTIZdNNEYEEDLN0w4g3SafA8qFYDPiwrlqJME2BUA/56rjsczeVgbQ0qleBcqcDqKmSzQ2m9TgeBpgZc9tptus1mEJmdhZ0dWF4GpQKfpsHiIuzuwtwcAN1GA6/TAbjUBE4BbhwnKBAZrP29UqDrIRkJAK7DfB9O+zr5LkplI53MzMDdHdTrA+xTU5BMQrUa04kAnMOmwGeVSsl0qYSRy8WJDs2t1QLFttu+wGYeTqLZuYDXPrwTXRfLthkpFEhkMlFxt9Hg2nG4cZz+7Ozl4QM8muIQ0T6QBdBNM5rikESAXwIv83DSdww1CRGlfNj2YV1ggfB/InDhw6kHH5/DkPr+AdPAwpA3Hco1AAAAAElFTkSuQmCC",
 	"depretry":       "iVBORw0KGgoAAAANSUhEUgAAABEAAAARCAYAAAA7bUf6AAAACXBIWXMAAAakAAAGpAHF3nU5AAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAlhJREFUOI19lE9LG1EUxX8ziZnEKlZjYsAug1gldGO+gXu7UHFXuxBa/61rv0W3irSQXe2ifgA3JtlEN2FmjFIQYkOJ+duohU46M7cLTciY0geXx3v3nMt5973zFB6Ncxj+A68VWACeA+NADSgo8BX4OAt3vRyld2HACvABiAL4IxHU0VHcZhO7Wu3ArgW2E/D5sQB0eG+Aa2qalLa35c4wxLKsbtzqupS2tsQMBMQA14QdTwEDVgxwCxMT0spmPeTH0cpkpBCNigGuDssAmDBkQNnUtL4CjUZDTk5OpNlsegul0x1F1+cw7HsLbxRYfLqxwcjqqkfhwcEBp6enlMtlEolEd98/OYlTr/M7l3si8EN9uAVG1tb6+hQMBj1z7+jgXVhQgRl/JEJgaqoPGAqFWFpaYnBwsC8XmJ7GFw6jwIwKjKljY32gVqtFKBQiHo8zMDDAzc1NH8YXDgOEVaDuNhqeZKlUIpPJMDc3B0AymSSbzVKpVDw4p15HgZoKnNnVKu2LCwAuLy9JpVIYhoFt2/fndl10XWd/f59isQhAu1DAqdcBzlQFDgFae3tdAoCIICIA2LaN4zgAWJYFwM/d3XscHComDAl8UzQt9uzoiGAySbFYRNM0YrFYV/rV1RWWZRGPx7FyOUrz80i7XfFDHAAdlg1wC9GotNLp/7/Y42MpRCKdp7/oaZIJOwa4ZiAgpc1Nuc3nvd7J5+X7+nqvd951uB4X67Cs3Ls4BuAbH++62KnVOrCyAluz8OWfRR4UDQm8EnipwAwP/4kCpsChA59ewK9ezl9NIHZjbvSlnwAAAABJRU5ErkJggg==",
 	"depwarning":     "iVBORw0KGgoAAAANSUhEUgAAABEAAAARCAYAAAA7bUf6AAAACXBIWXMAAAakAAAGpAHF3nU5AAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAkdJREFUOI2FlEFPE1EUhb83tEynwYUtlK7JxCguXEj9A7rHhRB2RBM1McJa/BdsNUQTdsbFdMFSF9puoI2QFohRiiUstFAbGivMMDPXxcDQaU14ycss3rnnnXveuaPoWVLgCgkeApPADWAYOAS2AQuDN+omf7prVIRgjRkUi0AGgNgIMnAV5bXAPTiH/UIxr27zrlcAUuallPClrIv7Y16cdlVs2w63066IuzsnUh4UKeFLiYUowRozUsL310fFaRUjxb3baRXEX88ERGWmAZRsMsQx31H66OnYRyQ5EZJ3Oh12dnYwTZNkMnnhwd9V4rW7IE6DE0yNYx4Bo176aYQAwLIsVlZWsCwrqjx5By/1BCCDzqx29gr4qcd9PiUSici3e/npM7zGpAaMExtB9Gt9QMMwmJqairQSqtGvQywNwrgGpGQg1Qc6OjrCMAxM0yQej9Nut/uJBtIAaQ1oKu935HB/f59CocDEROBRLpejWCzSaDQiOOU1AQ41hC3cA5T9FYBarcby8jLVahXXdYP+fZ9KpcLS0hL1ej0gsLfBbQJsaSjyAFrzdVgAICKICACu6+J5HgC2bQd+Nl+dySF/npNvKD17OvYBSeao1+vouk42mw2l7+3tYds2pmmiHa8Sr90LcxLcWmY6SGxGnNbnSxL7SfwvI0Fi13gQdbrEQhDlQXF3n4vT3uiZnQ1xd591z86L0OAIUZlphEUg6CM23DXFh+ewnwhzKsf7/5IAyCZDnDAL3EcY5+J/sokiT4y36had7pp/0xlslqbsICgAAAAASUVORK5CYII=",
@@ -13,6 +15,7 @@ var Icons = map[string]string{
 	"hold":           "iVBORw0KGgoAAAANSUhEUgAAABwAAAAUCAYAAACeXl35AAAACXBIWXMAAAOuAAADrgHKWVOZAAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAVhJREFUSIntlrFqAkEQQN/FHBwhWRCuvE6Clc11gYBf4B8E09gFksLCj7CJvY3iH9jYWhiyFlqvYbfSQhAunBK20JgqAQnxUpxJ46uWmWEeuws76wA0Go3rQqHwKIS43G63Z5vNhjTIZDIAb8vl8mU8Ht9Xq9UnOp3OrTFmvT0wWut1q9W6QUr5emjZJ1LK6NR13fN9x9JsNonjeCdWLpcBaLfbO3EhBJVK5cderutenCTdg9Yax3EIggDf91FKYa3FWotSCt/3CYIAx3HQWie14zSxAgjDkGKxSBRFDAaDnVypVCKbzdLv9+n1eom9EneYNkfhUXgU/r/wVy/NaDRiPp9jrf2W63a7eJ7HbDZLR5jL5YjjmOl0CkA+n8fzvK/1YrHYqU1EShn95Xg6mUwmD8aYdEb8Howx70qpOwegXq9fhWHYEELkgdS/GKvVSg2Hw/tarfb8AT1gtXJuAHaoAAAAAElFTkSuQmCC",
 	"inspect-off":    "iVBORw0KGgoAAAANSUhEUgAAAD8AAABPCAYAAABcfR0eAAAACXBIWXMAAA7DAAAOwwHHb6hkAAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAABbBJREFUeJztnO9v2kYYx7/n2JhQx6bQNKaoFTRVpUjbm0RtpU7qi63r1L7fy/0Z+6+m7dU0VVrfTNXeEJg0KWnV9AdpEpM1OLiQBPds7L1Ig3AocJgjiTCfV/ju8tzzPe6e+0VMVldXfUwwvu//eOfOnV++lCectTMXiUiLFwMPoghFUc7LFy5QSnF0dMRUNiB+dnYWi4uLY3HqrNjb28P79++Zyka620/FR5Wp+KgyFR9VpuKjylR8VBEHFznGtm3mDcO4EUURqqqOboe1YL1ex9bW1sgV8kBRFC7iI93tp+KjylR8VGGO9pqmQZblcfrCzMzMDBc7zOJlWb4w4nkR6W4/FR9VpuKjylR8VGGe5xuNBvb398fpCzPxeBwLCwsj22EW32w2Ua1WR66QB4qicBEf6W4/FR9VIi2eOeDxhhCCeDwOSZIgSRJarRYcx4Ft22i1WmfiA7P4+fl5pNNpZsPVahU7Ozvw/eAv3RRFwZUrV6BpGkSxu3rf99FoNFCr1WCaZtffa5qGfD7P7Ec/mMUTQpgPESzLwvb2diBNlmVcv34dmqYNrEdVVaiqCl3Xsb29Dcuy2vkfP37E1tYWcrkcq+s94T7mj46O8O7du0CaqqpYWloaKPw0sixjcXER2Ww2kG6aJnZ3d0f2lbv4crkMz/Paz8lkErdu3Rrp6EnX9a5v2jAM2LYd2ibAWbxpmmg2m+3nRCKBfD4PQsjIttPpdGBV5/s+dnZ2RrLJTbzv+zAMI5CWy+UgCPzaN5vNIh6Pt58ty8LBwUFoe9w8azQaoJS2n9PpNGZnZ3mZB3AcDE+P/1E2W9zEd0ZkALh69Sov0wGSyWTgFPl0vcPATXy9Xm9/jsViSCQSvEx30TlrOI4T+uqceZ7f399HpVLpmf/p06f257m5uVDOsKKqKj58+BCoO0xjM4t3XZd5aonFYkM7MgySJAWeHccJZWcsG5vTzo3b/oUSP+6NSeciCgh/dzcW8WG/CVY6p1QgfE8bi/jOVd5Z2A8rnjngiaLYd9FCKW1394ODA7iu+8UtKw8653ZCCC5duhTKDrN3qVQKqVSqZ361WsXm5iaA46VurVbD/Px8KKf6QSkNLGnn5ubOf8xrmhbYwFQqla7AxAPDMAIHHJcvXw5ti5t4SZICJz2O4/RdFIXh8PAQpmkG6uzXGwfBNeBdu3YtsIvb3d3ldsvjOA42Nja60keZVrmKlyQJmUwmkLa5uTnS5gM4HucvXrzoEuo4Dl69enVxFjm6rgfGoed5ePPmDSqVStdhJAv1eh1ra2s9Bdq2HboBxjLP53K5runHMAysr6+jVqsxNUKz2cTr16+xsbExMHCGbYCxTMSCIOD27dsol8uo1WrtdNu28fbtW4iiCE3ToChK17l9s9mEZVlDn8/Zto2XL18ONb2O7dJCEATcvHkTlUqlq8u7rgvTNAORmweU0qFOdcd+Y5PJZJBKpWAYRqjIn0gkkM1mQSltL6L6MUz0P5PrKlmWkc/nkclkYFkWLMvC4eFhz/KxWAzJZBLJZDJwMCIIAsrlcqjA+SXO9K4uHo9D13Xouo5WqwVKKSilcF0XMzMz7fHf6zDkZEEzTAMIgqD3yiOdr41IJBK4cePGUILOg0aj0bXM7YMlCMI3y8vL66czyKS/M+Mz/3me993du3fXOhOjcj+/IAjCs0Kh8FVnYlTEA8ACIeTZ6urq1ycJpFgs/nyeHvHA87wlQshPYAvge4SQhysrK/+OfoN4QSgUCo8JIb8CiA8sDNQEQXg0MeIBoFAoPPncAAP/K4IQ8udEiQeAYrH4g+/7vwHod0v6T6vV+n7iAt7KyspTz/MeA+i1hCxRSh/eu3fPnLhv/oRSqfTA87zfAXS+9ahIKX10//79fWCCp7rl5eW/CCFPADQAgBDyt+u6354IjwSlUulBoVD44/nz511Xx/8DsC89DCsZSKMAAAAASUVORK5CYII=",
 	"inspect":        "iVBORw0KGgoAAAANSUhEUgAAABAAAAAUCAYAAACEYr13AAAACXBIWXMAAAOuAAADrgHKWVOZAAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAd5JREFUOI2tkD2o2mAUhl+DCcWC6FidKjh0dOggCC6KAVHCnbsomEVHHdXVXYeAc0HELsXBRdTJyQwODm5KEDEmkog//fz5OpRb6DXXXmmf6Qzv+3DOsYmiSFmWPeIBLpcLe7lcnur1+nc7y7KkVqu9e0QgSZIhyzIAgHmkaMU/C+zPw2AwQKFQuBtOJBIoFovWgsPhAEVR7go2m83rG/A8j8Vi8beNb/h/P+j3+8jn8zeB8/kMu/1XLJlMolQqWQuOxyOWyyUAgFKK0+kEQgicTidM0wTHcdA07fUTeJ6HoihQFAXRaBSCIGCxWGA+n2O1WiGdTmM6nYJSai14RpZlTCYTSJIEh8MBAOA4DpVKBQzDoNPpWAt6vR4CgQAEQUAkEgHD3P43Ho9jOBxaCwgh0HUd+/0e6/X6pgwAmqbB7XZbC2KxGGazGcbjMbrdLlRV/SO43W5RrVYRDAbv/8Dj8aBcLiMcDqPVamE6naLdbsPv90NVVeRyOex2O9vvQjab/UEtGI1GNJPJ0FAoRFOpFG02m9Tn81EA1Ov1ngVB+AIANlEUry6Xy7Q8+gWGYTCNRuO9YRgMx3EKIeSz/Xq9ftB1/S19AIDX6/WYpvmNEPIRwNc3F196APQBfPoJyyfgrzB0AMoAAAAASUVORK5CYII=",
+	"limitexceeded":  "iVBORw0KGgoAAAANSUhEUgAAABEAAAARCAYAAAA7bUf6AAAACXBIWXMAAAakAAAGpAHF3nU5AAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAmdJREFUOI2dlM1PE1EUxX9vZiLOtMZQKBSoJqZdKA1xUeZ/wEDARUNgRXBL1LW41ejOuIUFhhAbPhZKIGHNlu6waOJ37Ze20hDbGaodnou2Q1t3nt3Lu/e8e8699wk68A4u/YF5AVPADaAXKAJvBbwCViJQbs0RrYc3MAM8B/oANI8HVddxbJtapdIM+y7h3ghs/ENyCIsCHglNEz7TxDc6yoWeHveBarFIKZHg+OAA6ThSwMMIPHFJGhW81LxecXV2Fn1oqFOlCzuTIRWPUyuXpYSZEdgQSfBK+CA0rf/a/Pw5gZQgWtS2nO10ms8rK0jH+aFBWDmDO0C/zzRdgj8nJ3xaXsbOZgE4zef5uLTE71IJAD0YxGeaAH0OzCmNLtAdjbovfltfx85m+bq2xkkyyZfVVU5zOdKbm/WKWuLPYEoBhjWPh67e3obVgsHJSVTDwLEs0ltbOJaFqusMTEy4krr8flTDQMCwAvhUXW8z72IgwMD4eD2h4UVgbAx9cLAtTjMMgB4F+OnYdtvlaT5Pbnf33Ewpye/tuR41UbMsBBQV4KhWqVAtFl1PstvbdQmGQTAWq0uzbXI7O64n1UIBx7IAjtQFuAzcEoqCNxwGIfCGw1ipFMFYDG8ohDcUws5kuDI9TVN6YX8fO5MBeNack/dC0wL/PScRKEu4L2s1mYrHsdNpt0ttaCFIxePN0V+4Dr/cyCQ8kPBYqKrwmSbd0Shdfr/LUS0UOE4kKCUSTYLFCDyFji0+hGlR3+IAgGoY7hY3TATIC7gbgS23SDrQ8GhOwm0BwzT+EwFJCa8deHETKq05fwF09Af32GKF8gAAAABJRU5ErkJggg==",
 	"logo-tm":        "iVBORw0KGgoAAAANSUhEUgAAAP4AAAA/CAYAAADAInVAAAAACXBIWXMAAA98AAAPfAHoG9owAAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAGqhJREFUeJztnXl8VNW9wL/n3juTjSQEwhI2QWQHlyKCgILSulYkWLSLth/bJ93ea/v62te+rr7WtvbZ2tp+Wvtsn/W12lqpQOli+4pFhcQiIlIVZQfDFsKSBJKQzNx73h+/yWRmMjP33plJQnC+n08+Se6ce++Zmfs753d+24F+iK6tflCvWzagr/uRJ09/xerrDvhFb7jlBrTzEQJhG/jnvu5Pnjz9EdXXHfCDXrfQoqBiK5qpgEZzrZq/6q993a88efobRl93wBfBgXdFhB5AoXhYr1sysE/7lCdPP6TfCL7esLgU1FcTDo8iqH7YJx3Kk6cf028EH2V+ERgGoE+dAa07X7ldb6he2mf9ypOnH9Kja3z991smENZvQzkTUOoCNJWgKlC6QBqodtDHgHpgN0pvh8AmNXfFwbjrPL9kLI56HSgE6Hj6dYzh5VjTRnQ2OUbAmqEuW3GkJ99PnjxZcg1Q7tKmAXimpzuSU6u+Xn9jBUbgRpS6Cc0ibGewDC0KohO0jv877gIKCKNrqveh1NOgV9Nevhan8V4iQm/XncBpOI1zrAWjqhxjUAlAJWH7IWBxLt9Pnjw55gvAApc2q8le8AcClUA7Mql2JDbIyYyva6qvBu4ClhAR0JyhOQ0MQAGOpv2pV0XVB1RZIQXXTgOzc8Wi71TzVj+S0/vnyZM7fgW8x6XNg8DHMrh2BeLeXgKcAI4ARcB5iBbxfeD/OhtnNePr2up3ovkycJl7Y9ChMLSH0WEHAGUZqMIgBNKYGhTRQJ3wjvqo0APo5jOEXzmIdfHozsYP6Jql69S8lfszekN58vQsh3PUJpG5wM+QQWM+0Jbw+lTg28D7gOVAe0aCr2tvnoE2vodmUco2TW049c04J1pwTraiT7eD7SRvbJkYpQWoimKMIWUYwwagigvir9dhY2/r/pmEt9djjBiIMbQUoAz0L7W+e6FSd6e4WZ48fcZxD20afV5zJvAIssx9A3g/XVp3C7AN2BJ5/evAE8ASX6q+XrfQIljxOeCrQKDb66fasfc2YO8/gW5p99n/GBQYg0oILJyECpgAhDa/ib2zPnnzkiAF108Hy4wc0J9Sc1c/kHkH8uTpET4D3OfSZjnwU4/XU4hQf4ouu8Ax4O9ADTACGQh+BXw08vofgCc8z/j6uaVVmPoJRJWIwzneQnjbIZxDjd3sdRmhwRhWFhV6feoM9u6jqZu3dBDaUkdg1tjIAXWv3nDLWjX/yddy0Js8eXLFGfcm+JkxZyNq/TMJx9cB34387QB30CX43wLu9eTH17VLLsPUL5Ig9Lq1nVDtbjrWbsM5mCOhB1TQwppcFf0/9HIdOOkvbu9pwDkc1ZIKUc7Det3CfpeLkOecxotQexkcOpkBbEpy/EJgGfBJ4N3EaxkvAjNcBUPXVF+N5ndAXDacvaeB0Et1ELZ99NMb5rQqCMps7xxtlkHFtaMQ2riP4PXTUQUWwGUUVHwGuDfnHfSOhVhVxyD+WxNoQtZxO4DmHN/PBKYAlyDunMGR3y3I+rIBeB3YCIRyfO9kTAKuAC4GxiEBWAbiXmoEDiGq6ouRPvWGXWYMcFGkb5VAaeTHAk4BJ5HvZU+kX7vxN6WZQCqh8PL+/HwGmuSeuYnAdcB7gUeRWb4TRcRxnvqqtUsWodUfiHXRhR1CG/di153w0T/vqJICCm6cAYb4/jvWbsM53uL5fHPMIAJzx3f+ewZlXKLmPvlGht15HBGedHyB+FG3EBlt34M89OnSh/cDdyKqWSffB6a53PPLyDqukymIK+cWItGNLpwG1gO/Qd5jFgaZbpQirt07gek+zjsI/Br4AVCXw/6YwFXA7cA7cf8+E2lEvp9fAGtwF8wvIWv5OuRzPh3z2giI5pqk4h9A7LrWBMoiP1XABMRVBzAL+HHkdyfHEEH/LhIz8DvEoPcxIAwsBO5OKfi69pZL0M4zkRvKsTMhOp7ZgW5sdel7CgyFKgyAqeTjsx10ezg2/JbA7HGY4yoBCdYJ1ez2fZvA/AswR1XIP0pt5IA5T926IhPVZAsyW6XjeuDPkb/fh2gYozxeX0faHoo5thq42eW8xcDvkQfhvyL3zTQmow74PGIAygYD+DBwDzAoi+u0AQ8gFugMH7QoS4H7Ea0rF2xDBOjZNG2+hPS9p6iiS/BBJp0vAX+J/B8r+CDP71OI9vIeYBXwP0lVfb3upkq0s4ZYoW9pp+OZ7ehT3icHVRQQV9uwMoxBJaiSIKiE51NrdNMZnBMt6KY2zLGDo8fDrxzsflEPhDfvxxhaigpaoPVsRtn/gsykfjmEu+CDqIkPIbOcH7YRL/QQ/6WmogOYB/wWGO7znomMBh5DAj/uRJYFfhkaucbbs+wLSNDJ55EBdSmicmfSn4eBG3PQn1imAn8DvkjfLiFj+SAizO8CXkbce7Fr45cRjbASkYHjwOPdjHta320QNB8lZtbSHWGZ6T0KvTGsjOCCiRQsvojArLGYYwahBhR0F3oApVADizDPr8S6ZHS0jb3nGLrZj50j5j20hQhveTP2TX1D1y6+IINLeQmmUMAv8S/0AGuTHPMi+O9CHsBshT6WZcDTuMeSJzIG2EBuhD6Wi5B1/xSf540Hasm90HdiIDNqJtF1PcErwG3IWv4LiNA3JbSZhExMIcTCnyRy7/mtHwV1bfR/RxNavysuYi4VqryIwMzzOoNp4rDDDo3HWmlt7qC9LYwVMLACBqUVRZQNLkTFDgq2Q/i1xInQH/be45hjBmNUlQMUg/lTrblaKV+GGi+q5qfJ/KFPJviJX1oy/inD+7kxG5k9rsWb8W8w8h4m9FB/KhEVdi5wwEP7csRPPd6tYQ54AFn7v94L93JjM/LdLUc+LxuxExQAI4HtyHKgtvOEOMHXz948Gh1nAST8jwM4DafS31aBNaUKa/pIMcpFaD3VwZ5XG6jbcYKGg6fQKVxyVtDkxjsvZOCQYrnn9np0a7e8At+EXthH8IbpEg+gWUhN9Ydh1U98XMJLJzIV+hDJ14q9YW1Px1XAVxADYjoUYozrKaHvZDQSmfYO3K3r3wYm+7y+gwy2nUY0r1jIkuQDPu/XU7QA34v8FCCDZhsSt9+N+Bk/YHwdTXS6do6eIrzdRfM0DQKXj8cc1VUIp/VUOy8/W8fuVxpwUoXpxlA1tjwq9DpkY7+Rm+xa3dZBeOsBApdGbDuKb+sNy/6k5q94M/2ZUbIffVKzEXEf5fqeW4BXkaysIGIMuhJv1v5OPocsX3akaXMXIoxuOIjxczWwFVljliJLhGuQJcZQl2ssAj6ExKOnYireNaGXItdaB+xCrN0g9oWxiPH033E3Ui5BVP+zLTy8HfGSpCQq+Pq5m6eiuT36iqMJbdqXfow1DYILJsap9ts3H2Hz0/sIdXgzoiuleNtVXUZX+7VD6I5wmjP8Ye8+ijmqAmN4GUAZKvyw1rzDo8qfi9m3EXk4EmeTZGo+ZCb4YWQNdx+wL8nrBuLG+TZwqYfrBZBZ//YUr5fizXK9E/E4JAsyeRlxj30RWTN/NEmbWP4TGYxSGZo+gcza6dDIoPYdkj/ZbYjq/jpiON1IevdfGWKDiI0QfRN4EvGWNBGfMDMLcbmm4zFk3d6JgSxhKhG7W040wq4Z3zQ+Q8wHZ+9uSL+uV4rA5eOjQu/Ymprf72TPqw2+OjD+wiFds31biPCu1KG5GaEhtGlvbCz/ImqXfAA8pe9m4gJ8FfhvRLB3xlwjgMxyi5CZ7k8pzvf7xR4GbkLWealwEGPgHOCHuAsZyEz8aeJ9yp3chfssvQPRNJInWHTRhBjKjiPr0FSMQAJSfp7ktQDuee4g2WtusfKd7EbU5ntc2l1AvOD/IvKTjA/hLvirkUGnR7EA9HPLhkC4K0/Y0a7GNWvK8Kh679gO61Zs58Auf0E9pmVw8YIx0f/DrxyEcIzWZJkoQ36jFFgGylCSf2+ZqKAJlgGmIev4gImyTEnzNU1UwICAhbKMBI+Cul8/t/Qv6sqVmaRApiKMhEj+hOSqXwh5mHYjs3MuOIoYvvZ5bG8jQjYIsQSnI4iossn6utzl3DAy07sJfSxfBW4A3pamzUdILvghZOYtR9b40yO/p0WOn4doCm5CnMhGD2382AXOGmTGt8K3orui8+w3j6PPpJ54VHkR1oyR0f83/nmPb6EHmDRzOCVlXem3gYtHY108WgQ2mesvd1Rg8mOgOkfXc5CRfE2OrucFjcyA+zI496OIUdItiu16ugv+DMQ9lI4nkIARPziI2v9UmjazEK0plY2mCRHWRIEdgNg4/A70XmIa+qXgix9fsyz2oL3nWNqTAjH+9r2vHWPHFj8DuxAstLhw/uiEg5FZPPdC34bEqe8BvQVYj9aFesNitwfYK9+hd4WeyP2ezvDck3hL/ZyV5Ni1SY4l4sdzEsvfiA9xTUQhyyS/nEY0rZ6gXyaCWfrv15dhM6/zgD4TwmlI/dkbgwdgDJcYj1C7zaa/7s3oxtNmj6SgyJJ4/HYx5nWcsdFoQmfCaCDU7qBth1DIwbEdAgUmI8dHQnFRIZT+FlrVo3ULSp/GMJvQdjOOKf877c0cLm7OMFzXKy30TRTXd7I8/zHEHZWOkciyIFadm+1yTkfkvGUu7VKxn/S5CpeT3rrvhSGI1jIW8XqUAyVAMVLCqvPvXAZInVVYOIVXEjNqOYeb42LnEzEndNl03th8mLbTmXmfXqmtY8uz/itkLXr3FEZdMAjQAbRuUfNW/TijDuSOp5EZtDc5Djyf5TW2If2ucGk3mnjBd0syCSL+/Z7CLYEpGeMQTeUapEzcyPTNz30MEtQ5pz5NpqhpRA16Wmve2JS5vz0cysz1mXDPd2fcgdyRrQBmes9stRgHKdXkRqL/f0zSVr3HWI/tDMTu8jSi5j+I2HTe8kIP8uHEpU46aTLvjCEDouWtGg6cotVHwk6uOLS3kfa2Tj+/ulhvWDwi7Qk9z64+uGeuvBFeLLLFMX8HSJ9m3BsMxX1dfRmStvxb4Gr62R6RvYGBQ1fyitbo5sQCnTGNK7sCdQ7v8xJSnnu0o6nfH723QpnJDFC9STpjVE+RqwIeXrKgYoWsKEf3zQZF+oi6jyH15vr6uTirMVBdqpzuCKctcaVKu+pxHD/cF8+7cKI+xsuifGdvnQu4rcu94mXD0VgVMHchldmR6v1/HvgR/dTS3ptYxH6I7em/V1XUVVi39VRPhrGnJ26J4Wg/MejnCrla3rhF30G8dtGG2Ab6es/FSiTjLJYbgG/4vM4xJH/gDSS2vTHhZxzi/TjnsIiNb3aLXre6vu+wx1j8nsAOx3RUGQWpW56zzEW+u2xm4BK85brHJntoxL6QzkC2Gbg1i355IdHGYSHhtV4GpMNIUFJn0lCOSsT2Lyxi37hb4ExMpp1h9d2gr4zYfjpni/rZm5QhcfB/y+Ial+OuErfTPQ9+L+kFfwSZVc3JhmqkwKQbP0LyD7yqq+fspGIQ64MOpk9u0me6ZKx4QLCn+uRKcWnMvbXxVt0h97NZnv8hD2220j1p6CWXc6rw7nLLFe/00OYxpCCpnzWq19qJ/Q4DWecAUs8+3ayvT3cZgQcOLU7ZrqepGFLS9Y/SC3Rt9TJJNHpLcR3eHvhkTEHq2bmRrFBIjYfzlvjrThwfAVYiufVVLm07memhzdcy6Eumn+9Zj4WoZbLWMxRqQEHKdFx9osvAO3xMOa89n1kxzKxQMHRMXGmva9BcgxlG11TvAbUWpdfSHl6nrvp9+qSD/s8jiMq+08c5BUiGmxeV7Y9Jjv0FmTXTnf8pZB3tt0puMWKZPw9R3zWiYfwRKam1meSZj25Gyjb8x1vMRmob9ja9MqMaKLbFHRiY+r5OfVPU3Vc1rpxgYe97TYaMLI3L6EvgfNDL0TxB0KrXNdUv65rq7+ua6pv1+htz5QI7mxiMzMDz3BpGGICU5XaLtwcRlPVJjjeRfECI5TykPr7fwJn7iC+FrZDZ/CvAC0hF4s8lOc8tDDRAkr0e0zAB0TrcCnv0BL0SGWng6Lj0yWSFMjvRHXY0pNe0DC64yIs3KLdMmhmTNxFO+30bSKXWTwKrMYLH9PPVPeHz7+uosCHAc8D/IlsnJaMY2TzxdbyVywIpxZzqA/6hh/M/hGTpedEsDGR/ALfKtcOQQieJuNl5LLwnDb0PifrriYhQL4bo20htVJxJjp43C8t5FtuMbsUTKVGVkvCuowSlci3TLx/JjpeOZBx375eyQUWMmyqbbaA17U+9AqYhdfuHlGIMK0UVpBzYDzJnld8ddbw8tGdDsIiBCPb7Eav7a3TV3BuFVN7xE3W3k/Rpu+siP1e5XGd5pM3diKqeGHFYgFQP+gKy7Zcb60mubWxFBvl0PIgI3hN0H9AqkfX8Jzz2IxY/37+XcNfpiHbzKFJ3IIhoQdcjbtzh+CtwkhRLzVlTr2uqtxLZOEKVFmIMKsE5kbwGgXOoCaexFWNgMUUDglx85RhefHpftv1wR8Hs68/HMMWNaO8/jm4RA63dfAZ751FQoMqKMIaWYY4bjDEoxgioWeOztDZ4E/y+c28kZ1zkJ1NsZJMGN+v3p5AH1M3lNQGxqHcgQTeHEMEbghT18Ooyc0iu5oNsE/V+l/MHIFmDD0T6cRCpHTgWsXFl6p/2s4TwWlfuQkQDSsZYciD4nW/28diD5rg0hVm0JrylLur9nzpnBFXne4n8zI5ps0cyYlzkPmGb8NYkhkUNuqkNe2d999BjpTMplOFFqP188f2BzyIbZLjxD2Sm9koQEfRrkdnrUvz5ye8hdSbkH/FuvBuK7Gn4bmTTjWlkF4noZ+B/meyrKOdkO7DIG1aPE5PmaY6rlLp1KXDqm6P71SulWLh0EhXDSlK2z5Yxkwczc1HX+w1tqUO3pf78jIHFGJVxSWSHKBi6LlX7NBS6N+mTII/7yO1Gl518FYmA88r9ZF5txw8PI8uFVLQD/0rfROH5EfxW4jdIzYTcCb6at3I/OqZ0lGViTUpffCS0pS66HAgWWlx7+3QqR+Q+Y/P86UNYUD0xutOOvf849u70lXzN6YmBZfohdelDmZQl9mK97AtvwZ+QNWmutiw+hZTSzsTX/XHEgt9T3I9U9XUT6j8gtfBzRQgpk+2G3w1Cv0F2A9TYLM6NEjut3x/7gjV5uOxsmwrbIbR+Z9TnX1Bkcd37ZzDp0qqc2B1Ny2DWO8Zxxc0To+t650gzoY3pS30ZQ0oxR8YsPbRqwXQynZW8lF7qfdeGPGxrkUqyPyfzohwOsu6dQebJKA7iObmD+M0as+U4EvP/b3jfsOI7yI69mWz8GcsWJKf/u24N8f/9ryd9GXE3cqnqg5q/agPorlrvlol1SXqXom4L0fG3N6LbZpuWwZzrzue6O2ZkPvsrGD1xEIuXX8zU2SOig4h9oJGO9TvTpg2jFNbMMfEDj9LfV3PWZGoMOVsFvzPYogExxI1FKtTW4u4yspEqtPcgdefei9S5y5ZHkXj5H5BdjYJGZOOPCcCKDM5/CLHM/xz/AUTPIFuUX4qsx72QSXboNxEbQyYFVbxGM6Ylbm7WNdXTkdEu6qIIbdiFfcClpJxlErhMdsWNpX5/EztfPsqBXSdiquYkp7isgPMmD2biJcOiG2xIp6TGf/i1w2lrAQJYF47Cmhr3uRymwJqiLl2RadWQi3B315wkt0kp70OEKB13pGkzALFST0aWIUWIABxFrMFb6fkageVI1Ns7keAit3Dqw4hBcQ2SNZerYg8DkY02ZiKfSQVdNQiakEGmDjEa1kb+jqUE97342iA+CM4HAcTYeR1iZByPfH/lyADegnxndUgE43okMSt1tRyPdFPKde3Sb6L1f0QPdNi0//U1T1tkm2MGSV384nh7h3Y0JxtaOVnfwumm9qjfP1hgUlpRSMWwEsoHd3czO42thF/Yl9K1GItRVU7wygnxuQZa36bmr37C9eSzi2wF/2xkJCL8QxGfuYkMRkeQQTOXG5vk8UD32ay97GsEm26isxZf0CR4xQQ61r7huqed/eYJ7IONWBcMxZw4DFUiA4AyFIOGlTDIo+XfOdmC/foR7LqTrrM8gBpYRPDy8QkJRvo3/VDoz1UO4rKJY57epZvgq6seOaM3LH4XynyByC4hqqyIwIKJdDy7HdwKcNgO4e1HCO+oxxhaKhtWDiuVsl2pMv8cjdPYinOkCaeuEeekd9uMKi0kuGBiYkrxTsx2t22ezlb6S7Rgnn5M0gdIzV+zXW9YegdKRxMVjMElBK+aROjZnWm314qiNU59c1e5bstEDShAFQdlLzukxp9uC4lnIJ3RLgVqYDHBBRPjSoIBJ1DGYjXnqVwVpOxtvMQFnGtBQ3l6mZRROmr+yjVo9RFifI5GRQnBd0zBqMggWCdsoxtbcQ41ypLgzRM4R5rRTW0ZCb0xYiAFb5+cKPStaG5Wc5/0G5N/NuElaCgv+HmyIm2oopq/8megP06MH1WVFBB8+2TMCcP6Ji/NNAhcMobgFROiNf4jnEZxg7gl+zVvxTDhPL2Ma4yymrf6QcTS3FWdwzQIzBxD8KrJqDT5+7nGqCqn4LppmJMSBh3NEZRepOauSlYxpr/hJfGhrze1yNPP8ZScoOatehxtXEGCn9MYWkrBtVMJzD4/ruZ+rjEqBxBcOEnW84n3UWzGdi5Tc1e/0GMd6F28BIS81cqM5ckxvpR1vbF6MGH9I1C3dX9R4xxuIry7AedwU0br9jiCJubIgZgThsWn13bhAPcx0PqKmrai74r8554/IFlj6fg1EnGXJ09GZLRK1zVLbgH1XVLFDXfY2EeaxKp/7DS6+Yy7P94yMMqLIgU1yjCGlYGRsnubQH1czVu5KZP+n+V8HQmlTcdW/G8ekSdPlIzNc7p2WRE6/AkkiSK96ulodEs7uq0D3WF3aQOGQhUGUEVBVHHAva4/7AB1D3MvfEypu3un7E+ePOcgWdvl9Ys3FXPG/CBKLUeyvHKP4hkc/SCHAk+qW1f03RY+efKcI+TUIadrls4CfQuwGG/bM6UiDLwIrMY0Vqo5T/opH50nTx4XeswTr59bWoXFHLSeiWICMB6th4CqQGqdSa68wVG0rgd2ofU2DLUFx65R89ec6qm+5cnzVuf/AaU8zZmxgCvBAAAAAElFTkSuQmCC",
 	"manual":         "iVBORw0KGgoAAAANSUhEUgAAABEAAAARCAYAAAA7bUf6AAAACXBIWXMAAAakAAAGpAHF3nU5AAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAahJREFUOI2Fkz1OG2EURc9Ywi5imniMYQOwBHrWEFlZAU1CjDtIFWUVIKXJChApIdBiu6DDNlUasHGLLRE7yZwUeBTPj8P7im80eu/M1Z37IF2yinxALpABMpvfF8geUs7MpABvkRE+n6pVt9yyalX+nQekvgzwEYlKlmza9NZbF6tv3333LVoUiZDDPAXRuut27Pi/atu2Zi0G1WNAGXkoWXoREFfLVqxohKwyN9GmzUTj7M/MKIqWgho2Yo/eM3fdvv1E09OvJzt3HSezSS6sazeGnIMMq1YzTY8/H+UT7nzd8XpwnQurWBG5LwCvK1QyP6tQKABw+eOS7S/bnPROCIIg0RMSAlRABnlKxtOxK59X3P2262gycjKdZHrmSu5AviP27CWN/T1zMB44no5zjb3xJvbkrACcAhxznJAaBAEb5Q3KxfyUH3EUP57GORmWLNm2nfvVdF15lcrJc+DqSFSzZsvWi4A11+LEvklH/xCJihZt2LBrNzHcteuee4u7c7BsCevIMN7X0NBNNw0NF7d4mFWQBZWRd8g5co9M5/fZ/P2r9MhfGdp5YhypbM4AAAAASUVORK5CYII=",
 	"manualsuccess":  "iVBORw0KGgoAAAANSUhEUgAAABEAAAARCAYAAAA7bUf6AAAACXBIWXMAAAakAAAGpAHF3nU5AAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAntJREFUOI2FlD1MU1EUx3/30YdCHqVAAy2FYsVGKUoiBJ3VCJPBGAUSoyAkLiqyKAkm8gYTZTDGhcEBjSYGERK71sW4aAKTETQK1FpRIYB8tHxV3nUoLS0QOdP9OOd3zsn53yvYbJ1ksMQloAYoAazAFPAJwSskj9EJJYaIJIBOPfAQyAUoznbhMOczPv+T0Rl/zGsCaEGndytEpx24o6ma6KzuoKLAgyFW49eKVBkMDtPm0wlHFiWCW3RwdwMSreD5AatbdJ99ACKypcu4GSqNfa18mR6RQD06vQIdDRjRVC3P19zzf8C6ScNEVXcd4cjiJGnsU4AmIK+zuiMOiKxF+Dw5wtzy/LYQofzlXlUHQC5LNJiIToGKghIM1qsQgq73z5hZnqWx/ByeXDf55rwkUKXzYGxZYwI8xdkujIQ2FKGQoqQwNv2d26/vk7FL40L5GSodZbiyCkk1pWKwistShH824FGA7MJMR1IWBYGiKPH9wkqIrndPae6/iW/kLQsrYQAKLQUAOSZgOjg3bt/UNUgZ36WradSVneKo8zDunCLS1DQAgrM/AKZMwPDojN+uyNS4LiQGEonTkk9TRS2ltv04zLZNaVT8swGAYRMCL5ITg8Ehyp3uuNPlI+exZVix7M7cdkIDgY8xmjemk6+aqtl8TT2g7KwTDJWT3bUJOok+puuhSEg29rUiDdOOgIsvW6LShyu0sZACwBuGOMbq9OLM8RcfvKLUeoiCTDuStXisQioDgWEa+q8yEZ6UQDs6j6IdJZpOLdFXbAPYm7UHh9nO+Pwvxv58i3n9Bq6h0xc7SIZEQRqCBiSnAQ8b/8kQAi/pPOEG4cSQfzRV0sTD3idkAAAAAElFTkSuQmCC",
@@ -23,6 +26,7 @@ var Icons = map[string]string{
 	"more":           "iVBORw0KGgoAAAANSUhEUgAAABwAAAAUCAYAAACeXl35AAAACXBIWXMAAAOuAAADrgHKWVOZAAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAihJREFUSIm9lj9LW1EYxn83dCjRYBQxxiUBr0k8GDCbThLdOgV0jGsL+R5+BJ1C/4A6uTj0A5jFLRmiQrg3kLskJqB3iJqC0KdD6y1pbaHF5NnOeeD8zsv7nD8ASDKSPklyJT3q5fT4Y82PkpYBLEmbwGfgNaPVF+CNJckB7BHDnuRYkr4C1piACo0RBmCFxggD4NWvE61WC8dxmJiYIJvNEolEhvxer8fV1RUAxhjm5uaG/H6/T71e5/7+nqWlJZLJ5JBvSdLT4OTkhLOzM+LxOA8PDwwGA0qlEouLiwCcn59zeHhILBbDsiyur68pFousr68D4LouBwcHhMNhwuEwnU6HjY0NdnZ2fq/w8vKSSqVCoVAgGo0CUK1WKZfL7O3t4fs+R0dHbG1tkUgkAPA8j+PjY9LpNFNTU5TLZYwx5HI5AHzf5/T0FGMMxhgAgh5eXFxg23YAA8jlcvT7fdrtNo1Gg9nZ2QAGkEgkmJmZodFo0Ol0uLu7Y3V1NfCnp6exbZt6vR7MDYXGsv4c2L95/6IAuLKyguM4+L4fmNVqlUgkwsLCAul0mpubGzzPC3zP87i9vSWTyRCPx5mcnKRWqwW+7/u4rks2m/258f8Jzfz8PJLodrvs7u6ytrYGQLPZZH9/PwhNu90mn8+zvb39PBBe9likUqmhnj8LHLXGftOEgHFWqBDQHCPQDQFv+f44jloD4B0AkpYlfZDkjOCL4Uh6LykD8A0jUq3uq72OnQAAAABJRU5ErkJggg==",
 	"onhold":         "iVBORw0KGgoAAAANSUhEUgAAABEAAAARCAYAAAA7bUf6AAAACXBIWXMAAAakAAAGpAHF3nU5AAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAgdJREFUOI2dlE1rE1EUhp9z0wk0aa2mqQ10oyJIUl1IDW79BxGRGBEs2QnxYydYQQZRFHHThYSsIgpGgwuzzk8wG2MSEb82UfxIv2iTgNPO7WKYSdKCoT6budw55z1z5r7nCjvQN4+NE/Cl0SQQokAYaKH5gOYNSvJi1jf6c2RAwIym0LIIHAQgGILRCeiuQXvZzfgFXBezUdwlos3YAjb3MPxCPAWnLsDkoV6F1jeoFOFtATYtDdyWu40Hnog2oylsecF4WLj4BGaO7+yyx/f3UMjA+pJGkRKzURRtzo6B/ozPP036+b8FXJpVyF+GLes33a2jPvPM1BU05zl9CU6e8+Ky2SzlcplIJEIul6NWqxGPx52X+6ahuwrNahC/74dCkwBgLjlQzLIsLMvCtm1vPYAbr3VCATGCIQgfHt5GP1NHILAfIKaAEKMTexNwCRwAYVIhLNFd+z+RzgogLQU0aC87PtgLf75AZxWgobApAY6R+jAMA8MwUEp56wEqr5ynTcnxidafGPFHSD+DmRPDv6L5DvLznk+UmPUNhBtYfzWFjGOkYQKFq471RTLy6ON6b3buxG4B9xkxnNmZSzrH6P2Dr1B56bS9aWmULIhZfwi7pjiWRLMIRADnCN0p7qy4YT8RuSZm/bW7MSDiCM2OYTOP6LNADPc+EaljU6LjeyqPq+3+nG3kRb3ga4/7+wAAAABJRU5ErkJggg==",
 	"poweredbyrpeat": "iVBORw0KGgoAAAANSUhEUgAAAQoAAAAfCAYAAAAfp4NVAAAACXBIWXMAAA8ZAAAPGQGoqNs7AAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAGUZJREFUeJztnXmYVMXV/z/ndvcMAwzLyI64gERBENG4IUZxi0tMBASTX+KSRUl+STBBCQaY6dMzEZ2YEMKbRTRxiW82MIoRRaPGFdGocQejIgjIJowwCrN033veP+r2zO2enmFxjc73eeahb9WpqlP31j11trpAO9rxMYc9Om6MLTn7ax81H59meB81A+1oR1swUw/8ORi/tscm9P+o+fm0ol1QtOPjjaXPfQ3xDiGwzlj692bIR83SpxHtN70dHzps6dn7Yd7nsWAMIgdg9MMoQdiGsBmTpzF7jKL4PTQ2Pu2vf6ePv2KTV3TcYDC5SEbf+ruPeg6fJKjqfp7n9Q2CwIDVqroun6ZdULTjQ4MtGXsiMAU4naw2G5hZfVrwDWICJQlEssvSMhjxhrtfwrbVkRg10GJ779WAZ8Pk2IUrPqJpfCKgqh2BHwDjgdeBN3DPZBDQF/gDcI2qZqBdULTjQ4A9/sXe+LE5wJcBgq07CFZuwd+4DdtWBxYhjnl43Urw9i4jfmAf/NffIv3UKleXiFF85vCMFMWXMHrhGJGclu3YRajqAcB84H+B36hqfbS+urq6tK6u7kfAmEQiMX7GjBkb2wXFxwxVVVUHBkFwSPbazB5S1U07a6eqZ4tIImzzlqo++AGyucuwpeMOw7c7EfoEb+8g88wagk21O22XOHxfYvvvRcOi57H6TFO517cbRccPBrPvy+iFv/ogef8kYtasWXs1NjYu8TzvooqKikdU9XxgaLZeRFYUFxfPv/zyy7ep6jhgJjAq/pFx3I6CCILgZDNregE8zxsF7FRQAL81sz7h77uBBz8A9nYL9sjZJ+AHi0A6ZZatI/PiOgh2rgRIp2Jig3qSWbYuR0gABOu34q/cbLH99vqpLT17kRyzcNUHxP5/DVT1MmBweNmgqpNbo21sbKwUkesqKioeCYvGAT1FZI6ZFZvZJfX19ZOBQ1T1VlUdLSI/bBcUHzOY2ba8op1vv810WUHxzvvH0Z7Blpw9CONWoFP6yZX4r2/OJRDwepbi9SxFupYgMQ9r9LHaHXg9SrF0hszLGwv2nf73G+L16ZqQDvEbzDix3QThBODM8Pd6oDVBIcD44uLiA/PKVyeTyQUAqtoPqFLV4tAk+Y2Z3dYeHv34IUcweJ63q4IiKmC2v3/s7D5s/oQYsACR7pnn32whJLx+3UicNoyiEw/CH9iLTb7H6m0ZauIJGNIPr19XMi+uh4xfeIB0QPrJVXFETuCxsy/44Gf0scfWVn7n4Morr+wG1F9++eX5m9EIVa1W1f8FviMi38r6LVR1BTCgXaP4mEFE0mYWvc60QR5FupXfHz76+5NARgbrt5F5eX1zuQjxw/clfkBP1q14m+cWvsamNbly8OSvDKVvn074K9q2toJ1W/HX1Fisf/f/sccm3CujFryZT1NVVTXE9/2mJC1VvU9VPWCUiIwAioC1ZnZ/hw4d/Pr6+iMizZ9X1U2VlZVHBUFwEtAbqAeWJxKJxTNmzCis7gDz58+PLV++/DQzG4OLIGwDXo/H4wtmzpz5RlvzqqysPMrMRpnZPkBHnHb4eiwWu7e8vPzVyNz2931/BPAu0D3SRVxVTxaRGEAymbwnW9HQ0LAd6IzTLKJa2Nsi8pSZjQK2mtn9kXtWAtSLqs4iDFWJyCOlpaX/rK2t/TpwFrBPSL8GuAu4XlXfLTTB6urq0vr6+ovN7As4e6kj8DbwFPBnVb09ylwqlZpqZnuFlwbMUNUgwuAUoBdgffv2rZg0aVI6UncZ0CPbNr8+lUp93sy+DhwJdAM2AMuBeap6b5SPcOHMikzlMVW9I5VKnW1mXwT6AztEZGkymayOtIuLyHlmdi4wDCgG1gJPeZ43t6Ki4oVC9ylsOxo4DxiOe3BvAg8kEomb0un0SGBxljaRSPSbMWPG+sI95fS5BBgVXl4H/H/g/+Ge4wG4Z/wGzn9xo6ruiLSdDnTJXvft27c8ej8jdNlnAmCdOnX6ydSpU3O0F3vq4gT1m9Zg9GpY/KLYO80O9fgR+xEb2IPH71rBK8+0fM96DejC6RcMJ/3E6/grt7Q94ZiHlBZTfMrBAR4LZfTC8QX4/QUuBAjumQ8F/gKMiLIM7B3O65lI+SW40OHnCoxeJyJXJJPJWeS+cFRWVh4RBMEfgIMKtMvgns3kbNgxwuswXEhyZCszNuBmYJKq1qdSqQvM7MZWaLN4Kew3Os6jnudNraioWBpeLwTqVPUrs2fPLqmtrf0LMCIWi51eXl6+XFXPAc6J4xZUVwAzG1pbW/tLXCw1iqHA54FLVfUsVX0+WllZWXlcXV3dApzUjaI7MBCYqKoPhP9uDscaBEyK0N4AvAJw1VVXda2vr68G4gAbN268C3gEYN68eYn169f/BPdiAizJLupwojea2cQCfAwBxqnqAuBrqtoY3qhAVS8BOoS081T122Z2erQDM2ta2aq6N3CHmR2aN04P4NAgCL6pqtNV9apoZcj7b4Fv5rUbDpyWTqdnAjfx3tEdWAp8Nq/8EJzg+JGqfkFVX4zQX5YlWr9+/SLg0WjD8JlcBSTCosX5QgKAhrfOQKS3v6aGqJCIDSgjPqgnjy16jVefLbwZHz5mHzDw+ncntncZFhMkHoNEDIlJWuIxs5jnSTwWjwT2PWCcPTp2nIy+7da8LvNV7LuBffPKlqnqOlXtkFc+G4gVZBRKzOwnqtopFLIAVFZWjgyC4EHcJlkIceA7wF7AudnCqqqqQb7vP0Dz5pdFI07rAacFnI/bfH/AHkJErgiCYPbcuXNPmDx5cgOwAmgAmDJlSp2qjgfm+L4/V1V/CFR6nvdVj1yb+CyahUQDsINc7APcOWvWrKwmgKoOC4LgLnKFxBrgaXIf1Bjg76paFDL817wJNEn5+vr6MYRCAiAIglOyvzdu3PgZmoVETj+1tbXXAlEhsQX4F1ATKZsAXJM3r+g9uBCXEJQDEbkvnG9HnHYVFRKrcfPN3i8BrkylUhdF+1i/fv1VtBQSdUBWkyoFvpc/9h7gHJqFRAMtfRb7AneqajeAeDw+B7coszg3j56GhoZzaBYSiMgvCw9t4wH8lRG/hAixQwfw5ms1rQqJst6dkJjHhlVb2dAAq7dlWL0lzYo12/E7Fgd0Kl5Nh3iVFMWnIlyMMBHhdIzjwEYS95cU6Db6XCWctwErcVGhFcC9BWjBCYkAmOt53gkicirwM5xWkMW0qqqqIeA00yAIbqZZSGwQkS8DfWOx2FAgSfNznqiqZ2Q78X1/Js1CIgNc0qFDh26qWgwMINwkQ3zZ3VJZBdwI/AJ4LlK/AfgpcC1wD3lIJpOLReTumpqa21S1TFUvjQo7Vc2o6veAr4d9zK6oqHgmTksP+Q5gallZ2e8nT57cENp5vwWOD+v3bmxs/AFQHl7PwanP4Bbl+ao6Pxy0A/BznNYCcAzwDeCaIUOGPLxs2bJNhKqsmR0DLAjpvpTH05eAipDuyEh5YGZ/C8caDURPGM4pKyu7PJSapFKpi8xsHm7BXKCqc1X12ZD2HZpV6mLcA70H+DfOLh2ZSCT+CSAi3zWz4dnxReQbyWTyD4DNnTu3uKamZg7w7ZDXqurq6r9MmzbtHVUdiFNns3g7vBd/x2kzo4C5OM3n/cB2EbnEzP6oqvVVVVWDfd//Bc3e8X1E5LvAFTNnznxTVf+EE5IAX1XVaVHzJDTlsng5mUz+o/Cw3jFYQLCpeVl5vUrxOhXx4tIWboQm1Gzczl03PF+wbsvG7d7Rpw0cSCL+OzlywYadTTyCfP/Om57nnVlRUdH0YqlqdkNqpCW+kl3LIe5V1Rdo1vo83/cvBKYBJwMHR2jPTSaTD4e/NwCVqlpG8xr4EW7DIRaLVQZBcCsw1My2q2pTeFxV16rq9cBxYVFvVY1XVFQ8BDwU0syj2Zx6U1WntXZDAJLJZCqVSn3FzB5KpVK3mNliYBVOOzsA+CJwqohMy/o44kCOa1lELk4mk3/MXpeXly+vrq4+q66u7jWaX6bxQHnoUDkp0vxX0RurqvWhWn9qyAC4HfWaiRMn+qp6B8077ElhmzhOs4nikKqqqkHl5eUrzOzESPmT2pyX/o1I+QuhPd1kPyaTyetU9QvhTfBC+mwYKSAXF4Qe4BYws+g41yeTySZTYfLkyQ3hfL8I9AN619XVnQX8CbcTRFXZb4X2ITjhfJ+qngi8BJQVGns3MTmZTF6fvSgvL39VVb8MvEao/ZnZOcAVIcnPgQtwgrQ7zr/xO4DKysqDgyA4NtL3/0DLkKQ9cGEH2DbI3qkHv/mWej07k2nIsGH1rgZwcvHmqzVw2kChMf05XEbhHkFEfhgVEuB20FbI788TEln6m1VVgf3DoqPDf6PvwdvAQaqa76eIrrNRV199daepU6duLy8vX4nTcu5Q1W6qeirwGZyW0RE4LNpJly5dErQUgruFZDL5Z1W9DbcJn4/z0wQ4gfEgMD2ZTDaNkR/1WBkVElmEO+J8mtXig8LdM7p48Dzvhvy2oSrzJ0KNADhs9uzZJVOmTKkTkdvNLCsohqtqLxEZHnFyLiY0A3zfH4tT/cZEul8Y+T0q8ruDquaYNiEGRn4XclIBPNWakAiz2qIPf1ihxURuavxxOEER9aivCx9SDlR1Q8j3d1rhbVexXlULPYt3Q36/HxYdoqrx8Bm9qKr3AKeFdd8jFBRBEFwc6WYrzunWErHtpYBYQ+4altIObK2pLyBadg3vbmsgCCzwPG/AnvXgYGb37pyqCYVMGXCzeIlmQZE1ufeL0HQH5u2k/0R9ff0+OCc7oVCpxvkCi9tq+H5BXQj0r+Ffm8gXFC8WpHKIHsKRmpqa7jQn+ADQuXPn1ws1FJHXIyE/r7a2tiewurS09L7a2trtQCdHJieZWZPw8Tzvx0EQHI278WOrqqruiIa7YrHY7ZFhorwMpjlTrTXs3Ur50601yGQyffKKjqZ5R2kN2cXdM1L2Cq28NiLyQjQ8uod4sY3+V0SfRVFRUVecLwecVpEVFCNUdXSXLl2erq2tPS/Sxe+1lcgXcYqwAiOb8V5zoiwAYpbYKWEbXahqqzkG+diNsHR2YkVtUhVAEAR9gOWpVGqMmS0i1wnq43b3LWH5sJY9fHjIT7hqS+XdK3pRUlKyXURyQmi1tbUlhRqaWY6ETCQSTV5WIg4XM/scTm0HeDlUExeF10f7vj8h0s0r5eXlyyPX0Qf7Ju6Fb+tv5fz58wt5tRsKzQEgCIL8kOF/dmGc7EsYbdtKJhHw/nwjpHNrFWYWjbnT2NhYl/2tqveR6xj7bm1t7QSa4/Q+0Pr5iqLumwBfSnLfZ9vRSOfuJZFTobuHjl2KicXFAznJHhs7wZ4Yu9fOW703mFl+xCgLIXI2Aud/gNw0+5dwQYE2/8zsCUDM7AaahcRqYGxJSUl3VT1AVY8Crn4fpvSekK9RHKqqvbTwIaTozrklNEfWRAk8zzuYXA9tFtEb++7gwYOjqXq34/LNwXnbs4vy1si/5+FeoB9G2kXNDnCRluwCul1Vv1uAj/eEkpKS9XV1dT6hryGMpd+8i82jZ/xHZFX+fCIzOy6/bA8wTFW7qGohp8BRkd+bow5LABH5uZllTYvx5O5kt6vqqtYGlc9em7YlY9fSsXhfYl6TnyJ4612KhvajR//OvLV297PLBwzuDk4LOhk4mQyBLTn7OZAHgFvk2NuW7nanO8dZqjpaVXPCxKlUapyZRU3YpQAi8riZZU20AxKJRF1+/ouqDgW+LyKLSktL/zllypS6MDTaFLIVkcuSyWT+2j6eXccHkm2d32kn4Ndz587N0QBU9TTcQ8riAYCioqKHiOzkQRBMzR8g9PReGCm6f+LEidEddVGkj6bdzvO8rKD4B83hvajGEzU7mngKMba6urq0AC+/VNUn1eGw/PqdYdq0ae/gEsgAMLPz8mlU1VPVh1X1H6lU6hJV3Q9ARKILroeITCrQ9rO4l/O9ohTnVc/v/3M4GziLB/NpzOyvOI0MXDi0SVB4njd3pyMLD0pM8Ho35W8RbKgl2N7I8FGtWXutIxYThh7ZD39DLY0PvkJm2TqCze96ZowEpoCMaq2ttFRhdkelEeDu8BnuU1VVNVhdfs3vIzR+LBa7CcDMbqc5glicTqdvCtc+0HSG4mbg22a2qLa2dvm8efMSZpafc9Ev0qZjKpVK4kKVTaitrc2fR3TDGXjFFVf0BaSysnI47xMKpXCfU1NTc1DoDKvFZTeeH6k3z/PmAEyfPn1L6Pi7MKw7S1Wvi8ViP4vH45saGhpG4ByQXSPtZ0cHU9UaVX2EXCflqoqKin+H9TtCJ9u4SP0G4PE8vq/BRTFiQN+6urq71CWMPBuPx/tmMplJOCee4HIMSnHhz/z70GZau4j82syyu/IpYehqFrDK87wDgyBQwlCWmZ0iImuBVUVFRX9paGi4ijAD0szmqOpnPM+7Jfyy0MnApfnjp9PpXbXL8+mmq2ofz/NuBmrM7GQzS5L7svw2vxNVbVTVuTjHWhTPhiG5neFO4ILYPmUE60KXgBn+C2sZcPRABh3SixXP78phWIeRJ+xLafcOpP/xuvuOxYZtwJsUn3YwdOtoZPxWoyBmFt0s5Oqrr+5YMEmsdXQyszm4BKRC9VeXl5f/B9w6TqVSKTP7WVh3Cu5rUU/hnJMjyXVS6qRJk9Lz589ftmzZsnWEAsLMZqvqBFwEYoSZdaElOpKb4xRNC++aTqfXqWpjEASbcZnF7xn5GkVWVR2Gc2xdB1xE7gSvrKioaPIIFxUVXYYL7WTxLd/3X25oaKjB7fKHR+pmq+rD5EFE7swrupOIS0xE7sqrv0cj6d4AqvqKiFRFikYDTwLpTCazGphB80uyvEOHDpUR2ujDaNW+BxgyZMifiKRY46T9q0A6CIIXcclOWdySTCZvBfjxj3/8Ni7enkUcmBwEwcM4cy0Zjp2/IgstlEKI0hlurt8MguDBIAieN7PZ5Arsm1T1n630dS15+TUisnNtAmBr/d8xW+ftW4Z0al42/qot+Cs3c+wXBjFoeK82Omgaj5En7MvBx/THf34twdbm98Lr2w3p1hHgETn+9jWtdpJ377Zv395Cy2wDV+D8BYWQBmYNHTp0ZrQwmUzOJncj7IQzG46m+R3ygWmqeiPAxIkTfRH5Fi5fB9w7eSxus+mCExj/io4Ti8Xy53EXLfNAioA++dbBniJfUFwvIhW4OHA+NorIxao6I1o4ffr0LYlE4ljg/gJtstghIperO6PRAiKSn7xzX/TCzB5oqz6LZDJZiUsEK5Q809Q2kUiMyTtBF11QbS6m0GyaCPyR1gN+AfCbsrKyr0VpVPUanNZQ30q7ZSJyaV7ZrgqKHCFA6/kGATCvrKyshekT4XMrucJ/k5n9eVeYkDMWN+B5V4sIicP3yalL/2sVwaoaRn9pMCdOGEKPfgVkskC/Qd0548LhHHJsfzLPrSXzciTHyhPih4YmTGDJnbDzLE7oXQtcW1RU1JYTOZcNkZeAA4GxuKTCv+JMh2m4HIkZeSY0uMjKpZ7nZfM9sj4KwwmdGzzPO1xVfxptlEwmF+M0jtm4TfJp3OYxBxjhed73ovPwfT/H4a4uFf8kXMLiM7hP2z0D/KGurm63ozGFIOrObWRtmV+p6vfDMwlHicj+Ziae563s3bv344UOCuUxPAo4A3eDO+M8wU8kEom/tXXaLuRjfNam7Nix4135KqKqjsueiDOze9sKdanq3iJyrpkdgXNw7gD+43neHZEPdmRpPRFp8guIyIZ8mjbGORRnEmUPd20TkWc8z7slq5K20q6PiIw3s4Nxu85bIrLEzO7A+S+aHJp7+IWrFar67/CA0pm41OW0iLzqed6ivGhRC6RSqdPNLKrFValqRasN8mAvTShia2YpcFj66TfwX81lPzagO96IAcQ6F7NjWwM1m3aQSfskijx69u9CUUkcf/N2/GdXE2zOjcQmjtyf2MAe4JyYE3ifEJ5lia6pr6rL/3lPUJdAGORrwP9tEFVdRnPa8DWq+l6TfdrxXwhtThsWXCp8VpOpTyQSA3flBGsUtnTsEHx70qBT5omV+KvyToOKuNTuPl2Qzh0g4UGjj23d4ZyfNS1dCfHhexM/uC+YvUaaI2TMwl3Oi9gZ1B30i5oxF6lq+9e+Q8TZDfu8HZ9onErhJLXq3RUSAHLMbcvt0XFnCXZn/Kj9S6RTMZll67NhTjAj2FhLsHEX0rpjnvuG5sAeYGzAi50lY/72vgmJEDkmnojsjj/jEw+PdkHxqUfo8No/r7geuAqobNli1yCjb30AszMEeSs+vD9FJx2E13M33j8RYnt3o/jMYRaaG6/iZ0bJqL+9vKc8tYFGnI2/AFhgZm1+YObThriIXGpm2WjA2o+Um3Z8JKipqUnjTj7uA/QSkS1m9qSq1uyk6U4hxy180B4eNwLPrvd6dD6t6KSDCDa/i79qM8HGWuzdhlyXsAjStYRY3654++2F1zWb7Gu/wYLL5fg7PpDvgarqa+R+oqAdEbR/rr8dHxps6fiTCYIkLvzn1l7GN6vPiKV9JO4hnYoNrylRygduJbBqOW5hq2dw2vHBo11QtONDhz0yfiCefw7IESCHYtYDl2fQgNhK4BWMeyFYLKP/3uK/t2vHh4//A4PqX1Z83fSsAAAAAElFTkSuQmCC",
+	"queued":         "iVBORw0KGgoAAAANSUhEUgAAABEAAAARCAYAAAA7bUf6AAAACXBIWXMAAAakAAAGpAHF3nU5AAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAqRJREFUOI19lL9LW1EUxz/vJSbBpD6SmMSAOtXQKrUg0dFFETJZpIibtKCCVefaP8C1FNxbcBBKh3ZT8AdEMxiXxLyYSjrZILUxmuBL0MS82yHmNZHSC3e4557vOV/u+X6vxIP1HR5V4JUE48BToB24AFISfAU+9oHWiJEaDypMAR8AL4DZ40F2OtGvrrjLZutp5wKWnsHnhwRIwDsV9KTVKjJLS0JTVXF7e2vs60RCZBYXRdJiESroSVhuKqDClAp6yucThUikCfxwF/b3RcrrFSroCZgEkJLgEPBDslp9nTs72IJBADRNI51O093djdvtbmp6E42SGRlBlMu/zfBY1uE14FPm5rAFg5RKJdbW1lhdXWVjYwObzQbA6ekpQggAbENDKLOzAN4qTMv3U0CZmQGgtbUVh8MBgKIotLW1oWka6+vr7O7uGmzq+TqMy0Cv2ePBEggAUCqVSKfT9PT0EAgEMJlMuFwugsEg0WgUTatN1/LkCSa3Gwl6ZcAlu1xGh/Pzc6rVKsPDw4RCISM+MDCAEIKzszMjZqq9lVsGcvrlpXHR0tICQLFYRJL+yqhYLNYYWCxGrJrLIcGFDBzfZbOUT04A8Pv9KIrC1tYW+XwegEKhwObmJna7na6uLgDKqRTVXA7g2PQGFCAkmc3Yx8aQZRm/308sFiMSiRCLxQiHw1QqFSYmJnA6nQDkVla4PTwEeF/XSVqyWjs6t7exDQ4a9I+Ojsjn8yiKQn9/vzG1m4MDMqOjhk4ASMCkCnrK6xWFvb3/KzYcFimPpy79l00qTMKyCnrSYhGZhQVxHY83eyceFz/n5xu987aObXJxAialmos7AEzt7YaLqxcX9bRfEiz2wZd/Frln5BAwLeCFBL3c/ycSJAV8q8Kn51BsxPwBQQRgVqQYmJ4AAAAASUVORK5CYII=",
 	"ready":          "iVBORw0KGgoAAAANSUhEUgAAABEAAAARCAYAAAA7bUf6AAAACXBIWXMAAAakAAAGpAHF3nU5AAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAdpJREFUOI2VlLGPEkEUh7+327AbJETInYGCxkZq6MF/4CyMOarL0WtHc5IwhYmhtLXBWAi5WEhFC/UuiRR3hsRONjmPpZPdQELG4gD37uIFft28vN/3ZubNG+GOms3mozAMT4Ej4BmQBnzgh4h801q3lFJ/oh6JLpRSx8AH4ADAtm0syyIMQ4Ig2KT9Bt4opc7vQZRSZ8A70zSlWCxSKBRIpVLbArPZDNd1cRyH1WqlReRto9F4v4Wsd/AlHo9LpVIhk8ncPeVWnufRbreZz+caOFZKnYtSKg78NE3zsFqtPgiIglqtFqvV6tqyrKcGUAUOi8XiTgCAbDZLoVAAOAjD8MTgpgub4M6K5B8ZQN627VuXuIvS6TSWZQHkDeCxbdt7ATZa+1IGMIu8gb209vkGcBkEAb7v7wWYTqeEYQhwaYhIF2A4HO4FcV0XABHpGlrrFnDlOA6e5+0EmEwmm6LXsVjss9nv95elUumX1vrleDyWXC5HIpF4ENDpdFgsFho4rdfr302Afr9/US6Xl8vl8vloNJIgCEgmk0S75vs+g8GAXq+3AZwppT7C/Sl+xc0UP4H/TvEV8Fop9XUTuAVZg+IicqK1fgHk+fefXIhI17btT7VabR71/AVWj8SDrdCT0gAAAABJRU5ErkJggg==",
 	"restart-off":    "iVBORw0KGgoAAAANSUhEUgAAABwAAAAUCAYAAACeXl35AAAACXBIWXMAAAOuAAADrgHKWVOZAAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAhZJREFUSIm1lr2O2kAUhY9nbP7GCAkLyTRJQ5tEKSkos09DUvAGiCdgK55kO9whJVWUKJVpklRgCSQLe/w7dgqCZQJrm83uaWzLd+5358z1lSUAmEwm74bD4Sdd199SSnUhBMUziFIqoijabLfbb6vV6n4+n3/HbDa7W6/Xm/SFZZrmZjqdfoBhGF9eGnaSYRifZVVVX1WxJ0kSBEEA3/dBCEGn07nZYlVVX8sApCKI7/vwPA9hGCJNUwC4gKVpCkl6NE1eEimL4JwjCIIMBgC1Wi27F0LAsiyEYVgFiELgybp89ZIkQZbl7Nm2bQghsNvtEMfx/wEB4HA4XOwuX0C9XgdwtNW27VKgXPRSCAHf9wEArVYLAEDp+SfKGEMURZn1cRyfOXATMH8ujDEoioIkSS7iGGPgnGdrioCFluaTE0LOrnnld32toMrAfHIhxE2FPQmYb3/XdR+NO9n575qbgZRSNBoNAIDneVehnHM4jgPg2LFF5weUNA1wnCphGCJJEti2Dc55VkQQBFljSZJUadyVAiml0DQN+/0eQghEUYQois5iCCHodruluzsB07IgRVHQ6/Xgui48z8smiizLaDabYIyVNstfpbLjOL8A6GWRhBC022202+0qia/KcZyfdDAY/O73+3eapqlPzlRBpmluHh4exhIAjMfjN6PR6KOu6+8VRXn2XwzLsr4ul8v7xWLx4w9g3rmldJZ7DAAAAABJRU5ErkJggg==",
 	"restart-on":     "iVBORw0KGgoAAAANSUhEUgAAABwAAAAUCAYAAACeXl35AAAACXBIWXMAAAOuAAADrgHKWVOZAAAAGXRFWHRTb2Z0d2FyZQB3d3cuaW5rc2NhcGUub3Jnm+48GgAAAo9JREFUSIm1lr9LG2EYxz9vLoLIoZIQcgo2+AMUtQ1FRBQySZ2cdMigf0LaQbI4OIiLjhERVwchKBJ00EFyICpNTSA2dDjilIIhd4NoBhcT06HtlWBziVf7nZ6X+z58Xr48976vAFhYWPCPj49/UhTlnSRJSrlclngFSZJUfnx8LOi6/vXi4mI9EolkWFlZmbq+vi5U/rOy2WxheXn5A6qqfnlp89PTky2oqqoJpyzLbxqJp1gscn5+zunpKR6Ph3A4/OKIZVn2OQFRy3B/f088HkdVVVKpFKVSCYDFxcUqX6lUwul0NsIUDsuvQnBwcEAikTBhAMPDw2Z9c3PDzMwMmUymESCWwNbWVpaWlpCkP0Pb3NxMb2+vuY5EIuTzeUKhELlc7t+AAJubm5TLZYT4mfzQ0FBVfGNjYzgcDh4eHlhdXa0LtAzeMAzOzs4AmJ6eRgiBx+Op8szOzqJpGrFYjGQySS6Xw+fz2QNeXV1RqVQACAaD9Pf3c3t7+8wXDAaJxWIApNNpS6BlpHd3d2btcrkQQuB2u5/5FEX5a8+Lge3t7Wat63pDG2tra7MP9Pv95rDs7e3V9O3v71f12AZ6vV4CgQAAx8fH7O7uPvMcHh6ys7MDwMjICD09PZZAcXl5WRgdHfXWMuTzeebn5ykWiwAMDAwwMTEBQCqVMn/4lpYWtre36e7urglLJpN63fOos7OTjY0NwuEwhmGgaRqaplV5XC4Xa2trlrDfcgKVeqbBwUGi0SjRaJSTkxPzROnq6mJycpK5ubm6w/JLFVRVTdi6a+xdT5+lvr6+7x0dHVNut1tuZIt2lc1mC0dHRyEBEAqF3gYCgY+Korxvamp69SeGYRjpeDy+vrW19e0HmwogwR6JzicAAAAASUVORK5CYII=",
@@ -0,0 +1,489 @@
+package rpeat
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// JobRun is a running instance of a job with StartRule "Start". Each instance
+// is tracked separately, with its own process, logs and History entry
+type JobRun struct {
+	RunUUID      uuid.UUID
+	Pid          int
+	Started      string
+	StartedUNIX  int64
+	AsOf         string
+	Params       map[string]string
+	Stdout       string
+	Stderr       string
+	CmdEval      string
+	RetryAttempt int
+	ExitCode     int
+	Warning      string
+	Unscheduled  bool
+	Reason       Reason
+
+	LimitExceeded string
+	Usage         *Usage
+
+	proc    *os.Process
+	start   time.Time
+	ended   JState // set when the instance is stopped or ended
+	stopped chan bool
+}
+
+// canStartInstance returns true if the job runs concurrent instances and is below MaxInstances
+func (job *Job) canStartInstance() bool {
+	if !job.startRule.Concurrent || job.isController() || !job.hasCmd() {
+		return false
+	}
+	return job.MaxInstances == 0 || len(job.runs) < job.MaxInstances
+}
+
+// hasInstances returns true if any concurrent instances are running
+func (job *Job) hasInstances() bool {
+	job.Lock()
+	defer job.Unlock()
+	return len(job.runs) > 0
+}
+
+// getRun returns the running instance with RunUUID runid, or nil
+func (job *Job) getRun(runid string) *JobRun {
+	for _, run := range job.runs {
+		if run.RunUUID.String() == runid {
+			return run
+		}
+	}
+	return nil
+}
+
+// startInstance starts a new concurrent instance of the job unless MaxInstances
+// instances are already running, returning false if the trigger is ignored. Scheduled
+// instances run the oldest missed trigger first when catching up, otherwise trigger
+// (see triggerAsOf) is the asof time
+func (job *Job) startInstance(unscheduled bool, reason Reason, trigger string) bool {
+	job.Lock()
+	if !job.canStartInstance() {
+		ServerLogger.Printf("[Concurrent] %s:%s has %d running instances (MaxInstances:%d) - trigger ignored", job.JobUUID, job.Name, len(job.runs), job.MaxInstances)
+		job.Unlock()
+		return false
+	}
+	job.Unlock()
+
+	var asof string
+	if !unscheduled {
+		if asof = job.nextMissed(); asof == "" {
+			asof = trigger
+		}
+	}
+	job.Lock()
+	run := &JobRun{AsOf: asof, Unscheduled: unscheduled, Reason: reason, stopped: make(chan bool)}
+	if unscheduled {
+		run.Params, job.params = job.params, nil
+	}
+	job.runs = append(job.runs, run)
+	job.Instances = len(job.runs)
+	job.Unlock()
+
+	go job.runInstance(run)
+	return true
+}
+
+// runInstance runs run, retrying failures up to Retry times, and records the result
+func (job *Job) runInstance(run *JobRun) {
+	maxd := job.getMaxDuration()
+	for {
+		held, ok := job.acquireSlots(run.stopped)
+		if !ok {
+			job.endInstance(run, job.runEnded(run))
+			return
+		}
+		state := job.execInstance(run, maxd)
+		releaseSlots(held)
+		if state != JFailed || run.RetryAttempt >= job.Retry || job.getHold() {
+			job.endInstance(run, state)
+			return
+		}
+		job.Lock()
+		jh := run.history(job, JRetryFailed)
+		job.prependHistory(jh)
+		job.modified = time.Now().Unix()
+		job.Unlock()
+		job.sendRunUpdate(run, JRetryFailed)
+
+		run.RetryAttempt++
+		select {
+		case <-time.After(job.getRetryWait(run.RetryAttempt)):
+		case <-run.stopped:
+			job.endInstance(run, job.runEnded(run))
+			return
+		}
+	}
+}
+
+// execInstance starts the process for run and waits for it to complete, returning the
+// resulting state of the run. MaxDuration ends the run if specified
+func (job *Job) execInstance(run *JobRun, maxd time.Duration) JState {
+	runid := uuid.New()
+	jobRunDir := filepath.Join(job.TmpDir, fmt.Sprintf("%s", job.JobUUID))
+	stdoutName := filepath.Join(jobRunDir, fmt.Sprintf("%s.stdout", runid))
+	stderrName := filepath.Join(jobRunDir, fmt.Sprintf("%s.stderr", runid))
+
+	// evaluation uses RunUUID, Params and sets CmdEval on the job, which are restored
+	job.evalLock.Lock()
+	job.Lock()
+	runUUID, cmdEval, params := job.RunUUID, job.CmdEval, job.params
+	job.RunUUID, job.params = runid, run.Params
+	job.Unlock()
+	c, err := evaluatedCmd(job, false, run.AsOf)
+	if err != nil {
+		ServerLogger.Printf(err.Error())
+	}
+	scriptErr := job.writeScript(runid)
+	if scriptErr != nil {
+		scriptErr = errors.New(fmt.Sprintf("unable to write Script: %s", scriptErr))
+		ServerLogger.Printf("[runInstance] %s %s", job.Name, scriptErr)
+	}
+	job.Lock()
+	run.CmdEval = job.CmdEval
+	job.RunUUID, job.CmdEval = runUUID, cmdEval
+	job.Unlock()
+	if job.Logging.StdoutFile != "" {
+		stdoutName = job.ExpandEnv([]string{job.Logging.StdoutFile}, run.AsOf)[0]
+	}
+	if job.Logging.StderrFile != "" {
+		stderrName = job.ExpandEnv([]string{job.Logging.StderrFile}, run.AsOf)[0]
+	}
+	job.Lock()
+	job.params = params
+	job.Unlock()
+	job.evalLock.Unlock()
+
+	flags := os.O_RDWR | os.O_CREATE | os.O_TRUNC
+	if job.Logging.Append {
+		flags = os.O_RDWR | os.O_CREATE | os.O_APPEND
+	}
+	job.runDir()
+	var runFiles []*os.File
+	stdout, ferr := openRunLog(stdoutName, job.Logging.StdoutFile == "", flags)
+	if ferr != nil {
+		ServerLogger.Printf("[runInstance] %s unable to create stdout: %s", job.Name, ferr)
+		return JFailed
+	}
+	defer stdout.Close()
+	if job.Logging.StdoutFile == "" {
+		runFiles = append(runFiles, stdout)
+	}
+	stderr, ferr := openRunLog(stderrName, job.Logging.StderrFile == "", flags)
+	if ferr != nil {
+		ServerLogger.Printf("[runInstance] %s unable to create stderr: %s", job.Name, ferr)
+		return JFailed
+	}
+	defer stderr.Close()
+	if job.Logging.StderrFile == "" {
+		runFiles = append(runFiles, stderr)
+	}
+
+	c.SysProcAttr = syscallSysProcAttr()
+	c.Stdout = stdout
+	c.Stderr = stderr
+	uid, gid, runAsErr := job.applyRunAs(c.SysProcAttr)
+	job.chownRun(uid, gid, runid, runFiles...)
+	stdin, workDirErr := job.applyWorkDir(&c, run.AsOf, uid, gid)
+	lim := job.newLimiter(&c, runid)
+
+	job.Lock()
+	run.RunUUID = runid
+	run.Stdout = stdout.Name()
+	run.Stderr = stderr.Name()
+	run.start = time.Now()
+	run.StartedUNIX = run.start.Unix()
+	run.Started = run.start.In(job._location).Format("2006-01-02 15:04:05")
+	job.Unlock()
+
+	if err = runAsErr; err == nil {
+		err = workDirErr
+	}
+	if err == nil {
+		err = scriptErr
+	}
+	if err == nil {
+		err = c.Start()
+	}
+	if stdin != nil {
+		stdin.Close()
+	}
+	if err != nil {
+		lim.ended(nil)
+		run.ExitCode = -1
+		ServerLogger.Printf("[runInstance] %s failed to start with error ( %s )", job.Name, err)
+		stderr.Write([]byte("[ rpeat ] Unable to create process (possibly missing shell e.g. /bin/sh -c ): " + err.Error()))
+		return JFailed
+	}
+
+	job.Lock()
+	run.proc = c.Process
+	run.Pid = c.Process.Pid
+	job.RunUUID = run.RunUUID
+	job.Pid = run.Pid
+	job.IsRunning = true
+	job.Failed = false
+	job.Warning = ""
+	job.prevStart = run.start
+	job.StartedUNIX = run.StartedUNIX
+	job.Started = run.Started
+	job.Logging.stdoutFile = run.Stdout
+	job.Logging.stderrFile = run.Stderr
+	job.StdoutFile = []string{run.Stdout}
+	job.StderrFile = []string{run.Stderr}
+	job.Unlock()
+	ServerLogger.Printf("[Concurrent] started %s:%s run:%s pid:%d (%d running)", job.JobUUID, job.Name, run.RunUUID, run.Pid, job.Instances)
+	lim.started(run.Pid)
+	job.setJobState(JRunning)
+	job.sendRunUpdate(run, JRunning)
+
+	tw := job.maxRuntimeWarning(func(w string) {
+		job.Lock()
+		run.Warning = w
+		job.Unlock()
+	})
+	var te *time.Timer
+	if job.MaxDuration != "" {
+		te = time.AfterFunc(maxd, func() {
+			ServerLogger.Printf("[Concurrent] MaxDuration reached for %s:%s run:%s", job.JobUUID, job.Name, run.RunUUID)
+			job.stopInstance(run, JEnd)
+		})
+	}
+	err = c.Wait()
+	if te != nil {
+		te.Stop()
+	}
+	if tw != nil {
+		tw.Stop()
+	}
+	exceeded := lim.ended(c.ProcessState)
+
+	job.Lock()
+	run.LimitExceeded = exceeded
+	run.Usage = processUsage(c.ProcessState)
+	run.proc = nil
+	run.Pid = 0
+	run.ExitCode = 0
+	if err != nil {
+		run.ExitCode = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			run.ExitCode = exitErr.ExitCode()
+		}
+	}
+	job.Unlock()
+
+	if state := job.runEnded(run); state != 0 {
+		return state
+	}
+	if exceeded != "" {
+		return JLimitExceeded
+	}
+	if err != nil {
+		return JFailed
+	}
+	if w := job.minRuntimeWarning(time.Since(run.start)); w != "" {
+		ServerLogger.Printf("[MinRuntime] %s:%s run:%s %s", job.JobUUID, job.Name, run.RunUUID, w)
+		job.Lock()
+		run.Warning = w
+		job.Unlock()
+		return JWarning
+	}
+	if run.Unscheduled {
+		return JManualSuccess
+	}
+	return JSuccess
+}
+
+// runEnded returns the state set when run was stopped or ended, or 0
+func (job *Job) runEnded(run *JobRun) JState {
+	job.Lock()
+	defer job.Unlock()
+	return run.ended
+}
+
+// history returns the JobHistory entry of run
+func (run *JobRun) history(job *Job, state JState) JobHistory {
+	stop := time.Now()
+	return JobHistory{
+		RunUUID:        run.RunUUID.String(),
+		ExitCode:       run.ExitCode,
+		CmdEval:        run.CmdEval,
+		JobStateString: state.String(),
+		JobStateAbb:    historyAbb(state),
+		RetryAttempt:   run.RetryAttempt,
+		Start:          run.Started,
+		StartUNIX:      run.StartedUNIX,
+		Stop:           stop.In(job._location).Format("2006-01-02 15:04:05"),
+		Elapsed:        dhms(stop.Sub(run.start).Round(time.Second)),
+		Stdout:         run.Stdout,
+		Stderr:         run.Stderr,
+		Unscheduled:    run.Unscheduled,
+		Reason:         run.Reason,
+		AsOf:           run.AsOf,
+		Params:         run.Params,
+		Warning:        run.Warning,
+		LimitExceeded:  run.LimitExceeded,
+		Usage:          run.Usage,
+	}
+}
+
+// endInstance removes run from the running instances and records its final state. The
+// job state follows the last instance to complete, while every instance adds a History
+// entry and sends a dependency event with its own state
+func (job *Job) endInstance(run *JobRun, state JState) {
+	ServerLogger.Printf("[Concurrent] %s:%s run:%s %s", job.JobUUID, job.Name, run.RunUUID, state)
+	if (state == JFailed || state == JLimitExceeded) && !job.cronStart.isDependent() {
+		job.setHold(true)
+	}
+
+	job.runlock.Lock() // job.asof is shared with RegisterJob
+	defer job.runlock.Unlock()
+
+	job.Lock()
+	for i, r := range job.runs {
+		if r == run {
+			job.runs = append(job.runs[:i], job.runs[i+1:]...)
+			break
+		}
+	}
+	job.Instances = len(job.runs)
+	if job.Logging.purge < math.MaxInt64 {
+		job.Logging.Logs = append(job.Logging.Logs, JobLog{PrevStop: time.Now(), LogFiles: []string{run.Stderr, run.Stdout}})
+		if len(job.Logging.Logs) == 1 {
+			job.Logging.l.Reset(job.Logging.purge)
+		}
+	}
+	if job.Instances > 0 {
+		job.prependHistory(run.history(job, state))
+		job.modified = time.Now().Unix()
+		job.Unlock()
+		job.SaveSnapshot(true)
+		job.sendRunUpdate(run, state)
+		return
+	}
+
+	// last running instance sets the job state
+	job.RunUUID = run.RunUUID
+	job.CmdEval = run.CmdEval
+	job.IsRunning = false
+	job.Pid = 0
+	job.Failed = state == JFailed || state == JLimitExceeded
+	job.ExitCode = run.ExitCode
+	job.prevStart = run.start
+	job.StartedUNIX = run.StartedUNIX
+	job.Started = run.Started
+	job.prevStop = time.Now()
+	job.PrevStop = job.prevStop.In(job._location).Format("2006-01-02 15:04:05")
+	job.PrevStopUNIX = job.prevStop.Unix()
+	job.PrevStart = job.Started
+	job.elapsed = job.prevStop.Sub(job.prevStart).Round(time.Second)
+	job.Elapsed = dhms(job.elapsed)
+	job.ElapsedUNIX = elapsedToInt(job.elapsed)
+	job.recordUsage(run.Usage)
+	job.Logging.stdoutFile = run.Stdout
+	job.Logging.stderrFile = run.Stderr
+	job.RetryAttempt = run.RetryAttempt
+	job.Unscheduled = run.Unscheduled
+	job.Reason = run.Reason
+	job.Warning = run.Warning
+	job.LimitExceeded = run.LimitExceeded
+	job.asof = run.AsOf
+	params := job.params
+	job.params = run.Params
+	pending := len(job.missed) > 0
+	job.Unlock()
+
+	job.setJobState(state)
+	job.sendUpdate()
+
+	job.Lock()
+	job.asof = ""
+	job.params = params
+	job.Unscheduled = false
+	job.Unlock()
+
+	if pending {
+		// triggers were ignored at MaxInstances while catching up
+		job.resetTimer(0)
+	}
+}
+
+// sendRunUpdate sends an update to clients and a dependency event with the state of run
+func (job *Job) sendRunUpdate(run *JobRun, state JState) {
+	job.sendUpdateClient()
+	job.state <- &depEvt{JobUUID: job.JobUUID, RunUUID: run.RunUUID, Name: job.Name, JobState: state}
+	if job.HasAlerts() {
+		go job.sendAlert()
+	}
+}
+
+// stopInstance signals the process group of run with ShutdownSig, recording jstate as
+// the final state of the run
+func (job *Job) stopInstance(run *JobRun, jstate JState) {
+	job.Lock()
+	if run.ended != 0 {
+		job.Unlock()
+		return
+	}
+	run.ended = jstate
+	close(run.stopped)
+	pid := run.Pid
+	job.Unlock()
+
+	ServerLogger.Printf("[stopInstance] %s:%s run:%s pid:%d (%s)", job.JobUUID, job.Name, run.RunUUID, pid, jstate)
+	if pid == 0 {
+		return
+	}
+	sig := os.Kill
+	switch job.ShutdownSig {
+	case "SIGINT", "Interrupt":
+		sig = os.Interrupt
+	case "SIGKILL", "Kill":
+		sig = os.Kill
+	}
+	pgid, err := syscallGetpgid(pid)
+	if err != nil {
+		ServerLogger.Printf("[stopInstance] error: %s", err.Error())
+		return
+	}
+	if err = syscallKill(-pgid, sig); err != nil {
+		ServerLogger.Printf("[stopInstance] error: %s", err.Error())
+	}
+}
+
+// stopRun stops the running instance with RunUUID runid
+func (job *Job) stopRun(runid string, jstate JState, reason Reason) error {
+	job.Lock()
+	run := job.getRun(runid)
+	if run != nil {
+		run.Reason = reason
+	}
+	job.Unlock()
+	if run == nil {
+		return errors.New(fmt.Sprintf("no running instance %s of job %s", runid, job.JobUUID))
+	}
+	job.stopInstance(run, jstate)
+	return nil
+}
+
+// stopInstances stops all running instances of the job
+func (job *Job) stopInstances(jstate JState) {
+	job.Lock()
+	runs := make([]*JobRun, len(job.runs))
+	copy(runs, job.runs)
+	job.Unlock()
+	for _, run := range runs {
+		job.stopInstance(run, jstate)
+	}
+}
@@ -94,6 +94,9 @@ const (
 	JManualSuccess        //1127
 	JUpdating             //1128
 	JUpdated              //1129
+	JQueued               //1130
+	JBlackout             //1131
+	JLimitExceeded        //1132
 )
 
 func (jstate JState) String() string {
@@ -128,8 +131,11 @@ func (jstate JState) String() string {
 		"manualsuccess",
 		"updating",
 		"updated",
+		"queued",
+		"blackout",
+		"limitexceeded",
 	}
-	if jstate < JRunning || jstate > JManualSuccess {
+	if jstate < JRunning || jstate > JLimitExceeded || jstate == JUpdating || jstate == JUpdated {
 		return "Unknown"
 	}
 	return names[jstate-1100]
@@ -142,7 +148,7 @@ func (jstate JState) ColorizedString() string {
 	switch jstate {
 	case JSuccess:
 		s = fmt.Sprintf(Green, jstate.String())
-	case JFailed:
+	case JFailed, JLimitExceeded:
 		s = fmt.Sprintf(WarningColor, jstate.String())
 	default:
 		s = jstate.String()
@@ -196,7 +202,11 @@ type JobSpec struct {
 	Hidden   bool  `json:"Hidden,omitempty" xml:"Hidden,omitempty"`
 
 	// Commands defining what runs on trigger
-	//   - Shell (not implemented) controls what shell to run
+	//   - Shell is the interpreter Cmd or Script is run with, e.g. "bash" or
+	//     "/usr/bin/python3 -u". Cmd is run as SHELL -c CMD. See Shell
+	//
+	//   - Script is a multi-line body written to a file for each run and run
+	//     with Shell (/bin/sh by default) in place of Cmd
 	//
 	//   - Cmd is string run by scheduler.  Like cron, if bash-style behavior
 	//     is required it should be of form:
@@ -212,9 +222,29 @@ type JobSpec struct {
 	//   - ShutdownSig is used to terminate a job when required by sending a signal to process (i.e. Control-C (SIGINT) or (SIGKILL))
 	Shell       *string `json:"Shell,omitempty"`
 	Cmd         *string `json:"Cmd,omitempty"`
+	Script      *string `json:"Script,omitempty"`
 	ShutdownCmd string  `json:"ShutdownCmd,omitempty" xml:"ShutdownCmd,omitempty"`
 	ShutdownSig string  `json:"ShutdownSig,omitempty" xml:"ShutdownSig,omitempty"`
 
+	// Process settings of Cmd or Script
+	//   - WorkDir is the working directory the process starts in, expanded with Env
+	//     and DateEnv. If not set the working directory of the server is used. With
+	//     CreateWorkDir it is created (including parents) if missing at each run
+	//
+	//   - Umask is the octal file mode creation mask of the process, e.g. "0027"
+	//
+	//   - Stdin is the standard input of the process, expanded like Cmd. A value
+	//     starting with @ is a file to read, e.g. "@/data/$YMD/input.csv", otherwise
+	//     the value itself is the input. If not set stdin is empty
+	//
+	//   With RunAs, WorkDir is only created and the Stdin file only read if the
+	//   RunAs user is permitted to do so, and Umask requires the rpeat-server
+	//   executable to be executable by the RunAs user
+	WorkDir       *string `json:"WorkDir,omitempty"`
+	CreateWorkDir *bool   `json:"CreateWorkDir,omitempty"`
+	Umask         *string `json:"Umask,omitempty"`
+	Stdin         *string `json:"Stdin,omitempty"`
+
 	// Environment Variables
 	//
 	//   Env defined execution environment variables that are resolved at runtime
@@ -229,8 +259,23 @@ type JobSpec struct {
 	//        YR=CCYY        => $YR=2006
 	//        MDY=MM/DD/CCYY => $MDY=01/02/2006
 	//   Add example for TZ and cal and shifts
-	Env     *EnvList `json:"Env,omitempty"`
-	DateEnv *EnvList `json:"DateEnv,omitempty"`
+	//
+	//   FiscalYearStart is the first month of the fiscal year used by the FY and FQ
+	//   DateEnv variables, as a name or number (default January). e.g. with "October"
+	//        FYQ=FYFQ       => $FYQ=20071 on 2006-10-02
+	//
+	//   DateEnvAsOf is the time DateEnv of scheduled runs is relative to: now (default),
+	//   the time the run starts, or trigger, the time the run was scheduled for. With
+	//   trigger a run scheduled for 23:59:59 that starts at 00:00:01 after Jitter or a
+	//   wait for a Pool keeps the date of the day it was scheduled.
+	//
+	//   Params declares typed parameters set in the environment like Env, whose values
+	//   may be overridden when the job is started manually. See Param
+	Env             *EnvList `json:"Env,omitempty"`
+	DateEnv         *EnvList `json:"DateEnv,omitempty"`
+	FiscalYearStart *string  `json:"FiscalYearStart,omitempty"`
+	DateEnvAsOf     *string  `json:"DateEnvAsOf,omitempty"`
+	Params          *[]Param `json:"Params,omitempty"`
 
 	// ExitState is defined as 2=JWarning 3=JFailed to allow error codes to be used
 	// to internally trigger JobStates
@@ -250,13 +295,18 @@ type JobSpec struct {
 	// constructed Dependency ruleset
 	//
 	// JobsControl makes available additional controls and is required if Jobs
-	// is defined.
+	// is defined. JobsControl.MaxConcurrent limits the number of jobs of Jobs
+	// running at once, queuing others as a Pool does.
 	//
 	// WARNING:
 	// This feature is still experimental and will likely change in future versions.
 	Jobs        []JobSpec    `json:"Jobs,omitempty" xml:"Jobs,omitempty"`
 	JobsControl *JobsControl `json:"JobsControl,omitempty" xml:"JobsControl,omitempty"`
 
+	// Matrix expands the job into one child job per combination of the values
+	// of its Env variables, optionally controlled by the job as with Jobs. See Matrix
+	Matrix *Matrix `json:"Matrix,omitempty" xml:"Matrix,omitempty"`
+
 	// Calendar and Timezone controls
 	//
 	// All jobs use a defined timezone (implied or explicit) to control
@@ -283,15 +333,28 @@ type JobSpec struct {
 	// only a few cases where rollback makes sense, for instance to ensure a job
 	// is run on the last business day of the month.
 	//
+	// CalAdjust replaces Rollback with a business day convention for trigger dates not
+	// in Calendar: following, modified-following, preceding, modified-preceding or
+	// none (the trigger is dropped).  e.g. "@eom" with modified-following triggers on the
+	// last day of the month, or if unavailable the prior available day as the next
+	// available day is in the following month.
+	//
 	// RequireCal ensures that all trigger dates calculated have coverage in the Calendar
 	// file specified.  If set to false, a lack of dates is interpretted as no special treatment.
 	// This is useful if a special calendar is defined which may not extend to all future dates
 	// but is critical for historic reasons.
+	//
+	// DSTPolicy controls triggers whose wall clock time is skipped or repeated by
+	// daylight saving transitions in Timezone: run-once (default), run-twice, skip
+	// or run-at-shift.  e.g. a 02:30 America/New_York job under run-once runs at 03:30 on
+	// the spring forward date and only once on the fall back date.
 	Timezone     *string   `json:"Timezone,omitempty"`
 	Calendar     *string   `json:"Calendar,omitempty"`
 	CalendarDirs *[]string `json:"CalendarDirs,omitempty"`
 	Rollback     *bool     `json:"Rollback,omitempty"`
+	CalAdjust    *string   `json:"CalAdjust,omitempty"`
 	RequireCal   *bool     `json:"RequireCal,omitempty"`
+	DSTPolicy    *string   `json:"DSTPolicy,omitempty"`
 
 	// Start, Stop and Restart triggers and concurrency rule
 	//
@@ -308,6 +371,11 @@ type JobSpec struct {
 	// all stardard cron-style shortcuts are available and *-style specification allows
 	// 5 (minute resolutio) or 6 (second resolution)
 	//
+	// Day fields additionally accept names (MON-FRI, JAN,APR), ? and the L, W and # forms
+	//   CronStart: ["0 30 16 ? * 5L"]     ## last Friday of the month (in Calendar if set) at 16:30
+	//   CronStart: ["0 9 15W * *"]        ## weekday nearest the 15th at 09:00
+	//   CronStart: ["0 9 ? * MON#2"]      ## second Monday of the month at 09:00
+	//
 	// StartDay, StartTime, EndDay, and EndTime offer a more human reasable option
 	// for specifying triggers
 	//
@@ -324,19 +392,55 @@ type JobSpec struct {
 	// define a CronStart which triggers the job. The dependency is not used to trigger.
 	//
 	// StartRule controls concurrency - allowing for either NoStart (ignore next trigger if running),
-	// Start (start new job, leaving current running - NOT YET IMPLEMENTED) or Restart (trigger end
-	// of running jobs and start a new job)
+	// Start (start new job, leaving current running) or Restart (trigger end of running jobs and
+	// start a new job)
+	//
+	// MaxInstances limits the number of concurrently running instances with StartRule "Start",
+	// with triggers ignored while at the limit. The default 0 is unlimited. Each instance has
+	// its own RunUUID, logs and History entry, can be stopped individually using its RunUUID,
+	// and MaxDuration and Retry apply to each instance separately
+	//
+	// Pool names a pool defined in ServerConfig.Pools, with PoolSlots (default 1) the number
+	// of its slots each run requires. Triggered jobs are queued (JQueued) until slots are free
+	//
+	// Priority (default 0, higher first) orders jobs waiting for slots of a Pool or of
+	// JobsControl.MaxConcurrent, including jobs triggered together by a QueueJobs dependency.
+	// The effective priority of a waiting job rises by one for each ServerConfig.PriorityAging
+	// (default 5m) waited, so low priority jobs are run eventually
 	//
 	// Jitter: ADD uniform (max) random seconds to CronStart
-	CronStart   *[]string `json:"CronStart,omitempty" xml:"CronStart,omitempty"`
-	CronEnd     *[]string `json:"CronEnd,omitempty" xml:"CronEnd,omitempty"`
-	CronRestart *string   `json:"CronRestart,omitempty"`
-	StartDay    *string   `json:"StartDay,omitempty"`
-	StartTime   *string   `json:"StartTime,omitempty"`
-	EndDay      *string   `json:"EndDay,omitempty"`
-	EndTime     *string   `json:"EndTime,omitempty"`
-	StartRule   *string   `json:"StartRule,omitempty"`
-	Jitter      *int      `json:"Jitter,omitempty"`
+	//
+	// RRStart, RREnd and RRRestart are arrays of RFC 5545 (iCalendar) recurrence rules
+	// which are evaluated together with CronStart, CronEnd and CronRestart respectively,
+	// with the earliest trigger used. Timezone, Calendar, Rollback and RequireCal
+	// apply as they do to cron rules.
+	//
+	// e.g.
+	//   RRStart: ["DTSTART:20240109T090000 RRULE:FREQ=MONTHLY;INTERVAL=3;BYDAY=2TU"]  ## second Tuesday of each quarter
+	//   RRStart: ["DTSTART:20240105T170000 RRULE:FREQ=WEEKLY;INTERVAL=3"]           ## every 3 weeks from Jan 5th, 2024
+	//
+	// CatchUp controls CronStart triggers missed while the server was down or
+	// skipped by a jump in the system clock: none (default), latest, all or
+	// all-within-duration, with CatchUpWithin limiting the latter (e.g. "48h").
+	// Missed runs use the missed trigger time as the asof time for DateEnv. See CatchUpPolicy
+	CronStart     *[]string `json:"CronStart,omitempty" xml:"CronStart,omitempty"`
+	CronEnd       *[]string `json:"CronEnd,omitempty" xml:"CronEnd,omitempty"`
+	CronRestart   *string   `json:"CronRestart,omitempty"`
+	RRStart       *[]string `json:"RRStart,omitempty" xml:"RRStart,omitempty"`
+	RREnd         *[]string `json:"RREnd,omitempty" xml:"RREnd,omitempty"`
+	RRRestart     *[]string `json:"RRRestart,omitempty" xml:"RRRestart,omitempty"`
+	StartDay      *string   `json:"StartDay,omitempty"`
+	StartTime     *string   `json:"StartTime,omitempty"`
+	EndDay        *string   `json:"EndDay,omitempty"`
+	EndTime       *string   `json:"EndTime,omitempty"`
+	StartRule     *string   `json:"StartRule,omitempty"`
+	MaxInstances  *int      `json:"MaxInstances,omitempty"`
+	Pool          *string   `json:"Pool,omitempty"`
+	PoolSlots     *int      `json:"PoolSlots,omitempty"`
+	Priority      *int      `json:"Priority,omitempty"`
+	Jitter        *int      `json:"Jitter,omitempty"`
+	CatchUp       *string   `json:"CatchUp,omitempty"`
+	CatchUpWithin *string   `json:"CatchUpWithin,omitempty"`
 
 	// Control parameters for runtime exceptions
 	//
@@ -350,7 +454,9 @@ type JobSpec struct {
 	// RetryWait may be a comma-delimted string to different durations for each retry, with final duration used
 	// for any remaining tries.
 	//
-	// MinRuntime, MaxRuntime will signal a JWarning if below or above time, respectively (NOT YET IMPLEMENTED)
+	// MinRuntime and MaxRuntime signal a warning without ending the job. A successful run shorter than
+	// MinRuntime completes as JWarning, while a run still running after MaxRuntime moves to JWarning2
+	// until it completes. Either triggers OnWarning alerts and records the Warning in History
 	Retry       *int    `json:"Retry,omitempty"`
 	RetryWait   *string `json:"RetryWait,omitempty"`   // e.g. "11s", "3m" or "30s,5m,60m" - used in time.ParseDuration
 	RetryReset  *string `json:"RetryReset,omitempty"`  // how long before a failed job is re-enabled "e.g. "12h" or "0s" - used in time.ParseDuration"
@@ -405,6 +511,14 @@ type JobSpec struct {
 	Permissions *Permission `json:"Permissions,omitempty"`
 	Admin       *[]string   `json:"Admin,omitempty" xml:"Admin,omitempty"`
 
+	// RunAs is the OS user, group and supplementary groups the process of the job is
+	// run as, which User must be granted in the auth file. See RunAs
+	RunAs *RunAs `json:"RunAs,omitempty" xml:"RunAs,omitempty"`
+
+	// Limits constrains the memory, CPU, open files, processes and scheduling priority
+	// of the process of the job. See Limits
+	Limits *Limits `json:"Limits,omitempty" xml:"Limits,omitempty"`
+
 	//JobUUID uuid.UUID  `json:"JobUUID---INTERNAL-DO-NOT-EDIT" xml:"JobUUID---INTERNAL-DO-NOT-EDIT"`// exported to be able to write to file DO NOT EDIT
 	// JobUUID is an internal value associated with a job once run for the first time. It should not be
 	// edited as it links a unique job with its history and potentially with Dependencies
@@ -424,6 +538,7 @@ type JobsControl struct {
 	MaxFailures   int
 
 	nfailures int
+	pool      *Pool // MaxConcurrent, see Pool
 	lock      sync.Mutex
 }
 
@@ -467,11 +582,21 @@ type Job struct {
 	ShutdownSig     string       `json:"ShutdownSig,omitempty"`
 	Jobs            []JobSpec    `json:"Jobs,omitempty"`
 	JobsControl     *JobsControl `json:"JobsControl,omitempty"`
+	Matrix          *Matrix      `json:"Matrix,omitempty"`
+	Parent          string       `json:"Parent,omitempty"` // Name of the Matrix job a child was expanded from
 	Shell           string       `json:"Shell,omitempty"`
+	Script          string       `json:"Script,omitempty"`
+	WorkDir         string       `json:"WorkDir,omitempty"`
+	CreateWorkDir   bool         `json:"CreateWorkDir,omitempty"`
+	Umask           string       `json:"Umask,omitempty"`
+	Stdin           string       `json:"Stdin,omitempty"`
 	Env             EnvList
 	DateEnv         EnvList
 	LocalEnv        EnvList
 	LocalDateEnv    EnvList
+	FiscalYearStart string  `json:"FiscalYearStart,omitempty"`
+	DateEnvAsOf     string  `json:"DateEnvAsOf,omitempty"`
+	Params          []Param `json:"Params,omitempty"`
 
 	ExitState    ExitState    `json:"ExitState,omitempy"`
 	AlertActions AlertActions `json:"AlertActions,omitempty"`
@@ -480,7 +605,9 @@ type Job struct {
 	Calendar       string   `json:"Calendar,omitempty"`
 	CalendarDirs   []string `json:"CalendarDirs,omitempty"`
 	Rollback       bool     `json:"Rollback,omitempty"`
+	CalAdjust      string   `json:"CalAdjust,omitempty"`
 	RequireCal     bool     `json:"RequireCal,omitempty"`
+	DSTPolicy      string   `json:"DSTPolicy,omitempty"`
 	StartDay       string   `json:"StartDay,omitempty"`
 	StartTime      string   `json:"StartTime,omitempty"`
 	EndDay         string   `json:"EndDay,omitempty"`
@@ -491,10 +618,16 @@ type Job struct {
 	CronEndArray   []string `json:"CronEndArray,omitempty"`
 	CronRestart    *string  `json:"CronRestart,omitempty"`
 	StartRule      string   `json:"StartRule,omitempty"` // "Restart", "Start", "NoStart"
+	MaxInstances   int      `json:"MaxInstances,omitempty"`
+	Pool           string   `json:"Pool,omitempty"`
+	PoolSlots      int      `json:"PoolSlots,omitempty"`
+	Priority       int      `json:"Priority,omitempty"`
 	Jitter         int      `json:"Jitter,omitempty"`
-	//RRStart RRule `json:"RRStart"`        //  RRULE or ISO8601
-	//RREnd RRule `json:"RREnd"`
-	//RRRestart RRule `json:"RRRestart"`
+	RRStart        []string `json:"RRStart,omitempty"` // RFC 5545 "DTSTART:20240109T090000 RRULE:FREQ=MONTHLY;BYDAY=2TU"
+	RREnd          []string `json:"RREnd,omitempty"`
+	RRRestart      []string `json:"RRRestart,omitempty"`
+	CatchUp        string   `json:"CatchUp,omitempty"`
+	CatchUpWithin  string   `json:"CatchUpWithin,omitempty"`
 
 	Dependency []Dependency `json:"Dependency,omitempty"`
 	Artifacts  Artifacts    `json:"Artifacts,omitempty"`
@@ -522,6 +655,9 @@ type Job struct {
 	Permissions Permission `json:"Permissions,omitempty"`
 	Group       []string   `json:"Group,omitempty"`
 	Admin       []string   `json:"Admin,omitempty"`
+	RunAs       *RunAs     `json:"RunAs,omitempty"`
+	runAsErr    error      // RunAs not granted to User, see allowedRunAs
+	Limits      *Limits    `json:"Limits,omitempty"`
 	//Concurrent bool
 	MsgC chan *Signal `json:"-"`
 	Ctl  chan *Ctl    `json:"-"`
@@ -530,6 +666,16 @@ type Job struct {
 	ServerName         string       `json:"-"`
 	ServerKey          string       `json:"-"`
 	Pid                int          `json:"Pid,omitempty"`
+	Instances          int          `json:"Instances,omitempty"`     // running instances with StartRule "Start"
+	Warning            string       `json:"Warning,omitempty"`       // MinRuntime or MaxRuntime warning of the latest run
+	Blackout           string       `json:"Blackout,omitempty"`      // Blackout window deferring or skipping the latest trigger
+	ConfigError        string       `json:"ConfigError,omitempty"`   // calendar error preventing the job being scheduled
+	LimitExceeded      string       `json:"LimitExceeded,omitempty"` // Limits exceeded by the latest run
+	Usage              *Usage       `json:"Usage,omitempty"`         // resource usage of the latest run
+	Stats              JobStats     `json:"Stats"`
+	QueuePosition      int          `json:"QueuePosition,omitempty"`
+	QueuedUNIX         int64        `json:"QueuedUNIX,omitempty"`
+	EffectivePriority  int          `json:"EffectivePriority,omitempty"` // Priority with aging while queued
 	Unscheduled        bool         `json:"Unscheduled,omitempty"`
 	Restarting         bool         `json:"Restarting,omitempty"`
 	IsRunning          bool         `json:"IsRunning,omitempty"`
@@ -558,36 +704,51 @@ type Job struct {
 	StderrFile    []string
 
 	// private members
-	lock           sync.Mutex       `json:"-"`
-	runlock        sync.Mutex       `json:"-"`
-	status         chan int         `json:"-"`
-	signal         chan int         `json:"-"`
-	pid            int              `json:"-"`
-	proc           *os.Process      `json:"-"`
-	procState      *os.ProcessState `json:"-"`
-	_location      *time.Location   `json:"-"`
-	cronStart      Cron             `json:"-"`
-	cronStartArray []Cron           `json:"-"`
-	cronEnd        Cron             `json:"-"`
-	cronEndArray   []Cron           `json:"-"`
-	cronRestart    Cron             `json:"-"`
-	startRule      StartRule        `json:"-"`
+	lock             sync.Mutex       `json:"-"`
+	runlock          sync.Mutex       `json:"-"`
+	evalLock         sync.Mutex       `json:"-"` // Cmd evaluation outside of runTik
+	backfillLock     sync.Mutex       `json:"-"` // one Backfill of the job at a time
+	status           chan int         `json:"-"`
+	signal           chan int         `json:"-"`
+	pid              int              `json:"-"`
+	proc             *os.Process      `json:"-"`
+	procState        *os.ProcessState `json:"-"`
+	_location        *time.Location   `json:"-"`
+	cronStart        Cron             `json:"-"`
+	cronStartArray   []Cron           `json:"-"`
+	cronEnd          Cron             `json:"-"`
+	cronEndArray     []Cron           `json:"-"`
+	cronRestart      Cron             `json:"-"`
+	cronRestartArray []Cron           `json:"-"`
+	startRule        StartRule        `json:"-"`
 	//lastRun time.Time `json:"-"`
-	prevStart time.Time       `json:"-"`
-	prevStop  time.Time       `json:"-"`
-	elapsed   time.Duration   `json:"-"`
-	modified  int64           `json:"-"`
-	updates   chan *JobUpdate `json:"-"`
-	jobstatus chan *JobStatus `json:"-"`
-	state     chan *depEvt    `json:"-"`
-	Version   string          `json:"Version"`
-	src       string
-	authKey   string
-	apiKey    string
-	jve       JobValidationExceptions
-	t         *time.Timer `json:"-"`
-	te        *time.Timer `json:"-"`
-	th        *time.Timer `json:"-"` // hold reset timer
+	prevStart     time.Time         `json:"-"`
+	prevStop      time.Time         `json:"-"`
+	asof          string            `json:"-"` // asof time of current run, set when catching up missed triggers
+	params        map[string]string `json:"-"` // Params overridden by a manual start of the current run
+	runs          []*JobRun         `json:"-"`
+	queueCancel   chan bool         `json:"-"`
+	slots         []heldSlots       `json:"-"` // pool slots held by the current triggered run
+	missed        []time.Time       `json:"-"`
+	scheduled     time.Time         `json:"-"` // next trigger before Jitter, see triggerAsOf
+	catchUp       CatchUpPolicy     `json:"-"`
+	dateEnvAsOf   AsOfPolicy        `json:"-"`
+	catchUpWithin time.Duration     `json:"-"`
+	minRuntime    time.Duration     `json:"-"`
+	maxRuntime    time.Duration     `json:"-"`
+	elapsed       time.Duration     `json:"-"`
+	modified      int64             `json:"-"`
+	updates       chan *JobUpdate   `json:"-"`
+	jobstatus     chan *JobStatus   `json:"-"`
+	state         chan *depEvt      `json:"-"`
+	Version       string            `json:"Version"`
+	src           string
+	authKey       string
+	apiKey        string
+	jve           JobValidationExceptions
+	t             *time.Timer `json:"-"`
+	te            *time.Timer `json:"-"`
+	th            *time.Timer `json:"-"` // hold reset timer
 }
 
 type ExitStateMap map[int]JState
@@ -623,7 +784,8 @@ type JobLogging struct {
 	l           *time.Timer
 }
 
-// JobStats tracks execution statistics
+// JobStats tracks execution statistics. Elapsed (seconds), Memory (max RSS in bytes)
+// and CPU (user and system seconds) are of the latest run, see Usage
 type JobStats struct {
 	Elapsed                                                 int64
 	Triggers, Manual, Stopped, Success, Failed, RetryFailed int
@@ -645,31 +807,31 @@ type JobHistory struct {
 	Stderr         string
 	Unscheduled    bool
 	Reason         Reason
+
+	// Backfill runs are replays of the job for a historical AsOf
+	// (YYYYMMDDhhmmss) time. AsOf is also set for CatchUp runs
+	Backfill bool
+	AsOf     string
+
+	// Params overridden by a manual start
+	Params map[string]string `json:"Params,omitempty"`
+
+	// MinRuntime or MaxRuntime warning
+	Warning string `json:"Warning,omitempty"`
+
+	// Limits exceeded by the run
+	LimitExceeded string `json:"LimitExceeded,omitempty"`
+
+	// resource usage of the run
+	Usage *Usage `json:"Usage,omitempty"`
 }
 
 func (job *Job) addHistory() {
-	var kabb string
-	switch job.JobState {
-	case JSuccess:
-		kabb = "S"
-	case JFailed:
-		kabb = "F"
-	case JRetryFailed:
-		kabb = "R"
-	case JEnd:
-		kabb = "E"
-	case JStopped:
-		kabb = "s"
-	case JWarning:
-		kabb = "W"
-	case JHold:
-		kabb = "H"
-	}
 	jh := JobHistory{
 		RunUUID:        job.RunUUID.String(),
 		ExitCode:       job.ExitCode,
 		JobStateString: job.JobStateString,
-		JobStateAbb:    kabb,
+		JobStateAbb:    historyAbb(job.JobState),
 		RetryAttempt:   job.RetryAttempt,
 		Start:          job.Started,
 		StartUNIX:      job.StartedUNIX,
@@ -680,10 +842,18 @@ func (job *Job) addHistory() {
 		CmdEval:        job.CmdEval,
 		Unscheduled:    job.Unscheduled,
 		Reason:         job.Reason,
+		AsOf:           job.asof,
+		Params:         job.params,
+		Warning:        job.Warning,
+		LimitExceeded:  job.LimitExceeded,
+		Usage:          job.Usage,
 		//CronStart:job.CronStart,
 		//CronEnd:job.CronEnd,
 		//CronRestart:job.CronRestart,
 	}
+	job.prependHistory(jh)
+}
+func (job *Job) prependHistory(jh JobHistory) {
 	//job.FullHistory = append([]JobHistory{jh}, job.FullHistory...)
 	job.History = append([]JobHistory{jh}, job.History...)
 	//job.History = append(job.History, jh)
@@ -692,13 +862,40 @@ func (job *Job) addHistory() {
 		job.History = job.History[:len(job.History)-1]
 	}
 }
+func historyAbb(s JState) string {
+	var kabb string
+	switch s {
+	case JSuccess:
+		kabb = "S"
+	case JFailed:
+		kabb = "F"
+	case JRetryFailed:
+		kabb = "R"
+	case JEnd:
+		kabb = "E"
+	case JStopped:
+		kabb = "s"
+	case JWarning:
+		kabb = "W"
+	case JHold:
+		kabb = "H"
+	case JBlackout:
+		kabb = "B"
+	case JLimitExceeded:
+		kabb = "L"
+	}
+	return kabb
+}
 func (h JobHistory) isNull() bool {
 	return h.RunUUID == "00000000-0000-0000-0000-000000000000"
 }
 /* TODO: getHistory */
 func (job *Job) getLogs(runid string) (stdout string, stderr string) {
 	ServerLogger.Printf("[ getLogging for %s ] %s", runid, job.JobUUID)
-	if runid == job.RunUUID.String() {
+	if run := job.getRun(runid); run != nil {
+		stdout = run.Stdout
+		stderr = run.Stderr
+	} else if runid == job.RunUUID.String() {
 		stdout = job.Logging.stdoutFile
 		stderr = job.Logging.stderrFile
 	} else {
@@ -923,6 +1120,15 @@ type JobUpdateParams struct {
 	Retry              *int
 	RetryAttempt       *int
 	Pid                *int
+	Instances          *int
+	Warning            *string
+	Blackout           *string
+	ConfigError        *string
+	LimitExceeded      *string
+	QueuePosition      *int
+	QueuedUNIX         *int64
+	Priority           *int
+	EffectivePriority  *int
 	Unscheduled        *bool
 	Reason             *Reason
 	Controls           *[]string
@@ -937,20 +1143,27 @@ type JobStatus struct {
 	Job      JobStatusParams
 }
 type JobStatusParams struct {
-	Name           string
-	JobUUID        uuid.UUID
-	RunUUID        uuid.UUID
-	PrevStop       string
-	PrevStopUNIX   int64
-	Elapsed        string
-	ElapsedUNIX    int64
-	Started        string
-	StartedUNIX    int64
-	NextStartUNIX  int64
-	NextStart      string
-	JobState       JState
-	JobStateString string
-	Updating       bool
+	Name              string
+	JobUUID           uuid.UUID
+	RunUUID           uuid.UUID
+	PrevStop          string
+	PrevStopUNIX      int64
+	Elapsed           string
+	ElapsedUNIX       int64
+	Started           string
+	StartedUNIX       int64
+	NextStartUNIX     int64
+	NextStart         string
+	JobState          JState
+	JobStateString    string
+	Updating          bool
+	Priority          int    `json:"Priority,omitempty"`
+	QueuePosition     int    `json:"QueuePosition,omitempty"`
+	EffectivePriority int    `json:"EffectivePriority,omitempty"`
+	QueueWait         string `json:"QueueWait,omitempty"`
+	Blackout          string `json:"Blackout,omitempty"`
+	ConfigError       string `json:"ConfigError,omitempty"`
+	LimitExceeded     string `json:"LimitExceeded,omitempty"`
 }
 
 func (job *Job) availableControls() []string {
@@ -965,7 +1178,7 @@ func (job *Job) availableControls() []string {
 		}
 	} else {
 		switch job.JobState {
-		case JHold, JMissedWarning, JMissedError, JWarning, JWarning2, JWarning3, JDepWarning:
+		case JHold, JMissedWarning, JMissedError, JWarning, JWarning3, JDepWarning, JBlackout:
 			if job.getHold() {
 				controls = []string{"hold", "info"}
 			} else {
@@ -973,9 +1186,14 @@ func (job *Job) availableControls() []string {
 			}
 		case JRetryWait, JDepRetry:
 			controls = []string{"stop", "start"}
-		case JRunning:
+		case JQueued:
+			controls = []string{"stop", "hold", "info"}
+		case JRunning, JWarning2:
 			controls = []string{"stop", "restart", "info"}
-		case JStopped, JFailed, JRetryFailed, JDepFailed:
+			if job.canStartInstance() {
+				controls = []string{"stop", "start", "restart", "info"}
+			}
+		case JStopped, JFailed, JRetryFailed, JDepFailed, JLimitExceeded:
 			controls = []string{"hold", "info"}
 		case JReady, JSuccess, JManualSuccess, JEnd:
 			controls = []string{"start", "hold", "info"}
@@ -1017,6 +1235,15 @@ func (job *Job) updateParams() *JobUpdateParams {
 		Retry:              &job.Retry,
 		RetryAttempt:       &job.RetryAttempt,
 		Pid:                &job.Pid,
+		Instances:          &job.Instances,
+		Warning:            &job.Warning,
+		Blackout:           &job.Blackout,
+		ConfigError:        &job.ConfigError,
+		LimitExceeded:      &job.LimitExceeded,
+		QueuePosition:      &job.QueuePosition,
+		QueuedUNIX:         &job.QueuedUNIX,
+		Priority:           &job.Priority,
+		EffectivePriority:  &job.EffectivePriority,
 		Unscheduled:        &job.Unscheduled,
 		Reason:             &job.Reason,
 		Controls:           &controls,
@@ -1040,6 +1267,15 @@ func (job *Job) statusParams() *JobStatusParams {
 		JobState:       job.JobState,
 		JobStateString: job.JobStateString,
 		Updating:       job.Updating,
+		Priority:       job.Priority,
+		QueuePosition:  job.QueuePosition,
+		Blackout:       job.Blackout,
+		ConfigError:    job.ConfigError,
+		LimitExceeded:  job.LimitExceeded,
+	}
+	if job.QueuedUNIX > 0 {
+		params.EffectivePriority = job.EffectivePriority
+		params.QueueWait = dhms(time.Since(time.Unix(job.QueuedUNIX, 0)).Round(time.Second))
 	}
 	return &params
 }
@@ -1057,7 +1293,7 @@ func (job *Job) sendUpdate() {
 	// websocket clients
 	job.updates <- &JobUpdate{Uuid: job.JobUUID.String(), Modified: job.modified, Job: *job.updateParams(), Tzoffset: tzoffset, Tzname: tzname}
 	// dependency clients
-	job.state <- &depEvt{JobUUID: job.JobUUID, Name: job.Name, JobState: job.JobState}
+	job.state <- &depEvt{JobUUID: job.JobUUID, RunUUID: job.RunUUID, Name: job.Name, JobState: job.JobState}
 	// alert API
 	if job.HasAlerts() {
 		go job.sendAlert()
@@ -1074,11 +1310,12 @@ func (job *Job) isValidJobStateChange(s JState) bool {
 	var isValid bool
 	switch s {
 	case JRestart:
-		if job.JobState == JRunning {
+		if job.JobState == JRunning || job.JobState == JWarning2 {
 			isValid = true
 		}
-	case JRunning, JManual: // Start
+	case JRunning, JManual, JQueued: // Start
 		if job.JobState == JStopped ||
+			job.JobState == JQueued ||
 			job.JobState == JSuccess ||
 			job.JobState == JManualSuccess ||
 			job.JobState == JReady ||
@@ -1097,25 +1334,39 @@ func (job *Job) isValidJobStateChange(s JState) bool {
 			job.JobState == JDepRetry ||
 			job.JobState == JDepFailed ||
 			job.JobState == JMissedError ||
-			job.JobState == JMissedWarning {
+			job.JobState == JMissedWarning ||
+			job.JobState == JBlackout ||
+			job.JobState == JLimitExceeded {
 			isValid = true
 		}
 	case JStopping:
-		if job.JobState == JRunning || job.JobState == JRetrying {
+		if job.JobState == JRunning || job.JobState == JWarning2 || job.JobState == JRetrying {
 			isValid = true
 		}
 	case JStopped, JSuccess, JEnd, JManualSuccess: // Stop, Success, End
-		if job.JobState == JStopping || job.JobState == JRunning || job.JobState == JManual || job.JobState == JRetrying || job.JobState == JRetryWait || job.JobState == JRestart ||
+		if job.JobState == JStopping || job.JobState == JQueued || job.JobState == JRunning || job.JobState == JWarning2 || job.JobState == JManual || job.JobState == JRetrying || job.JobState == JRetryWait || job.JobState == JRestart ||
 			job.JobState == JReady || job.JobState == JSuccess || job.JobState == JEnd || job.JobState == JManualSuccess {
 			isValid = true
 		}
 	case JFailed:
-		if job.JobState == JReady || job.JobState == JRunning || job.JobState == JStopping || job.JobState == JRetryFailed || job.JobState == JRetryWait {
+		if job.JobState == JReady || job.JobState == JRunning || job.JobState == JWarning2 || job.JobState == JStopping || job.JobState == JRetryFailed || job.JobState == JRetryWait {
 			// RetryWait is here to catch fork/exec failures with retries as they are not controlled well enough yet
 			isValid = true
 		}
+	case JLimitExceeded:
+		if job.JobState == JRunning || job.JobState == JWarning2 || job.JobState == JStopping {
+			isValid = true
+		}
 	case JRetryFailed:
-		if job.JobState == JRetrying || job.JobState == JRunning || job.JobState == JFailed || job.JobState == JStopping {
+		if job.JobState == JRetrying || job.JobState == JRunning || job.JobState == JWarning2 || job.JobState == JFailed || job.JobState == JStopping {
+			isValid = true
+		}
+	case JWarning: // MinRuntime
+		if job.JobState == JRunning || job.JobState == JWarning2 {
+			isValid = true
+		}
+	case JWarning2: // MaxRuntime
+		if job.JobState == JRunning {
 			isValid = true
 		}
 	case JRetrying, JRetryWait:
@@ -1124,6 +1375,7 @@ func (job *Job) isValidJobStateChange(s JState) bool {
 		}
 	case JHold, JReady, JContingent:
 		if job.JobState == JHold ||
+			job.JobState == JQueued ||
 			job.JobState == JReady ||
 			job.JobState == JContingent ||
 			job.JobState == JRetryFailed ||
@@ -1138,15 +1390,18 @@ func (job *Job) isValidJobStateChange(s JState) bool {
 			job.JobState == JWarning3 ||
 			job.JobState == JMissedError ||
 			job.JobState == JMissedWarning ||
+			job.JobState == JBlackout ||
+			job.JobState == JLimitExceeded ||
+			job.JobState == JConfigError ||
 			job.JobState == JDepWarning ||
 			job.JobState == JDepFailed ||
 			job.JobState == JUnknown {
 			isValid = true
 		}
-	case JReset, JMissedError, JMissedWarning, JDepWarning, JDepRetry, JDepFailed:
+	case JReset, JMissedError, JMissedWarning, JDepWarning, JDepRetry, JDepFailed, JBlackout, JConfigError:
 		isValid = true
 	case JUnknown:
-		if job.JobState == JRunning || job.JobState == JRetrying {
+		if job.JobState == JRunning || job.JobState == JWarning2 || job.JobState == JRetrying {
 			isValid = true
 		}
 	default:
@@ -1182,6 +1437,8 @@ func (job *Job) setJobState(s JState) error {
 			time.AfterFunc(d, func() { job.setHold(false); job.setJobState(JReady); job.sendUpdate() })
 		}
 		job.addHistory()
+	case JBlackout:
+		job.addHistory()
 	case JMissedWarning:
 		ServerLogger.Printf("checking for MissedReset: %s", job.MissedReset)
         //_, filename, line, _ := runtime.Caller(1)
@@ -1219,7 +1476,7 @@ func (job *Job) setJobState(s JState) error {
 			ServerLogger.Printf("no hold release on", job.JobUUID)
 		}
 		job.addHistory()
-	case JSuccess, JManualSuccess, JEnd, JRetryFailed, JDepWarning, JDepFailed, JStopped:
+	case JSuccess, JManualSuccess, JEnd, JRetryFailed, JDepWarning, JDepFailed, JStopped, JWarning, JLimitExceeded:
 		job.addHistory()
 	}
 	job.SaveSnapshot(true)
@@ -1236,10 +1493,17 @@ func (job *Job) WaitForTrigger(stop <-chan bool) error {
 		case <-ticker.C:
 			now := time.Now().Unix()
 			if now-lastTick > 30 {
-				// Skip setting the job as missed here
-				// TODO: might want to add some logging here
-				//job.setJobState(JMissedWarning)
-				return nil
+				// clock jumped (e.g. suspend or time change) - CronStart triggers
+				// in between were not seen by the timer
+				ServerLogger.Printf("Clock jump of %s detected for %s:%s", time.Duration(now-lastTick)*time.Second, job.JobUUID, job.Name)
+				if job.JobState != JRetryWait {
+					if job.queueMissed(time.Unix(lastTick, 0), time.Unix(now, 0)) > 0 {
+						return nil
+					}
+					d, next, scheduled := NextCronSchedule(job.cronStartArray)
+					job.resetTimer(d)
+					job.setNextStart(next, scheduled)
+				}
 			}
 			lastTick = now
 		case <-job.t.C:
@@ -1280,10 +1544,14 @@ func (job *Job) setContingent(h bool) {
 	job.cronStart.Contingent = true
 	job.modified = time.Now().Unix()
 }
-func (job *Job) setNextStart(next time.Time) {
+
+// setNextStart sets the next trigger, and the time it is scheduled for before Jitter
+func (job *Job) setNextStart(next, scheduled time.Time) {
 	job.Lock()
 	defer job.Unlock()
 
+	job.scheduled = scheduled
+
 	loc, _ := time.LoadLocation(job.Timezone)
 
 	//var ns_notz string
@@ -1341,6 +1609,36 @@ func (job *Job) getMaxDuration() time.Duration {
 	d, _ := time.ParseDuration(job.MaxDuration)
 	return d
 }
+
+// minRuntimeWarning returns a warning if a successful run of elapsed is shorter than MinRuntime
+func (job *Job) minRuntimeWarning(elapsed time.Duration) string {
+	if job.minRuntime > 0 && elapsed < job.minRuntime {
+		return fmt.Sprintf("runtime %s below MinRuntime %s", elapsed.Round(time.Second), job.MinRuntime)
+	}
+	return ""
+}
+
+// maxRuntimeWarning moves a job still running after MaxRuntime to JWarning2, calling
+// warn with the warning before the state change. The returned timer is nil if
+// MaxRuntime is not set, otherwise it should be stopped once the run completes
+func (job *Job) maxRuntimeWarning(warn func(string)) *time.Timer {
+	if job.maxRuntime <= 0 {
+		return nil
+	}
+	return time.AfterFunc(job.maxRuntime, func() {
+		w := fmt.Sprintf("runtime exceeded MaxRuntime %s", job.MaxRuntime)
+		ServerLogger.Printf("[MaxRuntime] %s:%s %s", job.JobUUID, job.Name, w)
+		job.Lock()
+		job.Warning = w
+		job.Unlock()
+		if warn != nil {
+			warn(w)
+		}
+		if job.setJobState(JWarning2) == nil {
+			job.sendUpdate()
+		}
+	})
+}
 func (job *Job) onNextStart() onStart {
 	var onstart onStart
 	switch job.StartRule {
@@ -1379,6 +1677,9 @@ const (
 
 func (ct CronType) String() string {
 	names := [...]string{"CronStart", "CronEnd", "CronRestart"}
+	if ct < CronStart || ct > CronRestart {
+		return "Cron"
+	}
 	return names[ct-1]
 }
 
@@ -0,0 +1,328 @@
+package rpeat
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Limits constrains the resources used by the process of a job and its children, e.g.
+//
+//	"Limits": {
+//	  "MaxRSS": "2G", "CPUSeconds": 3600, "OpenFiles": 4096, "Processes": 256,
+//	  "Nice": 10, "IONice": "best-effort:7", "CPUMax": "50%", "MemoryMax": "4G"
+//	}
+//
+// CPUSeconds, OpenFiles and Processes are set as RLIMIT_CPU, RLIMIT_NOFILE and RLIMIT_NPROC
+// of the process as it starts and inherited by its children. Processes counts all
+// processes of the user the job runs as (see RunAs) and does not apply to root. MaxRSS and
+// CPUSeconds are also checked every second against the total resident memory and CPU time
+// of the processes of the job, which are killed if either is exceeded. Nice (-20 to 19)
+// and IONice (idle, best-effort[:0-7] or realtime[:0-7]) set the CPU and I/O scheduling
+// priority. Sizes are in bytes with an optional K, M, G or T suffix. The rlimits, Nice and
+// IONice are applied by starting the run as the rpeat-server executable, which with RunAs
+// must be executable by the RunAs user.
+//
+// CPUMax and MemoryMax are written to cpu.max and memory.max of a cgroup v2 created for
+// each run under CgroupRoot of the server, a delegated cgroup directory with the cpu and
+// memory controllers enabled in its cgroup.subtree_control. CPUMax is a percentage of one
+// CPU, e.g. "150%", or "QUOTA PERIOD" in microseconds. Without a CgroupRoot they are not
+// applied and a warning is logged.
+//
+// A run killed for exceeding MaxRSS, CPUSeconds or MemoryMax ends in the JLimitExceeded
+// state rather than JFailed, with the limit recorded in LimitExceeded of the job and its
+// History. Such runs are not retried, and otherwise are handled as a failure by
+// dependencies, Jobs and OnFailure alerts. Limits are only supported on linux.
+type Limits struct {
+	MaxRSS     string `json:"MaxRSS,omitempty"`
+	CPUSeconds int    `json:"CPUSeconds,omitempty"`
+	OpenFiles  int    `json:"OpenFiles,omitempty"`
+	Processes  int    `json:"Processes,omitempty"`
+	Nice       int    `json:"Nice,omitempty"`
+	IONice     string `json:"IONice,omitempty"`
+	CPUMax     string `json:"CPUMax,omitempty"`
+	MemoryMax  string `json:"MemoryMax,omitempty"`
+}
+
+// CgroupRoot is the delegated cgroup v2 directory runs with CPUMax or MemoryMax are placed
+// under, set from ServerConfig.CgroupRoot
+var CgroupRoot string
+
+// SetCgroupRoot sets CgroupRoot, checking it is a cgroup v2 directory with the cpu and
+// memory controllers enabled for its children
+func SetCgroupRoot(root string) error {
+	CgroupRoot = root
+	if root == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(filepath.Join(root, "cgroup.subtree_control"))
+	if err != nil {
+		return errors.New(fmt.Sprintf("CgroupRoot %s is not a cgroup v2 directory: %s", root, err))
+	}
+	for _, controller := range []string{"cpu", "memory"} {
+		if !stringInSlice(controller, strings.Fields(string(b))) {
+			return errors.New(fmt.Sprintf("CgroupRoot %s: %s controller is not enabled in cgroup.subtree_control", root, controller))
+		}
+	}
+	return nil
+}
+
+// limitInterval is how often MaxRSS and CPUSeconds are checked
+var limitInterval = time.Second
+
+var ioniceClasses = []string{"realtime", "best-effort", "idle"}
+
+// parseSize parses a size in bytes with an optional K, M, G or T suffix
+func parseSize(size string) (int64, error) {
+	s := strings.TrimSuffix(strings.TrimSpace(strings.ToUpper(size)), "B")
+	mult := int64(1)
+	if n := len(s); n > 0 {
+		if i := strings.IndexByte("KMGT", s[n-1]); i >= 0 {
+			mult = int64(1) << (10 * uint(i+1))
+			s = s[:n-1]
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, errors.New(fmt.Sprintf("invalid size %q", size))
+	}
+	return n * mult, nil
+}
+
+// parseIONice returns the ioprio class (1-3) and level of an IONice value
+func parseIONice(s string) (int, int, error) {
+	parts := strings.SplitN(s, ":", 2)
+	class := 0
+	for i, name := range ioniceClasses {
+		if parts[0] == name {
+			class = i + 1
+		}
+	}
+	if class == 0 {
+		return 0, 0, errors.New(fmt.Sprintf("IONice class %q must be one of %s", parts[0], strings.Join(ioniceClasses, ", ")))
+	}
+	level := 4
+	if len(parts) == 2 {
+		l, err := strconv.Atoi(parts[1])
+		if err != nil || l < 0 || l > 7 || class == 3 {
+			return 0, 0, errors.New(fmt.Sprintf("invalid IONice %q", s))
+		}
+		level = l
+	}
+	return class, level, nil
+}
+
+// parseCPUMax returns the cpu.max value of CPUMax
+func parseCPUMax(s string) (string, error) {
+	if strings.HasSuffix(s, "%") {
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		if err != nil || pct <= 0 {
+			return "", errors.New(fmt.Sprintf("invalid CPUMax %q", s))
+		}
+		return fmt.Sprintf("%d 100000", int64(pct*1000)), nil
+	}
+	f := strings.Fields(s)
+	if len(f) == 2 {
+		_, qerr := strconv.ParseInt(f[0], 10, 64)
+		_, perr := strconv.ParseInt(f[1], 10, 64)
+		if (qerr == nil || f[0] == "max") && perr == nil {
+			return s, nil
+		}
+	}
+	return "", errors.New(fmt.Sprintf("invalid CPUMax %q (expecting a percentage or \"QUOTA PERIOD\")", s))
+}
+
+// validate checks the values of l
+func (l *Limits) validate() error {
+	if l == nil {
+		return nil
+	}
+	if l.MaxRSS != "" {
+		if _, err := parseSize(l.MaxRSS); err != nil {
+			return errors.New(fmt.Sprintf("Limits MaxRSS: %s", err))
+		}
+	}
+	if l.MemoryMax != "" {
+		if _, err := parseSize(l.MemoryMax); err != nil {
+			return errors.New(fmt.Sprintf("Limits MemoryMax: %s", err))
+		}
+	}
+	if l.CPUSeconds < 0 || l.OpenFiles < 0 || l.Processes < 0 {
+		return errors.New("Limits CPUSeconds, OpenFiles and Processes must be positive")
+	}
+	if l.Nice < -20 || l.Nice > 19 {
+		return errors.New(fmt.Sprintf("Limits Nice %d must be between -20 and 19", l.Nice))
+	}
+	if l.IONice != "" {
+		if _, _, err := parseIONice(l.IONice); err != nil {
+			return errors.New(fmt.Sprintf("Limits %s", err))
+		}
+	}
+	if l.CPUMax != "" {
+		if _, err := parseCPUMax(l.CPUMax); err != nil {
+			return errors.New(fmt.Sprintf("Limits %s", err))
+		}
+	}
+	return nil
+}
+
+func (l *Limits) String() string {
+	var s []string
+	for _, v := range []struct {
+		name, value string
+	}{
+		{"MaxRSS", l.MaxRSS},
+		{"CPUSeconds", strconv.Itoa(l.CPUSeconds)},
+		{"OpenFiles", strconv.Itoa(l.OpenFiles)},
+		{"Processes", strconv.Itoa(l.Processes)},
+		{"Nice", strconv.Itoa(l.Nice)},
+		{"IONice", l.IONice},
+		{"CPUMax", l.CPUMax},
+		{"MemoryMax", l.MemoryMax},
+	} {
+		if v.value != "" && v.value != "0" {
+			s = append(s, fmt.Sprintf("%s=%s", v.name, v.value))
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+// limiter applies the Limits of a job to a single run
+type limiter struct {
+	job    *Job
+	runid  uuid.UUID
+	cgroup string
+	fd     *os.File
+	pid    int
+	done   chan bool // closed by ended to stop watch
+	exited chan bool // closed when watch returns
+	lock   sync.Mutex
+	reason string
+}
+
+// newLimiter prepares the Limits of the job for run runid, setting c to start the process
+// with its rlimits and priority and in its cgroup. c.SysProcAttr and c.Env must already be
+// set. A nil limiter is returned if the job has no Limits
+func (job *Job) newLimiter(c *exec.Cmd, runid uuid.UUID) *limiter {
+	if job.Limits == nil {
+		return nil
+	}
+	l := &limiter{job: job, runid: runid}
+	path := c.Path
+	if err := limitCmd(c, job.Limits); err != nil {
+		ServerLogger.Printf("[Limits] %s rlimits and priority not applied: %s", job.Name, err)
+	} else if c.Path != path {
+		// started as the rpeat executable, failing the run if RunAs cannot execute it
+		if err = job.runAsExecutable(); err != nil {
+			c.Err = err
+		}
+	}
+	if job.Limits.CPUMax != "" || job.Limits.MemoryMax != "" {
+		if err := l.createCgroup(c.SysProcAttr); err != nil {
+			ServerLogger.Printf("[Limits] %s CPUMax and MemoryMax not applied: %s", job.Name, err)
+		}
+	}
+	return l
+}
+
+// started watches MaxRSS and CPUSeconds of the process pid
+func (l *limiter) started(pid int) {
+	if l == nil {
+		return
+	}
+	if l.fd != nil {
+		l.fd.Close()
+		l.fd = nil
+	}
+	l.pid = pid
+	if l.job.Limits.MaxRSS != "" || l.job.Limits.CPUSeconds > 0 {
+		l.done = make(chan bool)
+		l.exited = make(chan bool)
+		go l.watch(l.done, l.exited, pid)
+	}
+}
+
+// watch kills the processes of the run, process group pid, if they exceed MaxRSS or
+// CPUSeconds, until done is closed. exited is closed on return
+func (l *limiter) watch(done, exited chan bool, pid int) {
+	defer close(exited)
+	maxrss, _ := parseSize(l.job.Limits.MaxRSS)
+	maxcpu := time.Duration(l.job.Limits.CPUSeconds) * time.Second
+	ticker := time.NewTicker(limitInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			rss, cpu, err := processGroupUsage(pid)
+			if err != nil {
+				continue
+			}
+			var reason string
+			if maxrss > 0 && rss > maxrss {
+				reason = fmt.Sprintf("MaxRSS %s exceeded (rss %s)", l.job.Limits.MaxRSS, formatSize(rss))
+			} else if maxcpu > 0 && cpu > maxcpu {
+				reason = fmt.Sprintf("CPUSeconds %d exceeded (cpu %s)", l.job.Limits.CPUSeconds, cpu.Round(time.Millisecond))
+			}
+			if reason != "" {
+				l.lock.Lock()
+				l.reason = reason
+				l.lock.Unlock()
+				ServerLogger.Printf("[Limits] %s:%s %s - killing pid %d", l.job.Name, l.runid, reason, pid)
+				syscallKill(-pid, syscall.SIGKILL)
+				return
+			}
+		}
+	}
+}
+
+// ended stops watching the run, waiting for watch to return so the process group is not
+// checked once it may be reused, and removes its cgroup, returning the limit the run was
+// killed for exceeding, if any. ps is the state of the ended process, or nil if it
+// failed to start. ended is called once per run
+func (l *limiter) ended(ps *os.ProcessState) string {
+	if l == nil {
+		return ""
+	}
+	if l.done != nil {
+		close(l.done)
+		<-l.exited
+	}
+	if l.fd != nil {
+		l.fd.Close()
+		l.fd = nil
+	}
+	l.lock.Lock()
+	reason := l.reason
+	l.lock.Unlock()
+	if ps != nil && reason == "" {
+		reason = l.exitReason(ps)
+	}
+	if reason != "" {
+		ServerLogger.Printf("[Limits] %s:%s %s", l.job.Name, l.runid, reason)
+	}
+	l.removeCgroup()
+	return reason
+}
+
+// formatSize formats n bytes using the largest whole unit of K, M, G or T
+func formatSize(n int64) string {
+	units := "KMGT"
+	n >>= 10
+	i := 0
+	for i < len(units)-1 && n >= 1<<10 {
+		n >>= 10
+		i++
+	}
+	return fmt.Sprintf("%d%c", n, units[i])
+}
@@ -0,0 +1,231 @@
+package rpeat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+	clockTicks       = 100 // USER_HZ of /proc/PID/stat times
+	rlimitNproc      = 6
+
+	// the Limits and the path of the command of a run started by limitCmd
+	limitsEnv     = "_RPEAT_LIMITS"
+	limitsPathEnv = "_RPEAT_LIMITS_PATH"
+)
+
+// A run with Limits is started as the rpeat executable, which applies the rlimits and
+// priority to itself in init and then execs the command of the job, so they are in place
+// before any of the job runs
+func init() {
+	if spec := os.Getenv(limitsEnv); spec != "" {
+		execLimited(spec, os.Getenv(limitsPathEnv))
+	}
+}
+
+// limitCmd starts c with the Limits l applied
+func limitCmd(c *exec.Cmd, l *Limits) error {
+	if c.Err != nil || (l.CPUSeconds == 0 && l.OpenFiles == 0 && l.Processes == 0 && l.Nice == 0 && l.IONice == "") {
+		return nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	spec, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	if c.Env == nil {
+		c.Env = os.Environ()
+	}
+	c.Env = append(c.Env, limitsEnv+"="+string(spec), limitsPathEnv+"="+c.Path)
+	c.Path = exe
+	return nil
+}
+
+// execLimited applies the Limits of spec to the current process and execs path
+func execLimited(spec, path string) {
+	var l Limits
+	err := json.Unmarshal([]byte(spec), &l)
+	if err == nil {
+		err = applyLimits(&l)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ rpeat ] %s\n", err)
+	}
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, limitsEnv+"=") && !strings.HasPrefix(kv, limitsPathEnv+"=") {
+			env = append(env, kv)
+		}
+	}
+	err = syscall.Exec(path, os.Args, env)
+	fmt.Fprintf(os.Stderr, "[ rpeat ] Unable to create process %s: %s\n", path, err)
+	os.Exit(127)
+}
+
+// applyLimits sets the rlimits and scheduling priority of Limits on the current process
+func applyLimits(l *Limits) error {
+	var errs []string
+	set := func(name string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", name, err))
+		}
+	}
+	if l.CPUSeconds > 0 {
+		// SIGXCPU at the soft limit, SIGKILL a second later if it is ignored
+		set("CPUSeconds", syscall.Setrlimit(syscall.RLIMIT_CPU, &syscall.Rlimit{Cur: uint64(l.CPUSeconds), Max: uint64(l.CPUSeconds + 1)}))
+	}
+	if l.OpenFiles > 0 {
+		set("OpenFiles", syscall.Setrlimit(syscall.RLIMIT_NOFILE, &syscall.Rlimit{Cur: uint64(l.OpenFiles), Max: uint64(l.OpenFiles)}))
+	}
+	if l.Processes > 0 {
+		set("Processes", syscall.Setrlimit(rlimitNproc, &syscall.Rlimit{Cur: uint64(l.Processes), Max: uint64(l.Processes)}))
+	}
+	if l.Nice != 0 {
+		set("Nice", syscall.Setpriority(syscall.PRIO_PROCESS, 0, l.Nice))
+	}
+	if l.IONice != "" {
+		class, level, err := parseIONice(l.IONice)
+		if err == nil {
+			_, _, errno := syscall.RawSyscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, 0, uintptr(class<<ioprioClassShift|level))
+			if errno != 0 {
+				err = errno
+			}
+		}
+		set("IONice", err)
+	}
+	if len(errs) > 0 {
+		return errors.New("unable to set Limits " + strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// processGroupUsage returns the total resident memory and CPU time of the processes in
+// process group pgid, including children they have waited for
+func processGroupUsage(pgid int) (int64, time.Duration, error) {
+	dirs, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return 0, 0, err
+	}
+	var rss, ticks int64
+	found := false
+	for _, d := range dirs {
+		if _, err := strconv.Atoi(d.Name()); err != nil {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join("/proc", d.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		// fields following "(comm)", starting with state (field 3 of proc(5))
+		s := string(b)
+		f := strings.Fields(s[strings.LastIndex(s, ")")+1:])
+		if len(f) < 22 || f[2] != strconv.Itoa(pgid) {
+			continue
+		}
+		found = true
+		for _, i := range []int{11, 12, 13, 14} { // utime stime cutime cstime
+			t, _ := strconv.ParseInt(f[i], 10, 64)
+			ticks += t
+		}
+		pages, _ := strconv.ParseInt(f[21], 10, 64)
+		rss += pages * int64(os.Getpagesize())
+	}
+	if !found {
+		return 0, 0, errors.New(fmt.Sprintf("no processes in group %d", pgid))
+	}
+	return rss, time.Duration(ticks) * time.Second / clockTicks, nil
+}
+
+// createCgroup creates the cgroup of the run under CgroupRoot with CPUMax and MemoryMax,
+// and sets attr to start the process in it
+func (l *limiter) createCgroup(attr *syscall.SysProcAttr) error {
+	if CgroupRoot == "" {
+		return errors.New("no CgroupRoot set in server configuration")
+	}
+	dir := filepath.Join(CgroupRoot, fmt.Sprintf("rpeat-%s", l.runid))
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return err
+	}
+	l.cgroup = dir
+	if l.job.Limits.MemoryMax != "" {
+		n, _ := parseSize(l.job.Limits.MemoryMax)
+		if err := ioutil.WriteFile(filepath.Join(dir, "memory.max"), []byte(strconv.FormatInt(n, 10)), 0644); err != nil {
+			l.removeCgroup()
+			return err
+		}
+		// without swap MemoryMax bounds the memory of the run
+		ioutil.WriteFile(filepath.Join(dir, "memory.swap.max"), []byte("0"), 0644)
+	}
+	if l.job.Limits.CPUMax != "" {
+		max, _ := parseCPUMax(l.job.Limits.CPUMax)
+		if err := ioutil.WriteFile(filepath.Join(dir, "cpu.max"), []byte(max), 0644); err != nil {
+			l.removeCgroup()
+			return err
+		}
+	}
+	fd, err := os.Open(dir)
+	if err != nil {
+		l.removeCgroup()
+		return err
+	}
+	l.fd = fd
+	attr.UseCgroupFD = true
+	attr.CgroupFD = int(fd.Fd())
+	return nil
+}
+
+// removeCgroup kills any processes remaining in the cgroup of the run and removes it
+func (l *limiter) removeCgroup() {
+	if l.cgroup == "" {
+		return
+	}
+	ioutil.WriteFile(filepath.Join(l.cgroup, "cgroup.kill"), []byte("1"), 0644)
+	var err error
+	for i := 0; i < 10; i++ {
+		if err = os.Remove(l.cgroup); err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err != nil {
+		ServerLogger.Printf("[Limits] %s unable to remove cgroup %s: %s", l.job.Name, l.cgroup, err)
+	}
+	l.cgroup = ""
+}
+
+// exitReason returns the limit a process ending with state ps was killed for exceeding
+func (l *limiter) exitReason(ps *os.ProcessState) string {
+	status, ok := ps.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return ""
+	}
+	switch status.Signal() {
+	case syscall.SIGXCPU:
+		return fmt.Sprintf("CPUSeconds %d exceeded", l.job.Limits.CPUSeconds)
+	case syscall.SIGKILL:
+		if l.cgroup == "" {
+			break
+		}
+		b, _ := ioutil.ReadFile(filepath.Join(l.cgroup, "memory.events"))
+		for _, line := range strings.Split(string(b), "\n") {
+			if f := strings.Fields(line); len(f) == 2 && f[0] == "oom_kill" && f[1] != "0" {
+				return fmt.Sprintf("MemoryMax %s exceeded", l.job.Limits.MemoryMax)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,31 @@
+//go:build !linux
+// +build !linux
+
+package rpeat
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+func limitCmd(c *exec.Cmd, l *Limits) error {
+	return errors.New("Limits are unsupported in " + runtime.GOOS)
+}
+
+func processGroupUsage(pgid int) (int64, time.Duration, error) {
+	return 0, 0, errors.New("Limits are unsupported in " + runtime.GOOS)
+}
+
+func (l *limiter) createCgroup(attr *syscall.SysProcAttr) error {
+	return errors.New("cgroups are unsupported in " + runtime.GOOS)
+}
+
+func (l *limiter) removeCgroup() {}
+
+func (l *limiter) exitReason(ps *os.ProcessState) string {
+	return ""
+}
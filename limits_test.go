@@ -0,0 +1,46 @@
+package rpeat
+
+import (
+	"github.com/google/uuid"
+	"io/ioutil"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestLimiterWatchExits(t *testing.T) {
+	initServerLogging(ioutil.Discard)
+	interval := limitInterval
+	limitInterval = 10 * time.Millisecond
+	defer func() { limitInterval = interval }()
+
+	job := &Job{Name: "limits", Limits: &Limits{CPUSeconds: 60, MaxRSS: "1G"}}
+	for i := 0; i < 5; i++ {
+		c := exec.Command("/bin/sh", "-c", "sleep 0.1")
+		c.SysProcAttr = syscallSysProcAttr()
+		lim := job.newLimiter(c, uuid.New())
+		if err := c.Start(); err != nil {
+			t.Fatalf("Start: %s", err)
+		}
+		lim.started(c.Process.Pid)
+		exited := lim.exited
+		if err := c.Wait(); err != nil {
+			t.Fatalf("Wait: %s", err)
+		}
+		ended := make(chan string)
+		go func() { ended <- lim.ended(c.ProcessState) }()
+		select {
+		case reason := <-ended:
+			if reason != "" {
+				t.Errorf("ended = %q; want no limit exceeded", reason)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("ended did not return")
+		}
+		select {
+		case <-exited:
+		default:
+			t.Fatal("watch still running after ended")
+		}
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"html/template"
 	"net"
+	"net/url"
 	"reflect"
 	"sync"
 	"time"
@@ -147,6 +148,41 @@ func StartServer(server ServerConfig) {
 		decodeKRequest,
 		encodeResponse,
 	)
+	backfillHandler := httptransport.NewServer(
+		makeBackfillEndpoint(sd.svc),
+		decodeBackfillRequest,
+		encodeResponse,
+	)
+	blackoutsHandler := httptransport.NewServer(
+		makeBlackoutsEndpoint(sd.svc),
+		decodeBlackoutRequest,
+		encodeResponse,
+	)
+	addBlackoutHandler := httptransport.NewServer(
+		makeAddBlackoutEndpoint(sd.svc),
+		decodeBlackoutRequest,
+		encodeResponse,
+	)
+	removeBlackoutHandler := httptransport.NewServer(
+		makeRemoveBlackoutEndpoint(sd.svc),
+		decodeBlackoutRequest,
+		encodeResponse,
+	)
+	calendarsHandler := httptransport.NewServer(
+		makeCalendarsEndpoint(sd.svc),
+		decodeCalendarRequest,
+		encodeResponse,
+	)
+	calendarDatesHandler := httptransport.NewServer(
+		makeCalendarDatesEndpoint(sd.svc),
+		decodeCalendarRequest,
+		encodeResponse,
+	)
+	calendarCheckHandler := httptransport.NewServer(
+		makeCalendarCheckEndpoint(sd.svc),
+		decodeCalendarRequest,
+		encodeResponse,
+	)
 	/// optional TLS using pure go
 	/// https://gist.github.com/denji/12b3a568f092ab951456
 	mx := mux.NewRouter()
@@ -168,6 +204,13 @@ func StartServer(server ServerConfig) {
 	mx.Handle("/api/restart", restartHandler)
 	mx.Handle("/api/hold", holdHandler)
 	mx.Handle("/api/status", statusHandler)
+	mx.Handle("/api/backfill", backfillHandler)
+	mx.Handle("/api/blackouts", blackoutsHandler)
+	mx.Handle("/api/blackouts/add", addBlackoutHandler)
+	mx.Handle("/api/blackouts/remove", removeBlackoutHandler)
+	mx.Handle("/api/calendars", calendarsHandler)
+	mx.Handle("/api/calendars/dates", calendarDatesHandler)
+	mx.Handle("/api/calendars/check", calendarCheckHandler)
 
 	mx.HandleFunc("/api/log/{ext}/{jobid}/{runid}", func(w http.ResponseWriter, r *http.Request) {
 
@@ -219,6 +262,7 @@ func StartServer(server ServerConfig) {
 			"getDependencies":  func(job Job) template.HTML { return GetDependencies(job, sd) },
 			"slugify":          slugify,
 			"historyBars":      HistoryBars,
+			"usageChart":       UsageChart,
 			"stringify":        Stringify,
 			"stringifyWithSep": StringifyWithSep,
 			"stringifyHTML":    func(s string) template.HTML { h := template.HTML(Stringify(s)); return h },
@@ -292,6 +336,11 @@ func StartServer(server ServerConfig) {
 		}
 	})
 
+	/* Calendars */
+	mx.HandleFunc("/calendars", func(w http.ResponseWriter, r *http.Request) {
+		calendarsPageHandler(w, r, sd, server)
+	})
+
 	/* Dashboards */
 	mx.HandleFunc("/{group}", func(w http.ResponseWriter, r *http.Request) {
 		dashboardHandler(w, r, sd, server)
@@ -353,7 +402,11 @@ func Shutdown(jobs jobMap) {
 	for _, job := range jobs {
 		ServerLogger.Printf("SHUTDOWN check for %s:%s", job.JobUUID, job.Name)
 		go func(job *Job) {
-			if job.JobState == JRunning || job.JobState == JRetrying {
+			if job.JobState == JQueued {
+				ServerLogger.Printf("Removing %s:%s from queue", job.JobUUID, job.Name)
+				job.setHold(true)
+				job.cancelQueued()
+			} else if job.JobState == JRunning || job.JobState == JWarning2 || job.JobState == JRetrying {
 				if job.ShutdownCmd != "" {
 					ServerLogger.Printf("Shutting down %s:%s", job.JobUUID, job.Name)
 					shutdownJob(job, JStopped)
@@ -407,6 +460,29 @@ func (server ServerConfig) startJobs(sd *ServerData) {
 	keephistory := server.KeepHistory
 	maxhistory := server.MaxHistory
 
+	if sd.calendars == nil {
+		if r, err := newCalendarRegistry(server.CalendarDirs, sd.calendarsChanged); err != nil {
+			ServerLogger.Printf("[Calendar] unable to watch calendars: %s", err)
+		} else {
+			sd.calendars = r
+			calendars = r
+		}
+	}
+	SetPools(server.Pools)
+	if err := SetBlackouts(server); err != nil {
+		ServerLogger.Printf("[Blackout] %s", err)
+	}
+	if err := SetCgroupRoot(server.CgroupRoot); err != nil {
+		ServerLogger.Printf("[Limits] %s", err)
+	}
+	if server.PriorityAging != "" {
+		if d, err := time.ParseDuration(server.PriorityAging); err != nil {
+			ServerLogger.Printf("invalid PriorityAging %s: %s - using %s", server.PriorityAging, err, PriorityAging)
+		} else {
+			PriorityAging = d
+		}
+	}
+
 	ServerLogger.Printf("Loading Jobs configuration file: %s", config[0])
 	sjobs, jve := LoadConfig2(home, config, server, reloadjobs, keephistory, maxhistory, server.Name, serverkey, server.ApiKey, server.Logging)
 	jobs := sjobs.Jobs
@@ -424,6 +500,11 @@ func (server ServerConfig) startJobs(sd *ServerData) {
 		users, _ = LoadAuth(server.AuthFile)
 		// FIXME: add auth error check
 	}
+	for _, job := range jobs {
+		if job.runAsErr = job.allowedRunAs(users); job.runAsErr != nil {
+			ServerLogger.Printf("[RunAs] %s:%s %s", job.JobUUID, job.Name, job.runAsErr)
+		}
+	}
 
 	depEvt := make(chan *depEvt, MAX_JOBS)
 	//ServerLogger.Printf("Initializing JobUpdate Channel")
@@ -538,6 +619,11 @@ func (server *ServerConfig) reloadJobs(sd *ServerData) {
 	var wg sync.WaitGroup
 
 	jobs := sjobs.Jobs
+	for _, job := range jobs {
+		if job.runAsErr = job.allowedRunAs(sd.users); job.runAsErr != nil {
+			ServerLogger.Printf("[RunAs] %s:%s %s", job.JobUUID, job.Name, job.runAsErr)
+		}
+	}
 	newJobsInCurrentJobs := StringsInSlice(sjobs.JobOrder, sd.job_order, false)
 	for _, id := range newJobsInCurrentJobs {
 		job := sd.jobs[id]
@@ -550,9 +636,11 @@ func (server *ServerConfig) reloadJobs(sd *ServerData) {
 				defer job.runlock.Unlock()
 				defer func() {
 					job.Updating = false
-					d, next := NextCronStart(job.cronStartArray)
-					job.setNextStart(next)
-					ServerLogger.Printf("Next job %s <%s> scheduled for %s [%d] (starts in %s)\n", job.Name, job.JobUUID, next, next.Unix(), d.Round(time.Second))
+					if job.checkCalendar() {
+						d, next, scheduled := NextCronSchedule(job.cronStartArray)
+						job.setNextStart(next, scheduled)
+						ServerLogger.Printf("Next job %s <%s> scheduled for %s [%d] (starts in %s)\n", job.Name, job.JobUUID, next, next.Unix(), d.Round(time.Second))
+					}
 					ServerLogger.Printf("completed Job update for %s [%s]", job.Name, job.JobUUID)
 					job.sendUpdateClient() // only update clients
 				}()
@@ -564,7 +652,9 @@ func (server *ServerConfig) reloadJobs(sd *ServerData) {
 				job.Calendar = jobs[id].Calendar
 				job.CalendarDirs = jobs[id].CalendarDirs
 				job.Rollback = jobs[id].Rollback
+				job.CalAdjust = jobs[id].CalAdjust
 				job.RequireCal = jobs[id].RequireCal
+				job.DSTPolicy = jobs[id].DSTPolicy
 				job.CronStart = jobs[id].CronStart
 				job.CronStartArray = jobs[id].CronStartArray
 				job.StartTime = jobs[id].StartTime
@@ -572,6 +662,18 @@ func (server *ServerConfig) reloadJobs(sd *ServerData) {
 				job.CronEnd = jobs[id].CronEnd
 				job.CronEndArray = jobs[id].CronEndArray
 				job.CronRestart = jobs[id].CronRestart
+				job.RRStart = jobs[id].RRStart
+				job.RREnd = jobs[id].RREnd
+				job.RRRestart = jobs[id].RRRestart
+				job.MaxInstances = jobs[id].MaxInstances
+				job.Pool = jobs[id].Pool
+				job.PoolSlots = jobs[id].PoolSlots
+				job.Priority = jobs[id].Priority
+				job.CatchUp = jobs[id].CatchUp
+				job.CatchUpWithin = jobs[id].CatchUpWithin
+				job.DateEnvAsOf = jobs[id].DateEnvAsOf
+				job.MinRuntime = jobs[id].MinRuntime
+				job.MaxRuntime = jobs[id].MaxRuntime
 				job.Dependency = jobs[id].Dependency
 				job.parseJob(true) // converts Cron* strings to Cron objects
 
@@ -585,13 +687,23 @@ func (server *ServerConfig) reloadJobs(sd *ServerData) {
 				job.Disabled = jobs[id].Disabled // this is not possible?
 				job.Hidden = jobs[id].Hidden
 				job.Cmd = jobs[id].Cmd
+				job.Shell = jobs[id].Shell
+				job.Script = jobs[id].Script
+				job.WorkDir = jobs[id].WorkDir
+				job.CreateWorkDir = jobs[id].CreateWorkDir
+				job.Umask = jobs[id].Umask
+				job.Stdin = jobs[id].Stdin
 				job.ShutdownCmd = jobs[id].ShutdownCmd
 				job.ShutdownSig = jobs[id].ShutdownSig
 				job.Env = jobs[id].Env
 				job.DateEnv = jobs[id].DateEnv
+				job.FiscalYearStart = jobs[id].FiscalYearStart
+				job.Params = jobs[id].Params
 				job.AlertActions = jobs[id].AlertActions
 				job.Jobs = jobs[id].Jobs
 				job.JobsControl = jobs[id].JobsControl
+				job.Matrix = jobs[id].Matrix
+				job.Parent = jobs[id].Parent
 				job.Retry = jobs[id].Retry
 				job.RetryWait = jobs[id].RetryWait
 				job.MaxDuration = jobs[id].MaxDuration
@@ -601,6 +713,9 @@ func (server *ServerConfig) reloadJobs(sd *ServerData) {
 				job.User = jobs[id].User
 				job.Permissions = jobs[id].Permissions
 				job.Admin = jobs[id].Admin
+				job.RunAs = jobs[id].RunAs
+				job.runAsErr = jobs[id].runAsErr
+				job.Limits = jobs[id].Limits
 
 				// TODO: send message
 				sd.jobs[id] = job
@@ -689,10 +804,18 @@ func (x *Job) areEqualSpec(y *Job) bool {
 		ServerLogger.Printf("Rollback has been updated")
 		return false
 	}
+	if !reflect.DeepEqual(x.CalAdjust, y.CalAdjust) {
+		ServerLogger.Printf("CalAdjust has been updated")
+		return false
+	}
 	if !reflect.DeepEqual(x.RequireCal, y.RequireCal) {
 		ServerLogger.Printf("RequireCal has been updated")
 		return false
 	}
+	if !reflect.DeepEqual(x.DSTPolicy, y.DSTPolicy) {
+		ServerLogger.Printf("DSTPolicy has been updated")
+		return false
+	}
 	if isNilAndNot(x.CronStart, y.CronStart) {
 		return false
 	}
@@ -720,6 +843,42 @@ func (x *Job) areEqualSpec(y *Job) bool {
 		ServerLogger.Printf("CronRestart has been updated")
 		return false
 	}
+	if !reflect.DeepEqual(x.RRStart, y.RRStart) {
+		ServerLogger.Printf("RRStart has been updated")
+		return false
+	}
+	if !reflect.DeepEqual(x.RREnd, y.RREnd) {
+		ServerLogger.Printf("RREnd has been updated")
+		return false
+	}
+	if !reflect.DeepEqual(x.RRRestart, y.RRRestart) {
+		ServerLogger.Printf("RRRestart has been updated")
+		return false
+	}
+	if !reflect.DeepEqual(x.MaxInstances, y.MaxInstances) {
+		ServerLogger.Printf("MaxInstances has been updated")
+		return false
+	}
+	if !reflect.DeepEqual(x.Pool, y.Pool) {
+		ServerLogger.Printf("Pool has been updated")
+		return false
+	}
+	if !reflect.DeepEqual(x.PoolSlots, y.PoolSlots) {
+		ServerLogger.Printf("PoolSlots has been updated")
+		return false
+	}
+	if !reflect.DeepEqual(x.Priority, y.Priority) {
+		ServerLogger.Printf("Priority has been updated")
+		return false
+	}
+	if !reflect.DeepEqual(x.CatchUp, y.CatchUp) {
+		ServerLogger.Printf("CatchUp has been updated")
+		return false
+	}
+	if !reflect.DeepEqual(x.CatchUpWithin, y.CatchUpWithin) {
+		ServerLogger.Printf("CatchUpWithin has been updated")
+		return false
+	}
 	if !reflect.DeepEqual(x.Inherits, y.Inherits) {
 		ServerLogger.Printf("Inherits has been updated")
 		return false
@@ -752,6 +911,22 @@ func (x *Job) areEqualSpec(y *Job) bool {
 		ServerLogger.Printf("DateEnv has been updated")
 		return false
 	}
+	if !reflect.DeepEqual(x.FiscalYearStart, y.FiscalYearStart) {
+		ServerLogger.Printf("FiscalYearStart has been updated")
+		return false
+	}
+	if !reflect.DeepEqual(x.DateEnvAsOf, y.DateEnvAsOf) {
+		ServerLogger.Printf("DateEnvAsOf has been updated")
+		return false
+	}
+	if !reflect.DeepEqual(x.Matrix, y.Matrix) {
+		ServerLogger.Printf("Matrix has been updated")
+		return false
+	}
+	if !reflect.DeepEqual(x.Params, y.Params) {
+		ServerLogger.Printf("Params has been updated")
+		return false
+	}
 	if x.Hold != y.Hold {
 		ServerLogger.Printf("Hold has been updated")
 		return false
@@ -768,6 +943,14 @@ func (x *Job) areEqualSpec(y *Job) bool {
 		ServerLogger.Printf("MaxDuration has been updated")
 		return false
 	}
+	if !reflect.DeepEqual(x.MinRuntime, y.MinRuntime) {
+		ServerLogger.Printf("MinRuntime has been updated")
+		return false
+	}
+	if !reflect.DeepEqual(x.MaxRuntime, y.MaxRuntime) {
+		ServerLogger.Printf("MaxRuntime has been updated")
+		return false
+	}
 	if x.TmpDir != y.TmpDir {
 		ServerLogger.Printf("TmpDir has been updated")
 		return false
@@ -789,12 +972,40 @@ func (x *Job) areEqualSpec(y *Job) bool {
 		ServerLogger.Printf("Permissions has been updated")
 		return false
 	}
+	if !reflect.DeepEqual(x.RunAs, y.RunAs) {
+		ServerLogger.Printf("RunAs has been updated")
+		return false
+	}
+	if !reflect.DeepEqual(x.Limits, y.Limits) {
+		ServerLogger.Printf("Limits has been updated")
+		return false
+	}
 	if !reflect.DeepEqual(x.Admin, y.Admin) {
 		ServerLogger.Printf("Admin has been updated")
 		return false
 	}
-	if *(x.Cmd) != *(y.Cmd) {
-		ServerLogger.Printf("Cmd has been updated x:%s, y:%s", *x.Cmd, *y.Cmd)
+	if !reflect.DeepEqual(x.Cmd, y.Cmd) {
+		ServerLogger.Printf("Cmd has been updated")
+		return false
+	}
+	if x.Shell != y.Shell {
+		ServerLogger.Printf("Shell has been updated")
+		return false
+	}
+	if x.Script != y.Script {
+		ServerLogger.Printf("Script has been updated")
+		return false
+	}
+	if x.WorkDir != y.WorkDir || x.CreateWorkDir != y.CreateWorkDir {
+		ServerLogger.Printf("WorkDir has been updated")
+		return false
+	}
+	if x.Umask != y.Umask {
+		ServerLogger.Printf("Umask has been updated")
+		return false
+	}
+	if x.Stdin != y.Stdin {
+		ServerLogger.Printf("Stdin has been updated")
 		return false
 	}
 	if x.ShutdownSig != y.ShutdownSig {
@@ -813,6 +1024,111 @@ func isNilAndNot(x, y interface{}) bool {
 	return (x == nil && y != nil) || (x != nil && y == nil)
 }
 
+// calendarsPageHandler renders a month grid (?month=YYYYMM) for the calendars used by the
+// jobs of the user, or those selected with ?calendar=, marking the jobs triggering each day
+func calendarsPageHandler(w http.ResponseWriter, r *http.Request, sd *ServerData, server ServerConfig) {
+	funs := template.FuncMap{"stringify": Stringify}
+	t, err := template.New("CalendarsHTML").Funcs(funs).Parse(CalendarsHTML)
+
+	themePath := filepath.Join(server.ThemeDir, server.Theme)
+	cssFiles, _ := ioutil.ReadDir(themePath)
+	var themeCSS string
+	for _, cssfile := range cssFiles {
+		thiscss, err := ioutil.ReadFile(filepath.Join(themePath, cssfile.Name()))
+		if err != nil {
+			ServerLogger.Println("failed read of theme css file:", err.Error())
+		}
+		themeCSS = fmt.Sprintf("%s\n%s", themeCSS, thiscss)
+	}
+	CSS := fmt.Sprintf("%s\n%s", ClientCSS, themeCSS)
+	t.New("ClientHeaderHTML").Parse(ClientHeaderHTML)
+	t.New("ClientCSS").Parse(CSS)
+	t.New("ClientJS").Parse(ClientJS)
+
+	user, _, _ := r.BasicAuth()
+	loc := calendarLocation(server.Timezone)
+	today, _ := parseCalendarDate("", loc)
+	month := today.AddDate(0, 0, 1-today.Day())
+	if m := r.URL.Query().Get("month"); m != "" {
+		if t, err := time.ParseInLocation("200601", m, loc); err == nil {
+			month = t
+		}
+	}
+
+	type calendarCell struct {
+		Day       int
+		Date      int
+		Available bool
+		Today     bool
+		Holiday   string
+		Jobs      []string
+	}
+	type calendarMonth struct {
+		Info  CalendarInfo
+		Error string
+		Weeks [][]calendarCell
+	}
+	type CalendarPage struct {
+		Base      template.URL
+		Config    ServerConfig
+		Month     string
+		This      string
+		Prev      string
+		Next      string
+		Query     template.URL
+		Calendars []CalendarInfo
+		Selected  map[string]bool
+		Months    []calendarMonth
+	}
+
+	page := CalendarPage{Config: server, Base: "", Selected: make(map[string]bool),
+		Month: month.Format("January 2006"), This: month.Format("200601"),
+		Prev: month.AddDate(0, -1, 0).Format("200601"), Next: month.AddDate(0, 1, 0).Format("200601")}
+	if resp, err := sd.svc.Calendars(user); err == nil {
+		page.Calendars = resp.Calendars
+	}
+	for _, name := range r.URL.Query()["calendar"] {
+		page.Selected[name] = true
+		page.Query += template.URL("&calendar=" + url.QueryEscape(name))
+	}
+	if len(page.Selected) == 0 {
+		for _, info := range page.Calendars {
+			if len(info.Jobs) > 0 {
+				page.Selected[info.Name] = true
+			}
+		}
+	}
+
+	for _, info := range page.Calendars {
+		if !page.Selected[info.Name] {
+			continue
+		}
+		m := calendarMonth{Info: info}
+		resp, err := sd.svc.CalendarDates(user, info.Name, info.CalendarDirs, month.Format("20060102"), month.AddDate(0, 1, -1).Format("20060102"))
+		if err != nil {
+			m.Error = err.Error()
+			page.Months = append(page.Months, m)
+			continue
+		}
+		week := make([]calendarCell, 7)
+		for i, day := range resp.Days {
+			d := month.AddDate(0, 0, i)
+			week[d.Weekday()] = calendarCell{Day: d.Day(), Date: day.Date, Available: day.Available, Today: d.Equal(today), Holiday: day.Holiday, Jobs: day.Jobs}
+			if d.Weekday() == time.Saturday || i == len(resp.Days)-1 {
+				m.Weeks = append(m.Weeks, week)
+				week = make([]calendarCell, 7)
+			}
+		}
+		page.Months = append(page.Months, m)
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(http.StatusOK)
+	if err = t.Execute(w, page); err != nil {
+		ServerLogger.Printf("CalendarsView Parse Error: %s", err.Error())
+	}
+}
+
 func dashboardHandler(w http.ResponseWriter, r *http.Request, sd *ServerData, server ServerConfig) {
 
 	vars := mux.Vars(r)
@@ -821,6 +1137,7 @@ func dashboardHandler(w http.ResponseWriter, r *http.Request, sd *ServerData, se
 		"getElapsed":      GetElapsed,
 		"getDependencies": func(job Job) template.HTML { return GetDependencies(job, sd) },
 		"slugify":         slugify,
+		"stringify":       Stringify,
 		"stringifyHTML":   func(s string) template.HTML { h := template.HTML(Stringify(s)); return h },
 	}
 
@@ -0,0 +1,100 @@
+package rpeat
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Matrix expands a single job definition into one child job for each combination of
+// the Values of its Env variables, e.g.
+//
+//	"Name": "load",
+//	"Cmd": "/opt/bin/load $REGION $TICKER",
+//	"Matrix": {
+//	  "Env": [
+//	    {"Name": "REGION", "Values": ["us", "eu"]},
+//	    {"Name": "TICKER", "Values": ["SPY", "QQQ"]}
+//	  ],
+//	  "Parent": true
+//	}
+//
+// defines load-us-SPY, load-us-QQQ, load-eu-SPY and load-eu-QQQ, each with its values
+// added to Env. Children otherwise share the definition of the job, including Inherits,
+// and their JobUUID is derived from the JobUUID and Name of the child so it is stable
+// across reloads.
+//
+// Without Parent each child runs on the schedule of the job, and the job itself is not
+// run. With Parent the job is a controller as with Jobs: its trigger starts all children
+// at once (limited by JobsControl.MaxConcurrent) and it completes with success when all
+// children succeed, or with stopped or failed if any child is stopped or fails. In both
+// cases the children are grouped under the job in the dashboard.
+type Matrix struct {
+	Env    []MatrixVar `json:"Env"`
+	Parent bool        `json:"Parent,omitempty"`
+}
+
+// MatrixVar is an environment variable Name of a Matrix and the Values it takes
+type MatrixVar struct {
+	Name   string   `json:"Name"`
+	Values []string `json:"Values"`
+}
+
+// validateMatrix checks the variables of m, and that it is not combined with Jobs
+func validateMatrix(m *Matrix, jobs []JobSpec) error {
+	if m == nil {
+		return nil
+	}
+	if jobs != nil {
+		return errors.New("Matrix cannot be used with Jobs")
+	}
+	if len(m.Env) == 0 {
+		return errors.New("Matrix requires at least one Env variable")
+	}
+	seen := make(map[string]bool)
+	for _, v := range m.Env {
+		if !paramName.MatchString(v.Name) {
+			return errors.New(fmt.Sprintf("Matrix name %q must be a valid environment variable name", v.Name))
+		}
+		if seen[v.Name] {
+			return errors.New(fmt.Sprintf("Matrix %s is declared more than once", v.Name))
+		}
+		seen[v.Name] = true
+		if len(v.Values) == 0 {
+			return errors.New(fmt.Sprintf("Matrix %s has no Values", v.Name))
+		}
+		values := make(map[string]bool)
+		for _, value := range v.Values {
+			if value == "" || values[value] {
+				return errors.New(fmt.Sprintf("Matrix %s: Values must be unique and not empty", v.Name))
+			}
+			values[value] = true
+		}
+	}
+	return nil
+}
+
+// combinations returns the NAME=value pairs of each child, varying the last variable fastest
+func (m *Matrix) combinations() [][]string {
+	combos := [][]string{nil}
+	for _, v := range m.Env {
+		next := make([][]string, 0, len(combos)*len(v.Values))
+		for _, combo := range combos {
+			for _, value := range v.Values {
+				kv := append(append([]string{}, combo...), fmt.Sprintf("%s=%s", v.Name, value))
+				next = append(next, kv)
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// matrixName is the Name of the child of name with the NAME=value pairs of combo
+func matrixName(name string, combo []string) string {
+	parts := []string{name}
+	for _, kv := range combo {
+		parts = append(parts, kv[strings.Index(kv, "=")+1:])
+	}
+	return strings.Join(parts, "-")
+}
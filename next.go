@@ -45,6 +45,15 @@ func now(location, asof string) time.Time {
 }
 
 func (c Cron) NextStart(asof string) (time.Duration, time.Time, error) {
+	return c.NextStartAfter(now(c.Timezone, asof))
+}
+
+// NextStartAfter is NextStart as of the time t, which unlike the wall clock asof
+// string is unambiguous during daylight saving transitions
+func (c Cron) NextStartAfter(t time.Time) (time.Duration, time.Time, error) {
+	if loc, err := time.LoadLocation(c.Timezone); err == nil {
+		t = t.In(loc)
+	}
 
 	// TODO: add support for calendars for @every and @at
 	if c.IsEvery() {
@@ -52,25 +61,46 @@ func (c Cron) NextStart(asof string) (time.Duration, time.Time, error) {
 		//loc, _ := time.LoadLocation(c.Timezone)
 		//now := time.Now().In(loc)
 		//return d, now.Add(d), nil
-		return d, now(c.Timezone, asof).Add(d), nil
+		return d, t.Add(d), nil
 	}
 	if c.IsAt() {
 		loc, _ := time.LoadLocation(c.Timezone)
 		nextRun, _ := time.ParseInLocation("20060102150405", c.at, loc)
-		d := nextRun.Sub(now(c.Timezone, asof).Add(time.Millisecond))
+		d := nextRun.Sub(t.Add(time.Millisecond))
 		if d < 0 {
 			return time.Duration(math.MaxInt64), ZeroTime, errors.New("@at has passed")
 		} else {
 			return d, nextRun, nil
 		}
 	}
+	if c.IsRRule() {
+		return c.rrule.nextStart(c, t)
+	}
 	if c.IsNull() || c.isDependent() {
 		ServerLogger.Println("NON TRIGGERING CRON")
 		return time.Duration(math.MaxInt64), ZeroTime, errors.New("non-triggering Cron")
 	}
-	m := c.Months()
-	d := c.Mdays()
-	wd := c.Wdays()
+	current := t.Add(time.Millisecond)
+	d, nextRun, err := c.nextStart(current, current)
+
+	if c.DSTPolicy == DSTRunTwice {
+		// during the first pass of a repeated (fall back) hour, the second pass is also a candidate
+		if T, diff := fallBack(current); diff > 0 && T.Sub(current) <= diff {
+			if d2, next2, err2 := c.nextStart(T.Add(-diff-time.Millisecond), current); !next2.IsZero() && next2.Before(nextRun) {
+				d, nextRun, err = d2, next2, err2
+			}
+		}
+	}
+	if c.jitter > 0 && !nextRun.IsZero() {
+		nextRun = nextRun.Add(time.Second * time.Duration(rand.Intn(c.jitter)))
+		d = nextRun.Sub(current)
+	}
+	return d, nextRun, err
+}
+
+// nextStart calculates the next trigger using the wall clock of current, returning
+// only times after the time after. See DSTPolicy
+func (c Cron) nextStart(current, after time.Time) (time.Duration, time.Time, error) {
 	H := c.Hours()
 	M := c.Minutes()
 	S := c.Seconds()
@@ -81,11 +111,10 @@ func (c Cron) NextStart(asof string) (time.Duration, time.Time, error) {
 	rollback := c.Rollback
 	endof := c.EndOf
 
-	current := now(tz, asof).Add(time.Millisecond)
 	//currentIn := current.In(time.UTC)  /// FIXME: Why is this in UTC?  Currently breaks when we are in final day of a month (year too?) and TZ adjustment pushes to next month
 	currentIn := current
 
-	dates := nextYearDates(currentIn.Year(), int(currentIn.Month()), d, m, wd)
+	dates := c.triggerDates(currentIn.Year(), int(currentIn.Month()))
 
 	var cal Cal
 
@@ -108,21 +137,42 @@ func (c Cron) NextStart(asof string) (time.Duration, time.Time, error) {
 			cal.DatesIn = nextYearDates(currentIn.Year(), int(currentIn.Month()), nc.Mdays(), nc.Months(), nc.Wdays())
 		}
 		n := 0
-		for _, dt := range dates {
-			i := sort.SearchInts(cal.DatesIn, dt)
-			if i == len(cal.DatesIn) { // outside of calendar range - copy date to calendar
-				caldt = dt
-			} else {
-				caldt = cal.DatesIn[i]
-				lastDateInCal = caldt // keep track of last date in cal if need to remove
+		if useCal && c.adjust {
+			// dates are moved by the business day convention in place of rollback, with
+			// EndOf dates first moved to the last day of the prior period
+			for _, dt := range dates {
+				if endof {
+					dt = dateAsInt(time.Date(dt/10000, time.Month(dt/100%100), dt%100-1, 0, 0, 0, 0, time.UTC))
+				}
+				caldt, inRange := cal.adjustDate(dt, c.Adjust)
+				if caldt == 0 { // not available and not adjusted
+					continue
+				}
+				if inRange {
+					lastDateInCal = caldt
+				}
+				dates[n] = caldt
+				n++
 			}
-			dates[n] = caldt
-			if n > 0 && dates[n-1] == dates[n] {
-				continue
+			dates = uniqueInts(dates[:n])
+			rollback, endof = false, false
+		} else {
+			for _, dt := range dates {
+				i := sort.SearchInts(cal.DatesIn, dt)
+				if i == len(cal.DatesIn) { // outside of calendar range - copy date to calendar
+					caldt = dt
+				} else {
+					caldt = cal.DatesIn[i]
+					lastDateInCal = caldt // keep track of last date in cal if need to remove
+				}
+				dates[n] = caldt
+				if n > 0 && dates[n-1] == dates[n] {
+					continue
+				}
+				n++
 			}
-			n++
+			dates = dates[:n]
 		}
-		dates = dates[:n]
 	}
 
 	if len(dates) == 0 {
@@ -140,7 +190,7 @@ func (c Cron) NextStart(asof string) (time.Duration, time.Time, error) {
 	//ServerLogger.Printf(DebugColor, fmt.Sprintf("current: %d [%v] i:%s", dateAsInt(current), dates, i))
 
 	var runDate int
-	var nextRun time.Time
+	var wall string
 
 	curDate := dateAsInt(current)
 
@@ -169,7 +219,7 @@ func (c Cron) NextStart(asof string) (time.Duration, time.Time, error) {
 					runDate = runDate0
 				}
 			}
-			nextRun, _ = time.ParseInLocation("20060102150405", fmt.Sprintf("%08d%06d", runDate, firstTime), loc)
+			wall = fmt.Sprintf("%08d%06d", runDate, firstTime)
 			//ServerLogger.Printf(DebugColor, fmt.Sprintf("(1) currentTime:%s > lastTime (firstTime) | runDate0:%d runDate:%d dates[i+1]:%d", current, runDate0, runDate, dates[i+1]))
 		} else {
 			runDate = dates[i]
@@ -182,7 +232,7 @@ func (c Cron) NextStart(asof string) (time.Duration, time.Time, error) {
 					runDate = cal.DatesIn[sort.SearchInts(cal.DatesIn, runDate)]
 				}
 			}
-			nextRun, _ = time.ParseInLocation("20060102150405", fmt.Sprintf("%d", nextDateTime(runDate, current, H, M, S)), loc)
+			wall = fmt.Sprintf("%d", nextDateTime(runDate, current, H, M, S))
 			//ServerLogger.Printf(DebugColor, fmt.Sprintf("(2) currentTime:%s <= lastTime (nextDateTime) | runDate0:%d runDate:%d dates[i]:%d", current, runDate, runDate, dates[i]))
 		}
 	} else {
@@ -199,7 +249,7 @@ func (c Cron) NextStart(asof string) (time.Duration, time.Time, error) {
 				//runDate = cal.DatesIn[sort.SearchInts(cal.DatesIn, dates[i+1])-1]
 			}
 		}
-		nextRun, _ = time.ParseInLocation("20060102150405", fmt.Sprintf("%08d%06d", runDate, firstTime), loc)
+		wall = fmt.Sprintf("%08d%06d", runDate, firstTime)
 		//ServerLogger.Printf(DebugColor, fmt.Sprintf("(3) currentDate:%s != dates[i]     runDate:%d dates[i]:%d", current, runDate, dates[i]))
 	}
 
@@ -210,10 +260,90 @@ func (c Cron) NextStart(asof string) (time.Duration, time.Time, error) {
 			return time.Duration(math.MaxInt64), time.Time{}, err
 		}
 	}
-	if c.jitter > 0 {
-		nextRun = nextRun.Add(time.Second * time.Duration(rand.Intn(c.jitter)))
+	nextRun, resume := c.resolveDST(wall, loc, after)
+	if nextRun.IsZero() {
+		if resume.IsZero() {
+			return time.Duration(math.MaxInt64), time.Time{}, errors.New(fmt.Sprintf("invalid trigger time %s", wall))
+		}
+		return c.nextStart(resume, after)
+	}
+	return nextRun.Sub(after), nextRun, err
+}
+
+const wallLayout = "20060102150405"
+
+// resolveDST converts the wall clock time wall (YYYYMMDDhhmmss) to a time in loc according
+// to c.DSTPolicy. If the wall time is not to be run, the zero time is returned along with
+// the time from which the search for the next trigger should resume
+func (c Cron) resolveDST(wall string, loc *time.Location, after time.Time) (time.Time, time.Time) {
+	t, err := time.ParseInLocation(wallLayout, wall, loc)
+	if err != nil {
+		if wall[12:] == "60" {
+			// leap second, run at the start of the following minute
+			t, _ = time.ParseInLocation(wallLayout, wall[:12]+"59", loc)
+			return t.Add(time.Second), time.Time{}
+		}
+		return time.Time{}, time.Time{}
+	}
+
+	if t.Format(wallLayout) != wall {
+		// wall time falls in a spring forward gap, T is the moment of transition
+		start, T := t.ZoneBounds()
+		if t.Format(wallLayout) > wall {
+			T = start
+		}
+		switch c.DSTPolicy {
+		case DSTSkip:
+			if !c.IsRRule() && intIn(T.Hour(), c.Hours()) && intIn(T.Minute(), c.Minutes()) && intIn(T.Second(), c.Seconds()) && T.After(after) {
+				return T, time.Time{}
+			}
+			return time.Time{}, T
+		case DSTRunAtShift:
+			return T, time.Time{}
+		default:
+			// wall time using the offset prior to the transition, e.g. 02:30 EST is 03:30 EDT
+			u, _ := time.ParseInLocation(wallLayout, wall, time.UTC)
+			_, offset := T.Add(-time.Second).Zone()
+			return u.Add(-time.Duration(offset) * time.Second).In(loc), time.Time{}
+		}
+	}
+
+	// wall times repeated during fall back have a distinct first and second occurrence
+	first, second := t, t
+	if _, diff := fallBack(t); diff > 0 && t.Add(diff).Format(wallLayout) == wall {
+		second = t.Add(diff)
+	}
+	if start, _ := t.ZoneBounds(); !start.IsZero() {
+		_, offset0 := start.Add(-time.Second).Zone()
+		_, offset := t.Zone()
+		if diff := time.Duration(offset0-offset) * time.Second; diff > 0 && t.Add(-diff).Format(wallLayout) == wall {
+			first = t.Add(-diff)
+		}
+	}
+	if first.After(after) || first.Equal(second) {
+		return first, time.Time{}
+	}
+	if c.DSTPolicy == DSTRunTwice && second.After(after) {
+		return second, time.Time{}
+	}
+	// run once, resuming after the repeated period
+	T, diff := fallBack(first)
+	return time.Time{}, T.Add(diff - time.Millisecond)
+}
+
+// fallBack returns the end of the zone period containing t and the amount of time
+// repeated if the period ends with clocks being set back
+func fallBack(t time.Time) (time.Time, time.Duration) {
+	_, end := t.ZoneBounds()
+	if end.IsZero() {
+		return end, 0
+	}
+	_, offset := t.Zone()
+	_, offsetNext := end.Zone()
+	if offsetNext >= offset {
+		return end, 0
 	}
-	return nextRun.Sub(current), nextRun, err
+	return end, time.Duration(offset-offsetNext) * time.Second
 }
 
 func dateAsInt(t time.Time) int {
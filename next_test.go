@@ -0,0 +1,171 @@
+package rpeat
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// dstTriggers returns the first n triggers of spec in timezone under policy after from
+func dstTriggers(t *testing.T, spec, timezone string, policy DSTPolicy, from string, n int) []string {
+	c, err := ParseCron(spec, timezone, "", nil, false, false, 0)
+	if err != nil {
+		t.Fatalf("ParseCron(%q): %s", spec, err)
+	}
+	c.DSTPolicy = policy
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		t.Fatalf("LoadLocation(%s): %s", timezone, err)
+	}
+	after, err := time.ParseInLocation(wallLayout, from, loc)
+	if err != nil {
+		t.Fatalf("ParseInLocation(%s): %s", from, err)
+	}
+	var triggers []string
+	for i := 0; i < n; i++ {
+		_, next, err := c.NextStartAfter(after)
+		if err != nil {
+			t.Fatalf("NextStartAfter(%s): %s", after, err)
+		}
+		triggers = append(triggers, next.Format(time.RFC3339))
+		after = next
+	}
+	return triggers
+}
+
+func TestDSTPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		timezone string
+		policy   DSTPolicy
+		from     string
+		want     []string
+	}{
+		// America/New_York: 2024-03-10 02:00 EST -> 03:00 EDT, 2024-11-03 02:00 EDT -> 01:00 EST
+		{"ny spring run-once", "0 30 2 * * *", "America/New_York", DSTRunOnce, "20240309120000",
+			[]string{"2024-03-10T03:30:00-04:00", "2024-03-11T02:30:00-04:00"}},
+		{"ny spring run-twice", "0 30 2 * * *", "America/New_York", DSTRunTwice, "20240309120000",
+			[]string{"2024-03-10T03:30:00-04:00", "2024-03-11T02:30:00-04:00"}},
+		{"ny spring skip", "0 30 2 * * *", "America/New_York", DSTSkip, "20240309120000",
+			[]string{"2024-03-11T02:30:00-04:00", "2024-03-12T02:30:00-04:00"}},
+		{"ny spring run-at-shift", "0 30 2 * * *", "America/New_York", DSTRunAtShift, "20240309120000",
+			[]string{"2024-03-10T03:00:00-04:00", "2024-03-11T02:30:00-04:00"}},
+		{"ny fall run-once", "0 30 1 * * *", "America/New_York", DSTRunOnce, "20241102120000",
+			[]string{"2024-11-03T01:30:00-04:00", "2024-11-04T01:30:00-05:00"}},
+		{"ny fall run-twice", "0 30 1 * * *", "America/New_York", DSTRunTwice, "20241102120000",
+			[]string{"2024-11-03T01:30:00-04:00", "2024-11-03T01:30:00-05:00", "2024-11-04T01:30:00-05:00"}},
+		{"ny fall skip", "0 30 1 * * *", "America/New_York", DSTSkip, "20241102120000",
+			[]string{"2024-11-03T01:30:00-04:00", "2024-11-04T01:30:00-05:00"}},
+		{"ny fall run-at-shift", "0 30 1 * * *", "America/New_York", DSTRunAtShift, "20241102120000",
+			[]string{"2024-11-03T01:30:00-04:00", "2024-11-04T01:30:00-05:00"}},
+
+		// Europe/London: 2024-03-31 01:00 GMT -> 02:00 BST, 2024-10-27 02:00 BST -> 01:00 GMT
+		{"london spring run-once", "0 30 1 * * *", "Europe/London", DSTRunOnce, "20240330120000",
+			[]string{"2024-03-31T02:30:00+01:00", "2024-04-01T01:30:00+01:00"}},
+		{"london spring run-twice", "0 30 1 * * *", "Europe/London", DSTRunTwice, "20240330120000",
+			[]string{"2024-03-31T02:30:00+01:00", "2024-04-01T01:30:00+01:00"}},
+		{"london spring skip", "0 30 1 * * *", "Europe/London", DSTSkip, "20240330120000",
+			[]string{"2024-04-01T01:30:00+01:00", "2024-04-02T01:30:00+01:00"}},
+		{"london spring run-at-shift", "0 30 1 * * *", "Europe/London", DSTRunAtShift, "20240330120000",
+			[]string{"2024-03-31T02:00:00+01:00", "2024-04-01T01:30:00+01:00"}},
+		{"london fall run-once", "0 30 1 * * *", "Europe/London", DSTRunOnce, "20241026120000",
+			[]string{"2024-10-27T01:30:00+01:00", "2024-10-28T01:30:00Z"}},
+		{"london fall run-twice", "0 30 1 * * *", "Europe/London", DSTRunTwice, "20241026120000",
+			[]string{"2024-10-27T01:30:00+01:00", "2024-10-27T01:30:00Z", "2024-10-28T01:30:00Z"}},
+		{"london fall skip", "0 30 1 * * *", "Europe/London", DSTSkip, "20241026120000",
+			[]string{"2024-10-27T01:30:00+01:00", "2024-10-28T01:30:00Z"}},
+		{"london fall run-at-shift", "0 30 1 * * *", "Europe/London", DSTRunAtShift, "20241026120000",
+			[]string{"2024-10-27T01:30:00+01:00", "2024-10-28T01:30:00Z"}},
+
+		// Australia/Sydney: 2024-10-06 02:00 AEST -> 03:00 AEDT, 2024-04-07 03:00 AEDT -> 02:00 AEST
+		{"sydney spring run-once", "0 30 2 * * *", "Australia/Sydney", DSTRunOnce, "20241005120000",
+			[]string{"2024-10-06T03:30:00+11:00", "2024-10-07T02:30:00+11:00"}},
+		{"sydney spring run-twice", "0 30 2 * * *", "Australia/Sydney", DSTRunTwice, "20241005120000",
+			[]string{"2024-10-06T03:30:00+11:00", "2024-10-07T02:30:00+11:00"}},
+		{"sydney spring skip", "0 30 2 * * *", "Australia/Sydney", DSTSkip, "20241005120000",
+			[]string{"2024-10-07T02:30:00+11:00", "2024-10-08T02:30:00+11:00"}},
+		{"sydney spring run-at-shift", "0 30 2 * * *", "Australia/Sydney", DSTRunAtShift, "20241005120000",
+			[]string{"2024-10-06T03:00:00+11:00", "2024-10-07T02:30:00+11:00"}},
+		{"sydney fall run-once", "0 30 2 * * *", "Australia/Sydney", DSTRunOnce, "20240406120000",
+			[]string{"2024-04-07T02:30:00+11:00", "2024-04-08T02:30:00+10:00"}},
+		{"sydney fall run-twice", "0 30 2 * * *", "Australia/Sydney", DSTRunTwice, "20240406120000",
+			[]string{"2024-04-07T02:30:00+11:00", "2024-04-07T02:30:00+10:00", "2024-04-08T02:30:00+10:00"}},
+		{"sydney fall skip", "0 30 2 * * *", "Australia/Sydney", DSTSkip, "20240406120000",
+			[]string{"2024-04-07T02:30:00+11:00", "2024-04-08T02:30:00+10:00"}},
+		{"sydney fall run-at-shift", "0 30 2 * * *", "Australia/Sydney", DSTRunAtShift, "20240406120000",
+			[]string{"2024-04-07T02:30:00+11:00", "2024-04-08T02:30:00+10:00"}},
+	}
+	for _, tt := range tests {
+		got := dstTriggers(t, tt.spec, tt.timezone, tt.policy, tt.from, len(tt.want))
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%s: %s %s got %v; want %v", tt.name, tt.spec, tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestRRuleDSTPolicy(t *testing.T) {
+	gap := "DTSTART;TZID=America/New_York:20240310T023000 RRULE:FREQ=DAILY"
+	repeat := "DTSTART;TZID=America/New_York:20241101T013000 RRULE:FREQ=DAILY"
+	tests := []struct {
+		spec   string
+		policy DSTPolicy
+		from   string
+		want   []string
+	}{
+		{gap, DSTRunOnce, "20240309000000", []string{"2024-03-10T03:30:00-04:00", "2024-03-11T02:30:00-04:00"}},
+		{gap, DSTSkip, "20240309000000", []string{"2024-03-11T02:30:00-04:00", "2024-03-12T02:30:00-04:00"}},
+		{gap, DSTRunAtShift, "20240309000000", []string{"2024-03-10T03:00:00-04:00", "2024-03-11T02:30:00-04:00"}},
+		{repeat, DSTRunOnce, "20241102120000", []string{"2024-11-03T01:30:00-04:00", "2024-11-04T01:30:00-05:00"}},
+		{repeat, DSTRunTwice, "20241102120000", []string{"2024-11-03T01:30:00-04:00", "2024-11-03T01:30:00-05:00", "2024-11-04T01:30:00-05:00"}},
+	}
+	for _, tt := range tests {
+		got := dstTriggers(t, tt.spec, "America/New_York", tt.policy, tt.from, len(tt.want))
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%s %s got %v; want %v", tt.spec, tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestNextCronScheduleJitter(t *testing.T) {
+	c, err := ParseCron("59 59 23 * * *", "America/New_York", "", nil, false, false, 120)
+	if err != nil {
+		t.Fatalf("ParseCron: %s", err)
+	}
+	for i := 0; i < 20; i++ {
+		d, next, scheduled := NextCronSchedule([]Cron{c})
+		if got := scheduled.Format("15:04:05"); got != "23:59:59" {
+			t.Fatalf("scheduled %s; want 23:59:59", got)
+		}
+		if j := next.Sub(scheduled); j < 0 || j >= 120*time.Second {
+			t.Errorf("next %s is %s after scheduled %s; want jitter in [0s, 2m0s)", next, j, scheduled)
+		}
+		if d <= 0 || d > 25*time.Hour {
+			t.Errorf("d = %s", d)
+		}
+	}
+}
+
+func TestRRuleSubDailyFilters(t *testing.T) {
+	dtstart := "DTSTART;TZID=America/New_York:20240101T000000 RRULE:"
+	tests := []struct {
+		rule string
+		want []string
+	}{
+		{"FREQ=SECONDLY;BYMONTH=2;BYMONTHDAY=29", []string{"2028-02-29T00:00:00-05:00", "2028-02-29T00:00:01-05:00"}},
+		{"FREQ=MINUTELY;BYMONTH=2;BYMONTHDAY=29", []string{"2028-02-29T00:00:00-05:00", "2028-02-29T00:01:00-05:00"}},
+		{"FREQ=HOURLY;BYYEARDAY=-1", []string{"2026-12-31T00:00:00-05:00", "2026-12-31T01:00:00-05:00"}},
+		{"FREQ=SECONDLY;BYHOUR=3;BYMINUTE=15;BYSECOND=0,30", []string{"2026-01-02T03:15:00-05:00", "2026-01-02T03:15:30-05:00", "2026-01-03T03:15:00-05:00"}},
+		{"FREQ=MINUTELY;INTERVAL=7;BYDAY=SA", []string{"2026-01-03T00:03:00-05:00", "2026-01-03T00:10:00-05:00"}},
+	}
+	for _, tt := range tests {
+		start := time.Now()
+		got := dstTriggers(t, dtstart+tt.rule, "America/New_York", DSTRunOnce, "20260101120000", len(tt.want))
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("%s took %s", tt.rule, elapsed)
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%s got %v; want %v", tt.rule, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package rpeat
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Param declares a named parameter of a job, set in the environment of Cmd as Name.
+// Scheduled runs use Default, while a manual start may override the value, e.g.
+//
+//	"Params": [
+//	  {"Name": "REGION", "Default": "us", "Values": ["us", "eu", "apac"]},
+//	  {"Name": "LIMIT", "Type": "int", "Default": "100"},
+//	  {"Name": "TICKER", "Regex": "^[A-Z]{1,5}$", "Description": "symbol to load"}
+//	]
+//
+// Type is one of string (default), int, float, bool or date (YYYYMMDD). Values lists
+// the allowed values and Regex a pattern values must match. Overridden values are
+// validated, recorded in the Params of the JobHistory entry of the run and shown ahead
+// of the command in CmdEval.
+type Param struct {
+	Name        string   `json:"Name"`
+	Type        string   `json:"Type,omitempty"`
+	Default     string   `json:"Default,omitempty"`
+	Values      []string `json:"Values,omitempty"`
+	Regex       string   `json:"Regex,omitempty"`
+	Description string   `json:"Description,omitempty"`
+}
+
+var paramTypes = []string{"string", "int", "float", "bool", "date"}
+
+var paramName = regexp.MustCompile("^[_a-zA-Z][_0-9a-zA-Z]*$")
+
+// check returns an error if value is not valid for p
+func (p Param) check(value string) error {
+	var err error
+	switch p.Type {
+	case "int":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "float":
+		_, err = strconv.ParseFloat(value, 64)
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "date":
+		_, err = time.Parse("20060102", value)
+	}
+	if err != nil {
+		return errors.New(fmt.Sprintf("Param %s: %q is not a valid %s", p.Name, value, p.Type))
+	}
+	if len(p.Values) > 0 && !stringInSlice(value, p.Values) {
+		return errors.New(fmt.Sprintf("Param %s: %q is not one of %s", p.Name, value, strings.Join(p.Values, ", ")))
+	}
+	if p.Regex != "" {
+		if re, err := regexp.Compile(p.Regex); err == nil && !re.MatchString(value) {
+			return errors.New(fmt.Sprintf("Param %s: %q does not match %s", p.Name, value, p.Regex))
+		}
+	}
+	return nil
+}
+
+// validateParams checks the declared Params of a job, including their Default values
+func validateParams(params []Param) error {
+	seen := make(map[string]bool)
+	for _, p := range params {
+		if !paramName.MatchString(p.Name) {
+			return errors.New(fmt.Sprintf("Param name %q must be a valid environment variable name", p.Name))
+		}
+		if seen[p.Name] {
+			return errors.New(fmt.Sprintf("Param %s is declared more than once", p.Name))
+		}
+		seen[p.Name] = true
+		if p.Type != "" && !stringInSlice(p.Type, paramTypes) {
+			return errors.New(fmt.Sprintf("Param %s: unknown Type %s (expecting one of %s)", p.Name, p.Type, strings.Join(paramTypes, ", ")))
+		}
+		if p.Regex != "" {
+			if _, err := regexp.Compile(p.Regex); err != nil {
+				return errors.New(fmt.Sprintf("Param %s: invalid Regex: %s", p.Name, err))
+			}
+		}
+		if p.Default != "" {
+			if err := p.check(p.Default); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkParams validates the overrides of a manual start against the Params of the job
+func (job *Job) checkParams(overrides map[string]string) error {
+	for name, value := range overrides {
+		found := false
+		for _, p := range job.Params {
+			if p.Name == name {
+				if err := p.check(value); err != nil {
+					return err
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New(fmt.Sprintf("unknown Param %s", name))
+		}
+	}
+	return nil
+}
+
+// paramEnv returns the Params of the job as NAME=value, using the overrides of the
+// current run in place of Default
+func (job *Job) paramEnv() []string {
+	var env []string
+	for _, p := range job.Params {
+		value := p.Default
+		if v, ok := job.params[p.Name]; ok {
+			value = v
+		}
+		env = append(env, fmt.Sprintf("%s=%s", p.Name, value))
+	}
+	return env
+}
+
+// paramOverrides returns the overrides of the current run as sorted NAME=value pairs
+func (job *Job) paramOverrides() []string {
+	var overrides []string
+	for name, value := range job.params {
+		overrides = append(overrides, fmt.Sprintf("%s=%s", name, value))
+	}
+	sort.Strings(overrides)
+	return overrides
+}
@@ -0,0 +1,311 @@
+package rpeat
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Pool limits the number of slots in use by running jobs, e.g. database connections
+// or software licences. Pools are defined by name in ServerConfig.Pools and used by
+// jobs with Pool and PoolSlots. A triggered job waits in the JQueued state until the
+// slots it requires are available, with waiting jobs ordered by effective priority
+// (Priority increased by one for each PriorityAging waited) and then by time queued.
+//
+// JobsControl.MaxConcurrent uses an unnamed Pool to limit the number of jobs of a
+// job array (Jobs) running at once.
+type Pool struct {
+	Name  string
+	Slots int
+
+	used  int
+	queue []*poolRequest
+	lock  sync.Mutex
+}
+
+// heldSlots records the slots of a pool acquired by a job run
+type heldSlots struct {
+	pool  *Pool
+	slots int
+}
+
+type poolRequest struct {
+	job    *Job
+	slots  int
+	queued time.Time
+	ready  chan bool
+}
+
+// PriorityAging is the time waited in a pool queue that raises the effective
+// priority of a job by one, so low priority jobs are run eventually
+var PriorityAging = 5 * time.Minute
+
+var pools = struct {
+	m    map[string]*Pool
+	lock sync.Mutex
+}{m: make(map[string]*Pool)}
+
+// SetPools defines the named pools available to jobs. Pools already defined keep
+// their running and queued jobs, with queued jobs started if slots are added
+func SetPools(slots map[string]int) {
+	pools.lock.Lock()
+	defer pools.lock.Unlock()
+	for name, n := range slots {
+		p, ok := pools.m[name]
+		if !ok {
+			pools.m[name] = &Pool{Name: name, Slots: n}
+			continue
+		}
+		p.lock.Lock()
+		p.Slots = n
+		p.dispatch()
+		p.lock.Unlock()
+	}
+}
+
+// getPool returns the pool name, or nil if undefined
+func getPool(name string) *Pool {
+	pools.lock.Lock()
+	defer pools.lock.Unlock()
+	return pools.m[name]
+}
+
+// acquire waits until slots are available, returning the slots granted or false if
+// cancel is closed first. queued is called if the request has to wait
+func (p *Pool) acquire(job *Job, slots int, cancel <-chan bool, queued func()) (int, bool) {
+	p.lock.Lock()
+	if slots > p.Slots {
+		ServerLogger.Printf("[Pool] %s:%s requires %d slots of %s with %d slots - using %d", job.JobUUID, job.Name, slots, p, p.Slots, p.Slots)
+		slots = p.Slots
+	}
+	r := &poolRequest{job: job, slots: slots, queued: time.Now(), ready: make(chan bool, 1)}
+	p.queue = append(p.queue, r)
+	p.dispatch()
+	select {
+	case <-r.ready:
+		p.lock.Unlock()
+		return slots, true
+	default:
+	}
+	p.updatePositions()
+	ServerLogger.Printf("[Pool] %s:%s queued for %s (%d/%d slots in use, position %d of %d)", job.JobUUID, job.Name, p, p.used, p.Slots, job.QueuePosition, len(p.queue))
+	p.lock.Unlock()
+
+	if queued != nil {
+		queued()
+	}
+	select {
+	case <-r.ready:
+		return slots, true
+	case <-cancel:
+		p.lock.Lock()
+		removed := p.remove(r)
+		p.lock.Unlock()
+		if !removed { // slots were granted before the cancel
+			p.release(slots)
+		}
+		ServerLogger.Printf("[Pool] %s:%s removed from %s queue", job.JobUUID, job.Name, p)
+		return 0, false
+	}
+}
+
+// release returns slots granted by acquire to the pool, starting queued jobs in order
+func (p *Pool) release(slots int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.used -= slots
+	if p.used < 0 {
+		p.used = 0
+	}
+	p.dispatch()
+}
+
+// dispatch grants slots to queued requests in priority order, stopping at the first that
+// does not fit so jobs requiring several slots are not starved. Requires p.lock
+func (p *Pool) dispatch() {
+	p.sortQueue()
+	n := 0
+	for len(p.queue) > 0 && p.used+p.queue[0].slots <= p.Slots {
+		r := p.queue[0]
+		p.queue = p.queue[1:]
+		p.used += r.slots
+		r.job.setQueuePosition(0, 0)
+		r.ready <- true
+		n++
+	}
+	if n > 0 {
+		p.updatePositions()
+	}
+}
+
+// remove removes r from the queue, returning false if not queued. Requires p.lock
+func (p *Pool) remove(r *poolRequest) bool {
+	for i := range p.queue {
+		if p.queue[i] == r {
+			p.queue = append(p.queue[:i], p.queue[i+1:]...)
+			r.job.setQueuePosition(0, 0)
+			p.updatePositions()
+			return true
+		}
+	}
+	return false
+}
+
+// updatePositions sets the QueuePosition (from 1) and EffectivePriority of queued
+// jobs. Requires p.lock
+func (p *Pool) updatePositions() {
+	p.sortQueue()
+	now := time.Now()
+	for i, r := range p.queue {
+		r.job.setQueuePosition(i+1, r.effectivePriority(now))
+	}
+}
+
+// sortQueue orders queued requests by effective priority and then by time queued.
+// Requires p.lock
+func (p *Pool) sortQueue() {
+	now := time.Now()
+	sort.SliceStable(p.queue, func(i, j int) bool {
+		pi, pj := p.queue[i].effectivePriority(now), p.queue[j].effectivePriority(now)
+		if pi != pj {
+			return pi > pj
+		}
+		return p.queue[i].queued.Before(p.queue[j].queued)
+	})
+}
+
+// effectivePriority is the job Priority increased by one for each PriorityAging waited
+func (r *poolRequest) effectivePriority(now time.Time) int {
+	priority := r.job.Priority
+	if PriorityAging > 0 {
+		priority += int(now.Sub(r.queued) / PriorityAging)
+	}
+	return priority
+}
+
+func (p *Pool) String() string {
+	if p.Name == "" {
+		return "MaxConcurrent"
+	}
+	return fmt.Sprintf("Pool %s", p.Name)
+}
+
+// setQueuePosition records the position and effective priority of the job in a pool
+// queue, with a position of 0 once the job is no longer queued
+func (job *Job) setQueuePosition(n, priority int) {
+	job.Lock()
+	changed := job.QueuePosition != n || job.EffectivePriority != priority
+	job.QueuePosition = n
+	job.EffectivePriority = priority
+	if n == 0 {
+		job.QueuedUNIX = 0
+	} else if job.QueuedUNIX == 0 {
+		job.QueuedUNIX = time.Now().Unix()
+	}
+	job.modified = time.Now().Unix()
+	job.Unlock()
+	if changed && n > 0 && job.updates != nil {
+		go job.sendUpdateClient()
+	}
+}
+
+// jobPools returns the pools used by the job, in the order they are acquired
+func (job *Job) jobPools() []*Pool {
+	var p []*Pool
+	if job.Pool != "" {
+		if pool := getPool(job.Pool); pool != nil {
+			p = append(p, pool)
+		} else {
+			ServerLogger.Printf("[Pool] %s:%s Pool %s is not defined in ServerConfig.Pools - ignored", job.JobUUID, job.Name, job.Pool)
+		}
+	}
+	if jc := job.JobsControl; jc != nil && jc.MaxConcurrent > 0 && !job.isController() {
+		jc.lock.Lock()
+		if jc.pool == nil {
+			jc.pool = &Pool{Slots: jc.MaxConcurrent}
+		}
+		p = append(p, jc.pool)
+		jc.lock.Unlock()
+	}
+	return p
+}
+
+// poolSlots returns the number of slots the job requires of pool, with a PoolSlots
+// of 0 (unset) requiring 1
+func (job *Job) poolSlots(pool *Pool) int {
+	if pool.Name == "" || job.PoolSlots == 0 {
+		return 1
+	}
+	return job.PoolSlots
+}
+
+// acquireSlots waits for the slots required by the job, moving the job to JQueued if
+// it is not running already. The slots held are returned, to be freed by releaseSlots
+// once the run ends. False is returned if cancel is closed while waiting, with no
+// slots held
+func (job *Job) acquireSlots(cancel <-chan bool) ([]heldSlots, bool) {
+	return job.takeSlots(cancel, func() {
+		if !job.IsRunning && job.setJobState(JQueued) == nil {
+			job.sendUpdate()
+		}
+	})
+}
+
+// takeSlots acquires the slots of each pool used by the job, calling queued if it
+// has to wait
+func (job *Job) takeSlots(cancel <-chan bool, queued func()) ([]heldSlots, bool) {
+	var held []heldSlots
+	for _, p := range job.jobPools() {
+		n, ok := p.acquire(job, job.poolSlots(p), cancel, queued)
+		if !ok {
+			releaseSlots(held)
+			return nil, false
+		}
+		held = append(held, heldSlots{pool: p, slots: n})
+	}
+	return held, true
+}
+
+// releaseSlots returns the slots acquired by acquireSlots to the pools they were
+// taken from, which may no longer be used by the job after a configuration reload
+func releaseSlots(held []heldSlots) {
+	for _, h := range held {
+		h.pool.release(h.slots)
+	}
+}
+
+// waitForSlots waits for the slots required by a triggered (non-concurrent) job,
+// which may be cancelled by cancelQueued. The slots held are recorded on the job
+// and freed by releaseHeldSlots
+func (job *Job) waitForSlots() bool {
+	cancel := make(chan bool)
+	job.Lock()
+	job.queueCancel = cancel
+	job.Unlock()
+	held, ok := job.acquireSlots(cancel)
+	job.Lock()
+	job.queueCancel = nil
+	job.slots = held
+	job.Unlock()
+	return ok
+}
+
+// releaseHeldSlots frees the slots acquired by waitForSlots
+func (job *Job) releaseHeldSlots() {
+	job.Lock()
+	held := job.slots
+	job.slots = nil
+	job.Unlock()
+	releaseSlots(held)
+}
+
+// cancelQueued removes a job waiting in waitForSlots from its pool queue
+func (job *Job) cancelQueued() {
+	job.Lock()
+	defer job.Unlock()
+	if job.queueCancel != nil {
+		close(job.queueCancel)
+		job.queueCancel = nil
+	}
+}
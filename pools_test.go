@@ -0,0 +1,154 @@
+package rpeat
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPoolDispatch(t *testing.T) {
+	aging := PriorityAging
+	PriorityAging = time.Minute
+	defer func() { PriorityAging = aging }()
+
+	type req struct {
+		name     string
+		priority int
+		slots    int
+		waited   time.Duration
+	}
+	tests := []struct {
+		name    string
+		slots   int
+		used    int
+		queue   []req
+		granted []string
+		queued  []string
+	}{
+		{"priority", 1, 0,
+			[]req{{"a", 0, 1, 2 * time.Second}, {"b", 5, 1, time.Second}, {"c", 0, 1, 3 * time.Second}},
+			[]string{"b"}, []string{"c", "a"}},
+		{"queued time", 2, 0,
+			[]req{{"a", 1, 1, time.Second}, {"b", 1, 1, 3 * time.Second}, {"c", 1, 1, 2 * time.Second}},
+			[]string{"b", "c"}, []string{"a"}},
+		{"aging", 1, 0,
+			[]req{{"a", 1, 1, time.Second}, {"b", 0, 1, 2*time.Minute + time.Second}},
+			[]string{"b"}, []string{"a"}},
+		{"full", 2, 2,
+			[]req{{"a", 0, 1, time.Second}},
+			nil, []string{"a"}},
+		{"several", 3, 0,
+			[]req{{"a", 0, 1, 3 * time.Second}, {"b", 0, 2, 2 * time.Second}, {"c", 0, 1, time.Second}},
+			[]string{"a", "b"}, []string{"c"}},
+		{"stop at first that does not fit", 3, 1,
+			[]req{{"big", 5, 3, time.Second}, {"small", 0, 1, 2 * time.Second}},
+			nil, []string{"big", "small"}},
+		{"stop after grants", 4, 1,
+			[]req{{"a", 2, 1, time.Second}, {"big", 1, 3, time.Second}, {"small", 0, 1, time.Second}},
+			[]string{"a"}, []string{"big", "small"}},
+	}
+	for _, tt := range tests {
+		p := &Pool{Name: "test", Slots: tt.slots, used: tt.used}
+		now := time.Now()
+		var reqs []*poolRequest
+		for _, r := range tt.queue {
+			reqs = append(reqs, &poolRequest{job: &Job{Name: r.name, Priority: r.priority}, slots: r.slots, queued: now.Add(-r.waited), ready: make(chan bool, 1)})
+		}
+		p.queue = append(p.queue, reqs...)
+		p.lock.Lock()
+		p.dispatch()
+		p.lock.Unlock()
+
+		var granted, queued []string
+		used := tt.used
+		for _, r := range reqs {
+			select {
+			case <-r.ready:
+				granted = append(granted, r.job.Name)
+				used += r.slots
+			default:
+			}
+		}
+		for _, r := range p.queue {
+			queued = append(queued, r.job.Name)
+		}
+		if !reflect.DeepEqual(granted, tt.granted) {
+			t.Errorf("%s: granted %v; want %v", tt.name, granted, tt.granted)
+		}
+		if !reflect.DeepEqual(queued, tt.queued) {
+			t.Errorf("%s: queued %v; want %v", tt.name, queued, tt.queued)
+		}
+		if p.used != used {
+			t.Errorf("%s: %d slots used; want %d", tt.name, p.used, used)
+		}
+	}
+}
+
+func TestPoolAcquireRelease(t *testing.T) {
+	initServerLogging(ioutil.Discard)
+	p := &Pool{Name: "test", Slots: 2}
+	a, b := &Job{Name: "a"}, &Job{Name: "b", Priority: 1}
+	c := &Job{Name: "c"}
+
+	// requests larger than the pool are limited to its size
+	if n, ok := p.acquire(a, 3, nil, nil); !ok || n != 2 {
+		t.Fatalf("acquire 3 of 2 slots: %d %t; want 2 true", n, ok)
+	}
+
+	order := make(chan string, 2)
+	wait := func(job *Job, cancel chan bool) {
+		if _, ok := p.acquire(job, 1, cancel, nil); ok {
+			order <- job.Name
+		} else {
+			order <- "cancelled " + job.Name
+		}
+	}
+	cancel := make(chan bool)
+	go wait(c, cancel)
+	waitQueued(t, p, 1)
+	go wait(b, nil)
+	waitQueued(t, p, 2)
+
+	close(cancel)
+	if got := <-order; got != "cancelled c" {
+		t.Fatalf("cancel: %s; want cancelled c", got)
+	}
+	p.release(2)
+	if got := <-order; got != "b" {
+		t.Fatalf("release: %s granted; want b", got)
+	}
+	p.release(1)
+	if p.used != 0 || len(p.queue) != 0 {
+		t.Errorf("after release: %d used, %d queued; want 0, 0", p.used, len(p.queue))
+	}
+
+	// slots are released to the pool they were acquired from, after a reload
+	jc := &JobsControl{MaxConcurrent: 1}
+	d := &Job{Name: "d", JobsControl: jc}
+	held, ok := d.takeSlots(nil, nil)
+	if !ok || len(held) != 1 {
+		t.Fatalf("takeSlots: %v %t", held, ok)
+	}
+	old := jc.pool
+	jc.pool = nil
+	releaseSlots(held)
+	if old.used != 0 {
+		t.Errorf("released %d slots; %d used", held[0].slots, old.used)
+	}
+}
+
+// waitQueued waits for n requests to be queued in p
+func waitQueued(t *testing.T, p *Pool, n int) {
+	queued := 0
+	for i := 0; i < 500; i++ {
+		p.lock.Lock()
+		queued = len(p.queue)
+		p.lock.Unlock()
+		if queued == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%d requests queued; want %d", queued, n)
+}
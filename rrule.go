@@ -1,3 +1,901 @@
 package rpeat
 
-//type RRule interface{}
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RRule is an RFC 5545 (iCalendar) recurrence rule.  Rules are written as
+// one or more whitespace separated properties, with the RRULE property
+// required and DTSTART, EXDATE and RDATE optional:
+//
+//	DTSTART:20240109T090000 RRULE:FREQ=MONTHLY;INTERVAL=3;BYDAY=2TU
+//	DTSTART;TZID=Europe/London:20240105T170000 RRULE:FREQ=WEEKLY;INTERVAL=3 EXDATE:20240315T170000
+//	FREQ=MONTHLY;BYMONTHDAY=-1;BYHOUR=18
+//
+// The rule part names (FREQ, INTERVAL, COUNT, UNTIL, WKST, BYMONTH,
+// BYYEARDAY, BYMONTHDAY, BYDAY, BYHOUR, BYMINUTE, BYSECOND, BYSETPOS)
+// follow the RFC.  Times without a trailing Z or TZID are interpretted
+// in the Timezone of the job.  If DTSTART is missing, midnight of the day
+// the rule is parsed is used. As this changes with each reload or restart,
+// rules with INTERVAL > 1 or COUNT require a DTSTART to anchor the series.
+//
+// DAILY and longer rules recur at the wall clock time of DTSTART, with
+// occurrences affected by daylight saving transitions resolved by the
+// DSTPolicy of the trigger as for cron triggers. HOURLY and shorter rules
+// recur at fixed intervals of elapsed time.
+type RRule struct {
+	Freq       RRuleFreq
+	Interval   int
+	Count      int
+	Until      time.Time
+	WkSt       time.Weekday
+	ByMonth    []int
+	ByYearDay  []int
+	ByMonthDay []int
+	ByDay      []RRuleDay
+	ByHour     []int
+	ByMinute   []int
+	BySecond   []int
+	BySetPos   []int
+	DtStart    time.Time
+	ExDate     []time.Time
+	RDate      []time.Time
+
+	// dates only (VALUE=DATE) EXDATE values as YYYYMMDD
+	exDays []int
+	spec   string
+
+	// wall clock of DTSTART as a floating time in UTC, which unlike DtStart is not
+	// normalised when in a daylight saving gap, and the location of DtStart
+	wall time.Time
+	loc  *time.Location
+}
+
+type RRuleFreq int
+
+const (
+	RRSecondly RRuleFreq = iota
+	RRMinutely
+	RRHourly
+	RRDaily
+	RRWeekly
+	RRMonthly
+	RRYearly
+)
+
+func (f RRuleFreq) String() string {
+	names := [...]string{"SECONDLY", "MINUTELY", "HOURLY", "DAILY", "WEEKLY", "MONTHLY", "YEARLY"}
+	if f < RRSecondly || f > RRYearly {
+		return "Unknown"
+	}
+	return names[f]
+}
+
+// RRuleDay is a BYDAY entry, e.g. MO, 2TU or -1FR. N is zero when
+// no ordinal is given
+type RRuleDay struct {
+	N       int
+	Weekday time.Weekday
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// maximum number of years searched for the next occurrence, matches nextYearDates
+const rruleHorizon = 5
+
+func isRRuleSpec(spec string) bool {
+	s := strings.ToUpper(strings.TrimSpace(spec))
+	return strings.HasPrefix(s, "RRULE:") || strings.HasPrefix(s, "DTSTART") || strings.HasPrefix(s, "FREQ=")
+}
+
+func rruleError(spec, msg string) CronError {
+	return CronError{Spec: spec, Msg: msg, Exception: RRuleSyntaxError}
+}
+
+// ParseRRule parses an RFC 5545 recurrence rule (see RRule) with
+// floating times interpretted in timezone
+func ParseRRule(spec string, timezone string) (RRule, error) {
+	var rr RRule
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	rr.spec = spec
+	rr.Interval = 1
+	rr.WkSt = time.Monday
+
+	var hasRule, hasDtStart bool
+	for _, prop := range strings.Fields(spec) {
+		name := strings.ToUpper(prop)
+		if strings.HasPrefix(name, "FREQ=") {
+			prop = "RRULE:" + prop
+			name = "RRULE:" + name
+		}
+		i := strings.Index(prop, ":")
+		if i < 0 {
+			return rr, rruleError(spec, fmt.Sprintf("malformed property %s", prop))
+		}
+		params := strings.Split(name[:i], ";")
+		value := prop[i+1:]
+		switch params[0] {
+		case "RRULE":
+			if hasRule {
+				return rr, rruleError(spec, "only one RRULE is allowed per rule")
+			}
+			hasRule = true
+			if err := rr.parseRule(value, loc); err != nil {
+				return rr, err
+			}
+		case "DTSTART":
+			ts, _, err := parseRRuleTimes(prop[:i], value, loc)
+			if err != nil || len(ts) != 1 {
+				return rr, rruleError(spec, fmt.Sprintf("malformed DTSTART %s", value))
+			}
+			rr.DtStart = ts[0]
+			rr.wall = rruleWall(value, ts[0])
+			hasDtStart = true
+		case "EXDATE":
+			ts, dateOnly, err := parseRRuleTimes(prop[:i], value, loc)
+			if err != nil {
+				return rr, rruleError(spec, fmt.Sprintf("malformed EXDATE %s", value))
+			}
+			if dateOnly {
+				for _, t := range ts {
+					rr.exDays = append(rr.exDays, dateAsInt(t))
+				}
+			} else {
+				rr.ExDate = append(rr.ExDate, ts...)
+			}
+		case "RDATE":
+			ts, _, err := parseRRuleTimes(prop[:i], value, loc)
+			if err != nil {
+				return rr, rruleError(spec, fmt.Sprintf("malformed RDATE %s", value))
+			}
+			rr.RDate = append(rr.RDate, ts...)
+		default:
+			return rr, rruleError(spec, fmt.Sprintf("unsupported property %s", params[0]))
+		}
+	}
+	if !hasRule {
+		return rr, rruleError(spec, "missing RRULE")
+	}
+	if !hasDtStart {
+		if rr.Interval > 1 || rr.Count > 0 {
+			return rr, rruleError(spec, "DTSTART is required with INTERVAL > 1 or COUNT")
+		}
+		y, m, d := now(timezone, "").Date()
+		rr.DtStart = time.Date(y, m, d, 0, 0, 0, 0, loc)
+		rr.wall = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	rr.loc = rr.DtStart.Location()
+	if !rr.Until.IsZero() && rr.Until.Before(rr.DtStart) {
+		return rr, rruleError(spec, "UNTIL is before DTSTART")
+	}
+	sort.Slice(rr.RDate, func(i, j int) bool { return rr.RDate[i].Before(rr.RDate[j]) })
+	return rr, nil
+}
+
+func (rr *RRule) parseRule(rule string, loc *time.Location) error {
+	var hasFreq bool
+	var err error
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return rruleError(rr.spec, fmt.Sprintf("malformed rule part %s", part))
+		}
+		key, val := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		switch key {
+		case "FREQ":
+			hasFreq = true
+			switch val {
+			case "SECONDLY":
+				rr.Freq = RRSecondly
+			case "MINUTELY":
+				rr.Freq = RRMinutely
+			case "HOURLY":
+				rr.Freq = RRHourly
+			case "DAILY":
+				rr.Freq = RRDaily
+			case "WEEKLY":
+				rr.Freq = RRWeekly
+			case "MONTHLY":
+				rr.Freq = RRMonthly
+			case "YEARLY":
+				rr.Freq = RRYearly
+			default:
+				return rruleError(rr.spec, fmt.Sprintf("unrecognized FREQ %s", val))
+			}
+		case "INTERVAL":
+			rr.Interval, err = strconv.Atoi(val)
+			if err != nil || rr.Interval < 1 {
+				return rruleError(rr.spec, fmt.Sprintf("INTERVAL must be a positive integer, found %s", val))
+			}
+		case "COUNT":
+			rr.Count, err = strconv.Atoi(val)
+			if err != nil || rr.Count < 1 {
+				return rruleError(rr.spec, fmt.Sprintf("COUNT must be a positive integer, found %s", val))
+			}
+		case "UNTIL":
+			ts, dateOnly, err := parseRRuleTimes("UNTIL", val, loc)
+			if err != nil || len(ts) != 1 {
+				return rruleError(rr.spec, fmt.Sprintf("malformed UNTIL %s", val))
+			}
+			rr.Until = ts[0]
+			if dateOnly {
+				// inclusive of the final date
+				rr.Until = rr.Until.AddDate(0, 0, 1).Add(-time.Second)
+			}
+		case "WKST":
+			wd, ok := rruleWeekdays[val]
+			if !ok {
+				return rruleError(rr.spec, fmt.Sprintf("unrecognized WKST %s", val))
+			}
+			rr.WkSt = wd
+		case "BYMONTH":
+			rr.ByMonth, err = parseRRuleInts(val, 1, 12, false)
+		case "BYYEARDAY":
+			rr.ByYearDay, err = parseRRuleInts(val, 1, 366, true)
+		case "BYMONTHDAY":
+			rr.ByMonthDay, err = parseRRuleInts(val, 1, 31, true)
+		case "BYHOUR":
+			rr.ByHour, err = parseRRuleInts(val, 0, 23, false)
+		case "BYMINUTE":
+			rr.ByMinute, err = parseRRuleInts(val, 0, 59, false)
+		case "BYSECOND":
+			rr.BySecond, err = parseRRuleInts(val, 0, 60, false)
+		case "BYSETPOS":
+			rr.BySetPos, err = parseRRuleInts(val, 1, 366, true)
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				if len(d) < 2 {
+					return rruleError(rr.spec, fmt.Sprintf("malformed BYDAY %s", d))
+				}
+				wd, ok := rruleWeekdays[d[len(d)-2:]]
+				if !ok {
+					return rruleError(rr.spec, fmt.Sprintf("unrecognized BYDAY weekday %s", d))
+				}
+				var n int
+				if len(d) > 2 {
+					n, err = strconv.Atoi(d[:len(d)-2])
+					if err != nil || n == 0 || n < -53 || n > 53 {
+						return rruleError(rr.spec, fmt.Sprintf("malformed BYDAY ordinal %s", d))
+					}
+				}
+				rr.ByDay = append(rr.ByDay, RRuleDay{N: n, Weekday: wd})
+			}
+		default:
+			return rruleError(rr.spec, fmt.Sprintf("unsupported rule part %s", key))
+		}
+		if err != nil {
+			return rruleError(rr.spec, fmt.Sprintf("%s: %s", key, err))
+		}
+	}
+	if !hasFreq {
+		return rruleError(rr.spec, "FREQ is required")
+	}
+	if rr.Count > 0 && !rr.Until.IsZero() {
+		return rruleError(rr.spec, "COUNT and UNTIL may not both be specified")
+	}
+	for _, d := range rr.ByDay {
+		if d.N != 0 && rr.Freq != RRMonthly && rr.Freq != RRYearly {
+			return rruleError(rr.spec, "BYDAY ordinals are only valid with FREQ=MONTHLY or FREQ=YEARLY")
+		}
+	}
+	return nil
+}
+
+func parseRRuleInts(val string, min, max int, negative bool) ([]int, error) {
+	var ints []int
+	for _, v := range strings.Split(val, ",") {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return ints, err
+		}
+		a := i
+		if negative && a < 0 {
+			a = -a
+		}
+		if a < min || a > max {
+			return ints, fmt.Errorf("%d out of range", i)
+		}
+		ints = append(ints, i)
+	}
+	return ints, nil
+}
+
+// parseRRuleTimes parses comma separated DATE or DATE-TIME values, honoring any TZID parameter
+func parseRRuleTimes(params, value string, loc *time.Location) ([]time.Time, bool, error) {
+	var ts []time.Time
+	var dateOnly bool
+	for _, p := range strings.Split(params, ";")[1:] {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) == 2 && strings.ToUpper(kv[0]) == "TZID" {
+			tzloc, err := time.LoadLocation(kv[1])
+			if err != nil {
+				return ts, dateOnly, err
+			}
+			loc = tzloc
+		}
+	}
+	for _, v := range strings.Split(value, ",") {
+		var t time.Time
+		var err error
+		v = strings.ToUpper(v)
+		switch {
+		case strings.HasSuffix(v, "Z"):
+			t, err = time.Parse("20060102T150405Z", v)
+		case len(v) == 8:
+			t, err = time.ParseInLocation("20060102", v, loc)
+			dateOnly = true
+		case len(v) == 14:
+			t, err = time.ParseInLocation("20060102150405", v, loc)
+		default:
+			t, err = time.ParseInLocation("20060102T150405", v, loc)
+		}
+		if err != nil {
+			return ts, dateOnly, err
+		}
+		ts = append(ts, t.In(loc))
+	}
+	return ts, dateOnly, nil
+}
+
+// rruleWall returns the wall clock of the DTSTART value of t as a floating time in UTC
+func rruleWall(value string, t time.Time) time.Time {
+	v := strings.ToUpper(value)
+	for _, layout := range []string{"20060102T150405", "20060102150405", "20060102"} {
+		if w, err := time.Parse(layout, v); err == nil {
+			return w
+		}
+	}
+	return floating(t)
+}
+
+// floating returns the wall clock of t as a time in UTC
+func floating(t time.Time) time.Time {
+	y, m, d := t.Date()
+	hh, mm, ss := t.Clock()
+	return time.Date(y, m, d, hh, mm, ss, 0, time.UTC)
+}
+
+// isFloating is true if the occurrences of the rule are wall clock times, see RRule
+func (rr RRule) isFloating() bool {
+	return rr.Freq >= RRDaily
+}
+
+// periodStart returns the start of the i-th FREQ*INTERVAL period from DTSTART, as a
+// floating time if the rule isFloating
+func (rr RRule) periodStart(i int) time.Time {
+	s := rr.DtStart
+	if rr.isFloating() {
+		s = rr.wall
+	}
+	loc := s.Location()
+	n := i * rr.Interval
+	switch rr.Freq {
+	case RRYearly:
+		return time.Date(s.Year()+n, 1, 1, 0, 0, 0, 0, loc)
+	case RRMonthly:
+		return time.Date(s.Year(), s.Month()+time.Month(n), 1, 0, 0, 0, 0, loc)
+	case RRWeekly:
+		back := (int(s.Weekday()) - int(rr.WkSt) + 7) % 7
+		return time.Date(s.Year(), s.Month(), s.Day()-back+7*n, 0, 0, 0, 0, loc)
+	case RRDaily:
+		return time.Date(s.Year(), s.Month(), s.Day()+n, 0, 0, 0, 0, loc)
+	case RRHourly:
+		return s.Truncate(time.Second).Add(time.Duration(n) * time.Hour)
+	case RRMinutely:
+		return s.Truncate(time.Second).Add(time.Duration(n) * time.Minute)
+	default:
+		return s.Truncate(time.Second).Add(time.Duration(n) * time.Second)
+	}
+}
+
+// expand returns all (sorted) candidate occurrences within the period starting at p
+func (rr RRule) expand(p time.Time) []time.Time {
+	var occ []time.Time
+	loc := p.Location()
+	s := rr.wall
+
+	hours := rr.ByHour
+	if len(hours) == 0 {
+		hours = []int{s.Hour()}
+	}
+	mins := rr.ByMinute
+	if len(mins) == 0 {
+		mins = []int{s.Minute()}
+	}
+	secs := rr.BySecond
+	if len(secs) == 0 {
+		secs = []int{s.Second()}
+	}
+
+	switch rr.Freq {
+	case RRYearly, RRMonthly, RRWeekly, RRDaily:
+		for _, d := range rr.expandDates(p) {
+			for _, h := range hours {
+				for _, m := range mins {
+					for _, sec := range secs {
+						occ = append(occ, time.Date(d.Year(), d.Month(), d.Day(), h, m, sec, 0, loc))
+					}
+				}
+			}
+		}
+	case RRHourly:
+		if rr.dateMatches(p) && intIn(p.Hour(), rr.ByHour) {
+			for _, m := range mins {
+				for _, sec := range secs {
+					occ = append(occ, time.Date(p.Year(), p.Month(), p.Day(), p.Hour(), m, sec, 0, loc))
+				}
+			}
+		}
+	case RRMinutely:
+		if rr.dateMatches(p) && intIn(p.Hour(), rr.ByHour) && intIn(p.Minute(), rr.ByMinute) {
+			for _, sec := range secs {
+				occ = append(occ, time.Date(p.Year(), p.Month(), p.Day(), p.Hour(), p.Minute(), sec, 0, loc))
+			}
+		}
+	case RRSecondly:
+		if rr.dateMatches(p) && intIn(p.Hour(), rr.ByHour) && intIn(p.Minute(), rr.ByMinute) && intIn(p.Second(), rr.BySecond) {
+			occ = append(occ, p)
+		}
+	}
+	sort.Slice(occ, func(i, j int) bool { return occ[i].Before(occ[j]) })
+
+	if len(rr.BySetPos) > 0 && len(occ) > 0 {
+		var setpos []time.Time
+		for _, pos := range rr.BySetPos {
+			i := pos - 1
+			if pos < 0 {
+				i = len(occ) + pos
+			}
+			if i >= 0 && i < len(occ) {
+				setpos = append(setpos, occ[i])
+			}
+		}
+		sort.Slice(setpos, func(i, j int) bool { return setpos[i].Before(setpos[j]) })
+		occ = setpos[:0]
+		for i := range setpos {
+			if i > 0 && setpos[i].Equal(setpos[i-1]) {
+				continue
+			}
+			occ = append(occ, setpos[i])
+		}
+	}
+	return occ
+}
+
+// expandDates returns the dates (at midnight) within a YEARLY, MONTHLY, WEEKLY or DAILY period
+func (rr RRule) expandDates(p time.Time) []time.Time {
+	var dates []time.Time
+	loc := p.Location()
+	s := rr.wall
+	y := p.Year()
+
+	switch rr.Freq {
+	case RRYearly:
+		switch {
+		case len(rr.ByYearDay) > 0:
+			ndays := time.Date(y, 12, 31, 0, 0, 0, 0, loc).YearDay()
+			for yd := 1; yd <= ndays; yd++ {
+				d := time.Date(y, 1, yd, 0, 0, 0, 0, loc)
+				if (intIn(yd, rr.ByYearDay) || intIn(yd-ndays-1, rr.ByYearDay)) && rr.dateMatches(d) {
+					dates = append(dates, d)
+				}
+			}
+		case len(rr.ByMonth) > 0 || len(rr.ByMonthDay) > 0:
+			months := rr.ByMonth
+			if len(months) == 0 {
+				months = makerange(1, 12, 1)
+			}
+			for _, m := range months {
+				dates = append(dates, rr.monthDates(y, m, loc)...)
+			}
+		case len(rr.ByDay) > 0:
+			ndays := time.Date(y, 12, 31, 0, 0, 0, 0, loc).YearDay()
+			for _, d := range nthWeekdays(rr.ByDay, y, 1, ndays, loc) {
+				dates = append(dates, d)
+			}
+		default:
+			if isValidDate(y, int(s.Month()), s.Day()) {
+				dates = append(dates, time.Date(y, s.Month(), s.Day(), 0, 0, 0, 0, loc))
+			}
+		}
+	case RRMonthly:
+		if intIn(int(p.Month()), rr.ByMonth) {
+			dates = rr.monthDates(y, int(p.Month()), loc)
+		}
+	case RRWeekly:
+		for k := 0; k < 7; k++ {
+			d := time.Date(y, p.Month(), p.Day()+k, 0, 0, 0, 0, loc)
+			if !intIn(int(d.Month()), rr.ByMonth) {
+				continue
+			}
+			if len(rr.ByDay) == 0 && d.Weekday() != s.Weekday() {
+				continue
+			}
+			if len(rr.ByDay) > 0 && !weekdayInRRule(d.Weekday(), rr.ByDay) {
+				continue
+			}
+			dates = append(dates, d)
+		}
+	case RRDaily:
+		if rr.dateMatches(p) {
+			dates = append(dates, time.Date(y, p.Month(), p.Day(), 0, 0, 0, 0, loc))
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates
+}
+
+// monthDates expands BYMONTHDAY and BYDAY (with ordinals relative to the month)
+func (rr RRule) monthDates(y, m int, loc *time.Location) []time.Time {
+	var dates []time.Time
+	last := lastDayOfMonth(y, m)
+
+	if len(rr.ByMonthDay) == 0 && len(rr.ByDay) == 0 {
+		if rr.wall.Day() <= last {
+			dates = append(dates, time.Date(y, time.Month(m), rr.wall.Day(), 0, 0, 0, 0, loc))
+		}
+		return dates
+	}
+
+	mdays := make(map[int]bool)
+	for _, md := range rr.ByMonthDay {
+		if md < 0 {
+			md = last + md + 1
+		}
+		if md >= 1 && md <= last {
+			mdays[md] = true
+		}
+	}
+	wdays := make(map[int]bool)
+	for _, d := range nthWeekdays(rr.ByDay, y, m, last, loc) {
+		wdays[d.Day()] = true
+	}
+	for d := 1; d <= last; d++ {
+		in := mdays[d] || wdays[d]
+		if len(rr.ByMonthDay) > 0 && len(rr.ByDay) > 0 {
+			in = mdays[d] && wdays[d]
+		}
+		if in {
+			dates = append(dates, time.Date(y, time.Month(m), d, 0, 0, 0, 0, loc))
+		}
+	}
+	return dates
+}
+
+// nthWeekdays resolves BYDAY entries over ndays days starting at y-m-01
+func nthWeekdays(bydays []RRuleDay, y, m, ndays int, loc *time.Location) []time.Time {
+	var dates []time.Time
+	for _, bd := range bydays {
+		var matches []time.Time
+		for i := 0; i < ndays; i++ {
+			d := time.Date(y, time.Month(m), 1+i, 0, 0, 0, 0, loc)
+			if d.Weekday() == bd.Weekday {
+				matches = append(matches, d)
+			}
+		}
+		switch {
+		case bd.N == 0:
+			dates = append(dates, matches...)
+		case bd.N > 0 && bd.N <= len(matches):
+			dates = append(dates, matches[bd.N-1])
+		case bd.N < 0 && -bd.N <= len(matches):
+			dates = append(dates, matches[len(matches)+bd.N])
+		}
+	}
+	return dates
+}
+
+// dateMatches applies BYMONTH, BYMONTHDAY, BYYEARDAY and BYDAY as filters
+func (rr RRule) dateMatches(t time.Time) bool {
+	if !intIn(int(t.Month()), rr.ByMonth) {
+		return false
+	}
+	if len(rr.ByMonthDay) > 0 {
+		last := lastDayOfMonth(t.Year(), int(t.Month()))
+		if !intIn(t.Day(), rr.ByMonthDay) && !intIn(t.Day()-last-1, rr.ByMonthDay) {
+			return false
+		}
+	}
+	if len(rr.ByYearDay) > 0 {
+		ndays := time.Date(t.Year(), 12, 31, 0, 0, 0, 0, t.Location()).YearDay()
+		if !intIn(t.YearDay(), rr.ByYearDay) && !intIn(t.YearDay()-ndays-1, rr.ByYearDay) {
+			return false
+		}
+	}
+	if len(rr.ByDay) > 0 && !weekdayInRRule(t.Weekday(), rr.ByDay) {
+		return false
+	}
+	return true
+}
+
+func weekdayInRRule(wd time.Weekday, bydays []RRuleDay) bool {
+	for _, bd := range bydays {
+		if bd.Weekday == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// intIn is true if i is in is, or if is is empty (i.e. no restriction)
+func intIn(i int, is []int) bool {
+	if len(is) == 0 {
+		return true
+	}
+	for _, v := range is {
+		if v == i {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidDate(y, m, d int) bool {
+	return d >= 1 && d <= lastDayOfMonth(y, m)
+}
+
+func (rr RRule) isExcluded(t time.Time) bool {
+	for _, ex := range rr.ExDate {
+		if ex.Equal(t) {
+			return true
+		}
+	}
+	for _, ex := range rr.exDays {
+		if ex == dateAsInt(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// period is the length of the FREQ*INTERVAL period of a sub-daily rule
+func (rr RRule) period() time.Duration {
+	return [...]time.Duration{time.Second, time.Minute, time.Hour}[rr.Freq] * time.Duration(rr.Interval)
+}
+
+// periodIndex returns the index of the first period of a sub-daily rule starting at or
+// after t
+func (rr RRule) periodIndex(t time.Time) int {
+	base, unit := rr.DtStart.Truncate(time.Second), rr.period()
+	i := int(t.Sub(base) / unit)
+	if base.Add(time.Duration(i) * unit).Before(t) {
+		i++
+	}
+	return i
+}
+
+// nextCandidate returns p if the period of a sub-daily rule starting at p may have
+// occurrences, otherwise the start of the next day (no later than end), hour or minute
+// not excluded by BYMONTH, BYMONTHDAY, BYYEARDAY and BYDAY, BYHOUR or BYMINUTE
+func (rr RRule) nextCandidate(p, end time.Time) time.Time {
+	y, m, d := p.Date()
+	loc := p.Location()
+	if day := time.Date(y, m, d, 0, 0, 0, 0, loc); !rr.dateMatches(day) {
+		for !day.After(end) {
+			day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc)
+			if rr.dateMatches(day) {
+				break
+			}
+		}
+		return day
+	}
+	if rr.Freq < RRHourly && !intIn(p.Hour(), rr.ByHour) {
+		return time.Date(y, m, d, p.Hour()+1, 0, 0, 0, loc)
+	}
+	if rr.Freq < RRMinutely && !intIn(p.Minute(), rr.ByMinute) {
+		return time.Date(y, m, d, p.Hour(), p.Minute()+1, 0, 0, loc)
+	}
+	return p
+}
+
+// Iterate calls fn for each occurrence of the rule (including RDATE and
+// excluding EXDATE) in chronological order, until fn returns false, the
+// rule is exhausted or occurrences pass end. resolve converts the wall clock
+// of occurrences of a floating rule (see RRule), given as times in UTC, to a
+// time in the location of DTSTART, or the zero time if it is not to be run
+func (rr RRule) Iterate(end time.Time, resolve func(time.Time) time.Time, fn func(time.Time) bool) {
+	rdates := rr.RDate
+	emit := func(t time.Time) bool {
+		for len(rdates) > 0 && !rdates[0].After(t) {
+			rd := rdates[0]
+			rdates = rdates[1:]
+			if rd.Equal(t) {
+				continue
+			}
+			if !rr.isExcluded(rd) && !fn(rd) {
+				return false
+			}
+		}
+		if rr.isExcluded(t) {
+			return true
+		}
+		return fn(t)
+	}
+
+	// sub-daily rules without COUNT are started close to end of the search
+	// rather than iterating every period since DTSTART
+	i0 := 0
+	if rr.Count == 0 && rr.Freq < RRDaily {
+		start := end.AddDate(-rruleHorizon, 0, -1)
+		if start.After(rr.DtStart) {
+			i0 = int(start.Sub(rr.DtStart) / rr.period())
+		}
+	}
+
+	// periods and occurrences are bounded in the time of the rule, which for a floating
+	// rule is the wall clock in the location of DTSTART
+	start, until := rr.DtStart, rr.Until
+	if rr.isFloating() {
+		start, end = rr.wall, floating(end.In(rr.loc))
+		if !until.IsZero() {
+			until = floating(until.In(rr.loc))
+		}
+	}
+
+	n := 0
+Periods:
+	for i := i0; ; i++ {
+		p := rr.periodStart(i)
+		if p.After(end) || (!until.IsZero() && p.After(until)) {
+			break
+		}
+		if rr.Freq < RRDaily {
+			// periods of days, hours or minutes excluded by the BY filters are skipped
+			if next := rr.nextCandidate(p, end); next.After(p) {
+				i = rr.periodIndex(next) - 1
+				continue
+			}
+		}
+		for _, w := range rr.expand(p) {
+			if w.Before(start) {
+				continue
+			}
+			if !until.IsZero() && w.After(until) {
+				break Periods
+			}
+			if w.After(end) {
+				break Periods
+			}
+			n++
+			t := w
+			if rr.isFloating() {
+				t = resolve(w)
+			}
+			if !t.IsZero() && !emit(t) {
+				return
+			}
+			if rr.Count > 0 && n >= rr.Count {
+				break Periods
+			}
+		}
+	}
+	for _, rd := range rdates {
+		if rd.After(end) || rr.isExcluded(rd) {
+			continue
+		}
+		if !fn(rd) {
+			return
+		}
+	}
+}
+
+// nextStart finds the next occurrence after the current time, with dates adjusted
+// to the Cron calendar (rolling forward, or back if Rollback is set, or by Adjust)
+func (rr RRule) nextStart(c Cron, t time.Time) (time.Duration, time.Time, error) {
+	var err error
+	current := t.Add(time.Millisecond)
+	end := current.AddDate(rruleHorizon, 0, 0)
+
+	useCal := c.Calendar != "" && c.Calendar != "ALL"
+	var cal Cal
+	if useCal {
+		cal, err = ReadCalendar(c.Calendar, c.CalendarDirs)
+		if err != nil {
+			return time.Duration(math.MaxInt64), ZeroTime, errors.New("Missing Calendar")
+		}
+	}
+
+	// occurrences are resolved as cron trigger times, see DSTPolicy
+	resolve := func(w time.Time) time.Time {
+		t, _ := c.resolveDST(w.Format(wallLayout), rr.loc, current)
+		return t
+	}
+
+	var next time.Time
+	var outOfRange bool
+	rr.Iterate(end, resolve, func(t time.Time) bool {
+		adj, inCal := t, true
+		if useCal && c.adjust {
+			dt, inRange := cal.adjustDate(dateAsInt(t), c.Adjust)
+			if dt == 0 { // not available and not adjusted
+				return true
+			}
+			adj = time.Date(dt/10000, time.Month(dt/100%100), dt%100, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+			inCal = inRange
+		} else if useCal {
+			adj, inCal = adjustToCal(t, cal, c.Rollback)
+		}
+		if !next.IsZero() && dateAsInt(adj) > dateAsInt(next) {
+			// adjustments preserve date order, so nothing later can be earlier
+			return false
+		}
+		if adj.After(current) && (next.IsZero() || adj.Before(next)) {
+			next = adj
+			outOfRange = !inCal
+		}
+		return true
+	})
+
+	if next.IsZero() {
+		return time.Duration(math.MaxInt64), ZeroTime, errors.New("no remaining occurrences in rrule")
+	}
+	if outOfRange {
+		err = errors.New(fmt.Sprintf("insufficient calendar days in %s", c.Calendar))
+		if c.RequireCal {
+			return time.Duration(math.MaxInt64), time.Time{}, err
+		}
+	}
+	if c.jitter > 0 {
+		next = next.Add(time.Second * time.Duration(rand.Intn(c.jitter)))
+	}
+	return next.Sub(current), next, err
+}
+
+// adjustToCal moves t to the next (or prior if rollback) available date in cal,
+// keeping the time of day. Dates outside of the calendar are returned unchanged
+func adjustToCal(t time.Time, cal Cal, rollback bool) (time.Time, bool) {
+	dt := dateAsInt(t)
+	i := sort.SearchInts(cal.DatesIn, dt)
+	if i == len(cal.DatesIn) || (rollback && i == 0 && cal.DatesIn[0] != dt) {
+		return t, false
+	}
+	caldt := cal.DatesIn[i]
+	if caldt != dt && rollback {
+		caldt = cal.DatesIn[i-1]
+	}
+	return time.Date(caldt/10000, time.Month(caldt/100%100), caldt%100, t.Hour(), t.Minute(), t.Second(), 0, t.Location()), true
+}
+
+// parseRRuleCron wraps an RRule in a Cron so that it can be used in
+// place of any cron spec, e.g. as part of CronStart arrays
+func parseRRuleCron(spec string, timezone string, calendar string, calendarDirs []string, rollback bool, required bool, jitter int) (Cron, error) {
+	var cron Cron
+	rr, err := ParseRRule(spec, timezone)
+	if err != nil {
+		return cron, err
+	}
+	cron.rrule = &rr
+	cron.spec = spec
+	cron.Timezone = timezone
+	cron.Calendar = calendar
+	cron.CalendarDirs = calendarDirs
+	cron.Rollback = rollback
+	cron.RequireCal = required
+	cron.jitter = jitter
+	cron.r = make(map[CronFields]bool)
+	return cron, nil
+}
+
+func (c Cron) IsRRule() bool {
+	return c.rrule != nil
+}
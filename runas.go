@@ -0,0 +1,273 @@
+package rpeat
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// RunAs is the OS user and groups the process of a job is run as, e.g.
+//
+//	"RunAs": {"User": "etl", "Group": "etl", "Groups": ["market-data"]}
+//
+// Group is the primary group (default the primary group of User) and Groups the
+// supplementary groups (default all groups of User), each of which User must be a
+// member of. Names or numeric ids may be used. HOME, USER and LOGNAME are set for
+// User, and the stdout, stderr and Script files created for each run are given to User.
+//
+// TmpDir and the run directory TmpDir/JobUUID remain owned by rpeat-server, with the run
+// directory searchable by others. To run a Script, TmpDir must also be searchable by
+// User (e.g. mode 0711, or 0710 with a group of User), which rpeat-server does not change.
+// Runs with Umask or Limits start as the rpeat-server executable, which applies them
+// before running the command, so it must be executable by User.
+//
+// rpeat-server must be running as root (or with CAP_SETUID and CAP_SETGID) to run jobs
+// as another user, and the owner of the job (User) must be granted RunAs.User by the
+// RunAs of their entry in the AuthFile, e.g.
+//
+//	{"User": "ops", "Secret": "...", "RunAs": ["etl", "reports"]}
+//
+// with "*" granting any OS user.
+type RunAs struct {
+	User   string   `json:"User"`
+	Group  string   `json:"Group,omitempty"`
+	Groups []string `json:"Groups,omitempty"`
+}
+
+func lookupUser(name string) (*user.User, error) {
+	u, err := user.Lookup(name)
+	if err != nil {
+		if _, nerr := strconv.Atoi(name); nerr == nil {
+			return user.LookupId(name)
+		}
+	}
+	return u, err
+}
+
+func lookupGroup(name string) (*user.Group, error) {
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		if _, nerr := strconv.Atoi(name); nerr == nil {
+			return user.LookupGroupId(name)
+		}
+	}
+	return g, err
+}
+
+// lookup resolves r into the user and the uid, gid and supplementary gids to run as
+func (r *RunAs) lookup() (*user.User, uint32, uint32, []uint32, error) {
+	u, err := lookupUser(r.User)
+	if err != nil {
+		return nil, 0, 0, nil, errors.New(fmt.Sprintf("RunAs user %s: %s", r.User, err))
+	}
+	member, err := u.GroupIds()
+	if err != nil {
+		return nil, 0, 0, nil, errors.New(fmt.Sprintf("RunAs user %s: %s", r.User, err))
+	}
+	gidOf := func(name string) (uint32, error) {
+		g, err := lookupGroup(name)
+		if err != nil {
+			return 0, errors.New(fmt.Sprintf("RunAs group %s: %s", name, err))
+		}
+		if g.Gid != u.Gid && !stringInSlice(g.Gid, member) {
+			return 0, errors.New(fmt.Sprintf("RunAs user %s is not a member of group %s", r.User, name))
+		}
+		gid, err := strconv.ParseUint(g.Gid, 10, 32)
+		return uint32(gid), err
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, 0, 0, nil, err
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, 0, 0, nil, err
+	}
+	primary := uint32(gid)
+	if r.Group != "" {
+		if primary, err = gidOf(r.Group); err != nil {
+			return nil, 0, 0, nil, err
+		}
+	}
+	var groups []uint32
+	if r.Groups == nil {
+		for _, id := range member {
+			if gid, err := strconv.ParseUint(id, 10, 32); err == nil {
+				groups = append(groups, uint32(gid))
+			}
+		}
+	} else {
+		for _, name := range r.Groups {
+			gid, err := gidOf(name)
+			if err != nil {
+				return nil, 0, 0, nil, err
+			}
+			groups = append(groups, gid)
+		}
+	}
+	return u, uint32(uid), primary, groups, nil
+}
+
+// allowedRunAs returns an error if the owner of the job is not granted RunAs.User in users
+func (job *Job) allowedRunAs(users []AuthUser) error {
+	if job.RunAs == nil {
+		return nil
+	}
+	for _, u := range users {
+		if u.User == job.User {
+			if stringInSlice("*", u.RunAs) || stringInSlice(job.RunAs.User, u.RunAs) {
+				return nil
+			}
+			break
+		}
+	}
+	return PermissionError{Exception: RunAsNotPermitted, User: job.User, RunAs: job.RunAs.User}
+}
+
+// runAsEnv returns HOME, USER and LOGNAME of the RunAs user, if any
+func (job *Job) runAsEnv() []string {
+	if job.RunAs == nil {
+		return nil
+	}
+	u, err := lookupUser(job.RunAs.User)
+	if err != nil {
+		return nil
+	}
+	return []string{"HOME=" + u.HomeDir, "USER=" + u.Username, "LOGNAME=" + u.Username}
+}
+
+// applyRunAs sets the credential of RunAs on attr, returning the uid and gid the process
+// will run as, or -1 if the job has no RunAs
+func (job *Job) applyRunAs(attr *syscall.SysProcAttr) (int, int, error) {
+	if job.RunAs == nil {
+		return -1, -1, nil
+	}
+	if job.runAsErr != nil {
+		return -1, -1, job.runAsErr
+	}
+	_, uid, gid, groups, err := job.RunAs.lookup()
+	if err != nil {
+		return -1, -1, err
+	}
+	if err = syscallCredential(attr, uid, gid, groups); err != nil {
+		return -1, -1, err
+	}
+	return int(uid), int(gid), nil
+}
+
+// runAsAccess returns an error unless the RunAs user of the job is permitted mode (4 read,
+// 2 write, 1 search) on path, whose info fi is checked in place of path when not nil, and
+// may search the directories leading to it. Files the server reads or creates for the
+// process, which runs with fewer privileges, must pass this first
+func (job *Job) runAsAccess(path string, fi os.FileInfo, mode uint32) error {
+	if job.RunAs == nil {
+		return nil
+	}
+	_, uid, gid, groups, err := job.RunAs.lookup()
+	if err != nil {
+		return err
+	}
+	gids := append([]uint32{gid}, groups...)
+	denied := errors.New(fmt.Sprintf("RunAs user %s is not permitted to access %s", job.RunAs.User, path))
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return err
+	}
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		di, err := os.Stat(dir)
+		if err != nil || !syscallAccess(di, uid, gids, 1) {
+			return denied
+		}
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	if fi == nil {
+		if fi, err = os.Stat(path); err != nil {
+			return err
+		}
+	}
+	if !syscallAccess(fi, uid, gids, mode) {
+		return denied
+	}
+	return nil
+}
+
+// runAsExecutable returns an error unless the RunAs user of the job may execute the rpeat
+// executable, which runs with Umask or Limits are started as
+func (job *Job) runAsExecutable() error {
+	if job.RunAs == nil {
+		return nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if err = job.runAsAccess(exe, nil, 1); err != nil {
+		return errors.New(fmt.Sprintf("Umask and Limits require %s to be executable by the RunAs user: %s", exe, err))
+	}
+	return nil
+}
+
+// runDir creates the run directory TmpDir/JobUUID of the job if needed, returning it. With
+// RunAs it is searchable by others, so the RunAs user can reach its Script without being
+// able to list it
+func (job *Job) runDir() string {
+	dir := filepath.Join(job.TmpDir, fmt.Sprintf("%s", job.JobUUID))
+	_ = os.Mkdir(dir, os.FileMode(0770))
+	if job.RunAs != nil {
+		if fi, err := os.Lstat(dir); err == nil && fi.IsDir() && fi.Mode().Perm() != 0771 {
+			os.Chmod(dir, os.FileMode(0771))
+		}
+	}
+	return dir
+}
+
+// createRunFile creates the file name for a run, failing if it already exists or is a
+// symlink, so only files created by the server for the run are given to the RunAs user
+func createRunFile(name string, perm os.FileMode) (*os.File, error) {
+	return os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL|oNoFollow, perm)
+}
+
+// openRunLog opens the log file name of a run with flags, or if created creates it with
+// createRunFile
+func openRunLog(name string, created bool, flags int) (*os.File, error) {
+	if created {
+		return createRunFile(name, os.FileMode(0660))
+	}
+	return os.OpenFile(name, flags, os.FileMode(0660))
+}
+
+// chownRun gives files, created by createRunFile, and the Script of run runid to uid:gid
+func (job *Job) chownRun(uid, gid int, runid uuid.UUID, files ...*os.File) {
+	if uid < 0 {
+		return
+	}
+	for _, f := range files {
+		if err := f.Chown(uid, gid); err != nil {
+			ServerLogger.Printf("[RunAs] %s unable to chown %s: %s", job.Name, f.Name(), err)
+		}
+	}
+	if job.Script == "" {
+		return
+	}
+	script := job.scriptFile(runid)
+	f, err := os.OpenFile(script, os.O_RDONLY|oNoFollow, 0)
+	if err == nil {
+		err = f.Chown(uid, gid)
+		f.Close()
+	}
+	if err != nil {
+		ServerLogger.Printf("[RunAs] %s unable to chown %s: %s", job.Name, script, err)
+	} else if err = job.runAsAccess(script, nil, 4); err != nil {
+		ServerLogger.Printf("[RunAs] %s: %s (TmpDir %s must be searchable by the RunAs user)", job.Name, err, job.TmpDir)
+	}
+}
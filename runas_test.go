@@ -0,0 +1,264 @@
+//go:build linux
+// +build linux
+
+package rpeat
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// runAsUser is an OS user created for a test, with primary group group, supplementary
+// group member and a group other it is not a member of
+type runAsUser struct {
+	name, group, member, other string
+	uid, gid, memberGid        int
+}
+
+// newRunAsUser creates a throwaway user, skipping the test unless run as root
+func newRunAsUser(t *testing.T) runAsUser {
+	if os.Geteuid() != 0 {
+		t.Skip("RunAs tests require root")
+	}
+	if _, err := exec.LookPath("useradd"); err != nil {
+		t.Skip("RunAs tests require useradd")
+	}
+	initServerLogging(ioutil.Discard)
+	id := fmt.Sprintf("%d", os.Getpid()%100000)
+	u := runAsUser{name: "rpeatu" + id, group: "rpeatg" + id, member: "rpeatm" + id, other: "rpeato" + id}
+	run := func(name string, args ...string) {
+		if out, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+			t.Fatalf("%s %s: %s %s", name, strings.Join(args, " "), err, out)
+		}
+	}
+	for _, g := range []string{u.group, u.member, u.other} {
+		g := g
+		run("groupadd", g)
+		t.Cleanup(func() { exec.Command("groupdel", g).Run() })
+	}
+	run("useradd", "-M", "-N", "-d", "/nonexistent", "-s", "/bin/sh", "-g", u.group, "-G", u.member, u.name)
+	t.Cleanup(func() { exec.Command("userdel", u.name).Run() })
+	pw, err := user.Lookup(u.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.uid, _ = strconv.Atoi(pw.Uid)
+	u.gid, _ = strconv.Atoi(pw.Gid)
+	g, err := user.LookupGroup(u.member)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.memberGid, _ = strconv.Atoi(g.Gid)
+	return u
+}
+
+func TestRunAsLookup(t *testing.T) {
+	u := newRunAsUser(t)
+	tests := []struct {
+		runas  RunAs
+		gid    int
+		groups []int
+		err    string
+	}{
+		{RunAs{User: u.name}, u.gid, []int{u.gid, u.memberGid}, ""},
+		{RunAs{User: strconv.Itoa(u.uid)}, u.gid, []int{u.gid, u.memberGid}, ""},
+		{RunAs{User: u.name, Group: u.member}, u.memberGid, []int{u.gid, u.memberGid}, ""},
+		{RunAs{User: u.name, Group: strconv.Itoa(u.memberGid), Groups: []string{}}, u.memberGid, nil, ""},
+		{RunAs{User: u.name, Groups: []string{u.member}}, u.gid, []int{u.memberGid}, ""},
+		{RunAs{User: u.name, Group: u.other}, 0, nil, "not a member of group " + u.other},
+		{RunAs{User: u.name, Groups: []string{u.member, u.other}}, 0, nil, "not a member of group " + u.other},
+		{RunAs{User: u.name, Group: "rpeat-no-such-group"}, 0, nil, "RunAs group rpeat-no-such-group"},
+		{RunAs{User: "rpeat-no-such-user"}, 0, nil, "RunAs user rpeat-no-such-user"},
+	}
+	for _, tt := range tests {
+		_, uid, gid, groups, err := tt.runas.lookup()
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("lookup(%+v) error %v; want %q", tt.runas, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("lookup(%+v): %s", tt.runas, err)
+			continue
+		}
+		var got []int
+		for _, g := range groups {
+			got = append(got, int(g))
+		}
+		sort.Ints(got)
+		if int(uid) != u.uid || int(gid) != tt.gid || fmt.Sprint(got) != fmt.Sprint(tt.groups) {
+			t.Errorf("lookup(%+v) = %d:%d %v; want %d:%d %v", tt.runas, uid, gid, got, u.uid, tt.gid, tt.groups)
+		}
+	}
+}
+
+func TestRunAsPermitted(t *testing.T) {
+	u := newRunAsUser(t)
+	auth := []AuthUser{
+		{User: "ops", RunAs: []string{u.name}},
+		{User: "admin", RunAs: []string{"*"}},
+		{User: "dev", RunAs: []string{"etl"}},
+	}
+	tests := []struct {
+		user  string
+		runas RunAs
+		auth  []AuthUser
+		errs  []string
+	}{
+		{"ops", RunAs{User: u.name}, auth, nil},
+		{"admin", RunAs{User: u.name}, auth, nil},
+		{"dev", RunAs{User: u.name}, auth, []string{"RunAsNotPermitted"}},
+		{"nobody", RunAs{User: u.name}, auth, []string{"RunAsNotPermitted"}},
+		{"ops", RunAs{User: u.name, Group: u.other}, auth, []string{"InvalidRunAs"}},
+		{"dev", RunAs{User: u.name, Group: u.other}, auth, []string{"InvalidRunAs", "RunAsNotPermitted"}},
+		{"dev", RunAs{User: u.name}, nil, nil}, // auth file unknown
+	}
+	for _, tt := range tests {
+		runas := tt.runas
+		job := &Job{Name: "runas", User: tt.user, RunAs: &runas, Permissions: Permission{"start": {tt.user}}}
+		err := job.allowedRunAs(tt.auth)
+		if permitted := !stringInSlice("RunAsNotPermitted", tt.errs); tt.auth != nil && permitted != (err == nil) {
+			t.Errorf("%s RunAs %+v: allowedRunAs = %v", tt.user, tt.runas, err)
+		}
+		job.ValidatePermissions(tt.auth)
+		var got []string
+		for _, e := range job.jve.Errors {
+			got = append(got, strings.SplitN(e.Msg, ":", 2)[0])
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.errs) {
+			t.Errorf("%s RunAs %+v: ValidatePermissions errors %v; want %v", tt.user, tt.runas, got, tt.errs)
+		}
+	}
+}
+
+func TestCreateRunFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpeat-runas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	target := filepath.Join(dir, "target")
+	if err = ioutil.WriteFile(target, []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err = os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	dangling := filepath.Join(dir, "dangling")
+	if err = os.Symlink(filepath.Join(dir, "missing"), dangling); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{target, link, dangling} {
+		if f, err := createRunFile(name, 0660); err == nil {
+			f.Close()
+			t.Errorf("createRunFile(%s) succeeded; want error", filepath.Base(name))
+		}
+	}
+	if b, _ := ioutil.ReadFile(target); string(b) != "keep" {
+		t.Errorf("target modified: %q", b)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("dangling symlink target created")
+	}
+	f, err := createRunFile(filepath.Join(dir, "new"), 0660)
+	if err != nil {
+		t.Fatalf("createRunFile(new): %s", err)
+	}
+	f.Close()
+}
+
+func TestRunAsRun(t *testing.T) {
+	u := newRunAsUser(t)
+	tmp, err := ioutil.TempDir("", "rpeat-runas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	if err = os.Chmod(tmp, 0711); err != nil {
+		t.Fatal(err)
+	}
+
+	job := &Job{
+		Name:    "runas",
+		User:    "ops",
+		Script:  "#!/bin/sh\nid -u\nid -g\nid -G\necho $USER\n",
+		TmpDir:  tmp,
+		JobUUID: uuid.New(),
+		RunAs:   &RunAs{User: u.name, Group: u.member},
+	}
+	runid := uuid.New()
+	job.RunUUID = runid
+	c, err := evaluatedCmd(job, false, "")
+	if err != nil {
+		t.Fatalf("evaluatedCmd: %s", err)
+	}
+	if err = job.writeScript(runid); err != nil {
+		t.Fatalf("writeScript: %s", err)
+	}
+	dir := job.runDir()
+	var files []*os.File
+	for _, ext := range []string{"stdout", "stderr"} {
+		f, err := createRunFile(filepath.Join(dir, fmt.Sprintf("%s.%s", runid, ext)), 0660)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		files = append(files, f)
+	}
+	c.SysProcAttr = syscallSysProcAttr()
+	c.Stdout, c.Stderr = files[0], files[1]
+	uid, gid, err := job.applyRunAs(c.SysProcAttr)
+	if err != nil {
+		t.Fatalf("applyRunAs: %s", err)
+	}
+	if uid != u.uid || gid != u.memberGid {
+		t.Errorf("applyRunAs = %d:%d; want %d:%d", uid, gid, u.uid, u.memberGid)
+	}
+	job.chownRun(uid, gid, runid, files...)
+	if err = c.Run(); err != nil {
+		stderr, _ := ioutil.ReadFile(files[1].Name())
+		t.Fatalf("run: %s %s", err, stderr)
+	}
+
+	out, err := ioutil.ReadFile(files[0].Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// uid, gid, groups (the gid and supplementary groups) and USER of the process
+	lines := strings.Split(string(out), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("RunAs process output %q", out)
+	}
+	groups := strings.Fields(lines[2])
+	sort.Strings(groups)
+	wantGroups := []string{strconv.Itoa(u.gid), strconv.Itoa(u.memberGid)}
+	sort.Strings(wantGroups)
+	if lines[0] != strconv.Itoa(u.uid) || lines[1] != strconv.Itoa(u.memberGid) || fmt.Sprint(groups) != fmt.Sprint(wantGroups) || lines[3] != u.name {
+		t.Errorf("RunAs process output %q; want uid %d gid %d groups %v USER %s", out, u.uid, u.memberGid, wantGroups, u.name)
+	}
+	for _, name := range []string{files[0].Name(), files[1].Name(), job.scriptFile(runid)} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		st := fi.Sys().(*syscall.Stat_t)
+		if int(st.Uid) != u.uid || int(st.Gid) != u.memberGid {
+			t.Errorf("%s owned by %d:%d; want %d:%d", filepath.Base(name), st.Uid, st.Gid, u.uid, u.memberGid)
+		}
+	}
+	if fi, err := os.Stat(dir); err != nil || fi.Mode().Perm() != 0771 {
+		t.Errorf("run directory mode %v %v; want 0771", fi.Mode().Perm(), err)
+	}
+}
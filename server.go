@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"path/filepath"
+	"strings"
 
 	//"github.com/google/uuid"
 	"github.com/go-kit/kit/endpoint"
@@ -21,11 +24,18 @@ type Service interface {
 	ServerRestart(string) (*ServerConfig, error)
 
 	// Controls
-	Start(string, string, string) (*controlResponse, error)
-	Stop(string, string, string) (*controlResponse, error)
+	Start(string, string, string, map[string]string) (*controlResponse, error)
+	Stop(string, string, string, string) (*controlResponse, error)
 	Hold(string, string, string, string) (*controlResponse, error)
 	Restart(string, string, string) (*controlResponse, error)
 	Log(string, string, string, bool, bool, int, int64) (*LogOutput, error)
+	Backfill(string, string, string, string, string, int, bool) (*BackfillResponse, error)
+	Blackouts(string) (*BlackoutResponse, error)
+	AddBlackout(string, Blackout) (*BlackoutResponse, error)
+	RemoveBlackout(string, string) (*BlackoutResponse, error)
+	Calendars(string) (*CalendarResponse, error)
+	CalendarDates(string, string, []string, string, string) (*CalendarResponse, error)
+	CalendarCheck(string, string, []string, string) (*CalendarResponse, error)
 
 	Resume(string, string) (*JobUpdateParams, error) // not implemented and may never be
 	Status(string, string) (*JobUpdateParams, error)
@@ -267,7 +277,10 @@ func (k service) Hold(jobid string, user string, comment, duration string) (*con
 		job.setRetryAttempt(0)
 		job.setJobState(JReady)
 	} else {
-		if job.IsRunning {
+		job.cancelQueued()
+		if job.hasInstances() {
+			job.stopInstances(JStopped)
+		} else if job.IsRunning {
 			job.getProc().Kill() // FIXME: shouldn't be in Hold, rather chain call or disable
 		}
 		job.setHold(true)
@@ -280,22 +293,33 @@ func (k service) Hold(jobid string, user string, comment, duration string) (*con
 	job.sendUpdate()
 	return &controlResponse{Status: "success"}, nil
 }
-func (k service) Start(jobid string, user string, comment string) (*controlResponse, error) {
+// Start triggers an unscheduled run of jobid, with params overriding the Default of
+// the Params of the job for this run
+func (k service) Start(jobid string, user string, comment string, params map[string]string) (*controlResponse, error) {
 	ServerLogger.Printf("\tSTART\tJobUUID: %s\tuser:%s", jobid, user)
-	job, ok := k.Jobs[jobid]
+	job, ok := k.Jobs.getJob(jobid)
 	if !ok {
 		return &controlResponse{Status: "invalid jobid"}, errors.New("bad jobid")
 	}
 	if permitted := job.hasPermission(user, "start"); !permitted {
 		return &controlResponse{Status: "permission denied"}, ErrEmpty
 	}
+	if err := job.checkParams(params); err != nil {
+		return &controlResponse{Status: err.Error()}, err
+	}
 	if job.Hold {
 		job.setHold(false)
 		job.setRetryAttempt(0)
 	}
-	if !job.IsRunning {
+	if !job.IsRunning || job.startRule.Concurrent {
 		job.Unscheduled = true
 		job.Reason = Reason{Action: "start", Comment: comment, User: user, Timestamp: time.Now().Unix()}
+		job.Lock()
+		job.params = nil
+		if len(params) > 0 {
+			job.params = params
+		}
+		job.Unlock()
 		job.resetTimer(time.Second * 0)
 		//job.setJobState(JManual)
 		//job.sendUpdate()
@@ -304,8 +328,8 @@ func (k service) Start(jobid string, user string, comment string) (*controlRespo
 	}
 	return &controlResponse{Status: "success"}, nil
 }
-func (k service) Stop(jobid string, user string, comment string) (*controlResponse, error) {
-	ServerLogger.Printf("\tSTOP\tJobUUID: %s\tuser:%s", jobid, user)
+func (k service) Stop(jobid string, runid string, user string, comment string) (*controlResponse, error) {
+	ServerLogger.Printf("\tSTOP\tJobUUID: %s\tRunUUID: %s\tuser:%s", jobid, runid, user)
 	job, ok := k.Jobs[jobid]
 	if !ok {
 		return &controlResponse{Status: "invalid jobid"}, errors.New("bad jobid")
@@ -313,6 +337,14 @@ func (k service) Stop(jobid string, user string, comment string) (*controlRespon
 	if permitted := job.hasPermission(user, "stop"); !permitted {
 		return &controlResponse{Status: "permission denied"}, ErrEmpty
 	}
+	if runid != "" && job.hasInstances() {
+		// single instance of a job with StartRule "Start"
+		reason := Reason{Action: "stop", Comment: comment, User: user, Timestamp: time.Now().Unix()}
+		if err := job.stopRun(runid, JStopped, reason); err != nil {
+			return &controlResponse{Status: "invalid runid"}, err
+		}
+		return &controlResponse{Status: "stopped"}, nil
+	}
 	job.Unscheduled = true
 	job.Reason = Reason{Action: "stop", Comment: comment, User: user, Timestamp: time.Now().Unix()}
 	if job.ShutdownCmd == "" {
@@ -344,6 +376,201 @@ func (k service) Restart(jobid string, user string, comment string) (*controlRes
 	}
 	return &controlResponse{Status: "success"}, nil
 }
+
+// Backfill runs job jobid for each CronStart trigger from from through to (YYYYMMDD or
+// YYYYMMDDhhmmss in the job Timezone), or up to now if to is later, optionally using
+// calendar in place of the job Calendar. Runs are started in the background with at
+// most parallel at once. If dryrun is true the asof times are returned without running
+// the job.
+func (k service) Backfill(jobid, user, from, to, calendar string, parallel int, dryrun bool) (*BackfillResponse, error) {
+	ServerLogger.Printf("\tBACKFILL\tJobUUID: %s\tuser:%s\tfrom:%s\tto:%s\tcalendar:%s", jobid, user, from, to, calendar)
+	resp := &BackfillResponse{}
+	job, ok := k.Jobs.getJob(jobid)
+	if !ok {
+		resp.Status = "invalid jobid"
+		return resp, errors.New("bad jobid")
+	}
+	if permitted := job.hasPermission(user, "backfill"); !permitted {
+		resp.Status = "permission denied"
+		return resp, ErrPermission
+	}
+	if !job.hasCmd() || job.isController() {
+		resp.Status = "job has no Cmd to backfill"
+		return resp, errors.New("backfill requires a job with Cmd")
+	}
+	start, err := ParseBackfillTime(from, job._location, false)
+	if err != nil {
+		resp.Status = err.Error()
+		return resp, err
+	}
+	end, err := ParseBackfillTime(to, job._location, true)
+	if err != nil {
+		resp.Status = err.Error()
+		return resp, err
+	}
+	// a range ending today or later is backfilled up to now
+	if now := time.Now(); end.After(now) {
+		end = now
+	}
+	if !start.Before(end) {
+		resp.Status = "backfill range must start in the past with from before to"
+		return resp, errors.New("invalid backfill range")
+	}
+	starts, err := job.BackfillStarts(start, end, calendar)
+	if err != nil {
+		resp.Status = err.Error()
+		return resp, err
+	}
+	for _, t := range starts {
+		resp.AsOf = append(resp.AsOf, t.In(job._location).Format("20060102150405"))
+	}
+	if dryrun || len(starts) == 0 {
+		resp.Status = "success"
+		return resp, nil
+	}
+	reason := Reason{Action: "backfill", User: user, Comment: fmt.Sprintf("%s-%s", from, to), Timestamp: time.Now().Unix()}
+	go job.Backfill(starts, parallel, reason)
+	resp.Status = "started"
+	return resp, nil
+}
+// Blackouts lists the Blackout windows of the server
+func (k service) Blackouts(user string) (*BlackoutResponse, error) {
+	return newBlackoutResponse("success"), nil
+}
+
+// AddBlackout adds a Blackout window, e.g. an ad-hoc freeze, which is kept until it ends
+// or is removed with RemoveBlackout
+func (k service) AddBlackout(user string, b Blackout) (*BlackoutResponse, error) {
+	ServerLogger.Printf("\tBLACKOUT ADD\tID: %s\tuser:%s", b.ID, user)
+	if permitted := k.ServerConfig.hasPermission(user, "blackout"); !permitted {
+		return &BlackoutResponse{Status: "permission denied"}, ErrPermission
+	}
+	b.User = user
+	if _, err := addBlackout(b, k.ServerConfig.Timezone); err != nil {
+		return &BlackoutResponse{Status: err.Error()}, err
+	}
+	return newBlackoutResponse("success"), nil
+}
+
+// RemoveBlackout removes a Blackout window added with AddBlackout
+func (k service) RemoveBlackout(user string, id string) (*BlackoutResponse, error) {
+	ServerLogger.Printf("\tBLACKOUT REMOVE\tID: %s\tuser:%s", id, user)
+	if permitted := k.ServerConfig.hasPermission(user, "blackout"); !permitted {
+		return &BlackoutResponse{Status: "permission denied"}, ErrPermission
+	}
+	if err := removeBlackout(id); err != nil {
+		return &BlackoutResponse{Status: err.Error()}, err
+	}
+	return newBlackoutResponse("success"), nil
+}
+
+// Calendars lists the calendars known to the server, with the jobs of user using each
+func (k service) Calendars(user string) (*CalendarResponse, error) {
+	return &CalendarResponse{Status: "success", Calendars: knownCalendars(k.ServerConfig.CalendarDirs, k.userJobs(user, ""))}, nil
+}
+
+// CalendarDates returns the days of calendar from through to (YYYYMMDD, defaulting to the
+// current month), with the jobs of user triggering on each
+func (k service) CalendarDates(user, calendar string, dirs []string, from, to string) (*CalendarResponse, error) {
+	dirs, err := k.calendarDirs(calendar, dirs)
+	if err != nil {
+		return &CalendarResponse{Status: err.Error(), Calendar: calendar}, err
+	}
+	cal, err := lookupCalendar(calendar, dirs)
+	if err != nil {
+		return &CalendarResponse{Status: err.Error(), Calendar: calendar}, err
+	}
+	loc := calendarLocation(k.ServerConfig.Timezone)
+	start, err := parseCalendarDate(from, loc)
+	if err != nil {
+		return &CalendarResponse{Status: err.Error(), Calendar: calendar}, err
+	}
+	if from == "" {
+		start = start.AddDate(0, 0, 1-start.Day())
+	}
+	end := start.AddDate(0, 1, -1)
+	if to != "" {
+		if end, err = parseCalendarDate(to, loc); err != nil {
+			return &CalendarResponse{Status: err.Error(), Calendar: calendar}, err
+		}
+	}
+	days, err := calendarDays(cal, start, end, k.userJobs(user, calendar))
+	if err != nil {
+		return &CalendarResponse{Status: err.Error(), Calendar: calendar}, err
+	}
+	return &CalendarResponse{Status: "success", Calendar: calendar, Days: days}, nil
+}
+
+// CalendarCheck returns date (YYYYMMDD, defaulting to today) of calendar, with its
+// availability, the previous and next available dates and the jobs of user triggering
+func (k service) CalendarCheck(user, calendar string, dirs []string, date string) (*CalendarResponse, error) {
+	t, err := parseCalendarDate(date, calendarLocation(k.ServerConfig.Timezone))
+	if err != nil {
+		return &CalendarResponse{Status: err.Error(), Calendar: calendar}, err
+	}
+	d := t.Format("20060102")
+	return k.CalendarDates(user, calendar, dirs, d, d)
+}
+
+// userJobs returns the jobs visible to user, optionally only those using calendar
+func (k service) userJobs(user, calendar string) []*Job {
+	var jobs []*Job
+	for _, id := range k.JobOrder {
+		job, ok := k.Jobs[id]
+		if !ok || (user != job.User && !stringInSlice(user, job.Admin)) {
+			continue
+		}
+		if calendar != "" && job.Calendar != calendar {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// calendarDirs returns dirs, or if empty the CalendarDirs of a job using calendar or
+// of the server. So calendars can only be read from the directories of the server or a
+// job, dirs must be CalendarDirs of either and calendar names cannot contain paths
+func (k service) calendarDirs(calendar string, dirs []string) ([]string, error) {
+	if strings.ContainsAny(calendar, `/\`) {
+		return nil, errors.New(fmt.Sprintf("invalid calendar %q", calendar))
+	}
+	for _, name := range tokenizeCalendar(calendar) {
+		if name == "." || name == ".." {
+			return nil, errors.New(fmt.Sprintf("invalid calendar %q", calendar))
+		}
+	}
+	if len(dirs) > 0 {
+		known := make(map[string]bool)
+		add := func(cdirs []string) {
+			for _, dir := range cdirs {
+				if abs, err := filepath.Abs(dir); err == nil {
+					known[abs] = true
+				}
+			}
+		}
+		add(k.ServerConfig.CalendarDirs)
+		for _, job := range k.Jobs {
+			add(job.CalendarDirs)
+		}
+		cdirs := make([]string, len(dirs))
+		for i, dir := range dirs {
+			abs, err := filepath.Abs(dir)
+			if err != nil || !known[abs] {
+				return nil, errors.New(fmt.Sprintf("calendarDirs %s is not a CalendarDirs of the server or a job", dir))
+			}
+			cdirs[i] = abs
+		}
+		return cdirs, nil
+	}
+	for _, job := range k.Jobs {
+		if job.Calendar == calendar && len(job.CalendarDirs) > 0 {
+			return job.CalendarDirs, nil
+		}
+	}
+	return k.ServerConfig.CalendarDirs, nil
+}
+
 func (k service) Resume(jobid string, user string) (*JobUpdateParams, error) {
 	ServerLogger.Printf("\tRESUME\tJobUUID: %s\tuser:%s", jobid, user)
 	job, ok := k.Jobs[jobid]
@@ -400,10 +627,37 @@ type logRequest struct {
 	LastMod int64  `json:"lastmod"`
 }
 
+type backfillRequest struct {
+	JobID    string `json:"jobid"`
+	UserID   string `json:"userid"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Calendar string `json:"calendar"`
+	Parallel int    `json:"parallel"`
+	DryRun   bool   `json:"dryrun"`
+}
+
+type blackoutRequest struct {
+	UserID   string   `json:"userid"`
+	ID       string   `json:"id"`
+	Blackout Blackout `json:"blackout"`
+}
+
+type calendarRequest struct {
+	UserID       string   `json:"userid"`
+	Calendar     string   `json:"calendar"`
+	CalendarDirs []string `json:"calendarDirs"`
+	From         string   `json:"from"`
+	To           string   `json:"to"`
+	Date         string   `json:"date"`
+}
+
 type kRequest struct {
-	JobID  string `json:"jobid"`
-	RunID  string `json:"runid"`
-	UserID string `json:"userid"`
+	JobID   string            `json:"jobid"`
+	RunID   string            `json:"runid"`
+	UserID  string            `json:"userid"`
+	Comment string            `json:"comment,omitempty"`
+	Params  map[string]string `json:"params,omitempty"`
 }
 type kResponse struct {
 	Job interface{} `json:"job"`           // should be renamed to Resp or something as it is generic
@@ -517,7 +771,7 @@ func makeHoldEndpoint(svc Service) endpoint.Endpoint {
 func makeStartEndpoint(svc Service) endpoint.Endpoint {
 	return func(_ context.Context, request interface{}) (interface{}, error) {
 		req := request.(kRequest)
-		ctl, err := svc.Start(req.JobID, req.UserID, "")
+		ctl, err := svc.Start(req.JobID, req.UserID, req.Comment, req.Params)
 		if err != nil {
 			return *ctl, nil
 		}
@@ -527,7 +781,7 @@ func makeStartEndpoint(svc Service) endpoint.Endpoint {
 func makeStopEndpoint(svc Service) endpoint.Endpoint {
 	return func(_ context.Context, request interface{}) (interface{}, error) {
 		req := request.(kRequest)
-		ctl, err := svc.Stop(req.JobID, req.UserID, "")
+		ctl, err := svc.Stop(req.JobID, req.RunID, req.UserID, "")
 		if err != nil {
 			return *ctl, nil
 		}
@@ -544,6 +798,76 @@ func makeRestartEndpoint(svc Service) endpoint.Endpoint {
 		return *ctl, nil
 	}
 }
+func makeBackfillEndpoint(svc Service) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(backfillRequest)
+		resp, err := svc.Backfill(req.JobID, req.UserID, req.From, req.To, req.Calendar, req.Parallel, req.DryRun)
+		if err != nil {
+			return kResponse{*resp, err.Error()}, nil
+		}
+		return kResponse{*resp, ""}, nil
+	}
+}
+func makeBlackoutsEndpoint(svc Service) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(blackoutRequest)
+		resp, err := svc.Blackouts(req.UserID)
+		if err != nil {
+			return kResponse{*resp, err.Error()}, nil
+		}
+		return kResponse{*resp, ""}, nil
+	}
+}
+func makeAddBlackoutEndpoint(svc Service) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(blackoutRequest)
+		resp, err := svc.AddBlackout(req.UserID, req.Blackout)
+		if err != nil {
+			return kResponse{*resp, err.Error()}, nil
+		}
+		return kResponse{*resp, ""}, nil
+	}
+}
+func makeRemoveBlackoutEndpoint(svc Service) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(blackoutRequest)
+		resp, err := svc.RemoveBlackout(req.UserID, req.ID)
+		if err != nil {
+			return kResponse{*resp, err.Error()}, nil
+		}
+		return kResponse{*resp, ""}, nil
+	}
+}
+func makeCalendarsEndpoint(svc Service) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(calendarRequest)
+		resp, err := svc.Calendars(req.UserID)
+		if err != nil {
+			return kResponse{*resp, err.Error()}, nil
+		}
+		return kResponse{*resp, ""}, nil
+	}
+}
+func makeCalendarDatesEndpoint(svc Service) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(calendarRequest)
+		resp, err := svc.CalendarDates(req.UserID, req.Calendar, req.CalendarDirs, req.From, req.To)
+		if err != nil {
+			return kResponse{*resp, err.Error()}, nil
+		}
+		return kResponse{*resp, ""}, nil
+	}
+}
+func makeCalendarCheckEndpoint(svc Service) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(calendarRequest)
+		resp, err := svc.CalendarCheck(req.UserID, req.Calendar, req.CalendarDirs, req.Date)
+		if err != nil {
+			return kResponse{*resp, err.Error()}, nil
+		}
+		return kResponse{*resp, ""}, nil
+	}
+}
 func makeStatusEndpoint(svc Service) endpoint.Endpoint {
 	return func(_ context.Context, request interface{}) (interface{}, error) {
 		req := request.(kRequest)
@@ -580,6 +904,43 @@ func decodeLogRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	}
 	return request, nil
 }
+func decodeBackfillRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var request backfillRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+	user, ok := GetUserFromAuth(r)
+	if ok {
+		request.UserID = user
+	}
+	return request, nil
+}
+func decodeBlackoutRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var request blackoutRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			return nil, err
+		}
+	}
+	user, ok := GetUserFromAuth(r)
+	if ok {
+		request.UserID = user
+	}
+	return request, nil
+}
+func decodeCalendarRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var request calendarRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			return nil, err
+		}
+	}
+	user, ok := GetUserFromAuth(r)
+	if ok {
+		request.UserID = user
+	}
+	return request, nil
+}
 func decodeKRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var request kRequest
 	user, ok := GetUserFromAuth(r)
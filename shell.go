@@ -0,0 +1,67 @@
+package rpeat
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Shell is the interpreter a job is run with, as a name on PATH or a path followed by
+// any arguments, e.g. "sh", "/bin/bash -eu" or "python3 -u". With a Shell, Cmd is
+// run as
+//
+//	SHELL [ARGS...] -c CMD
+//
+// avoiding the quoting of a "/bin/sh -c" prefix in Cmd. Script is a multi-line body
+// that is written to a file of each run under the run directory of the job
+// (TmpDir/JobUUID/RunUUID.script) and run as
+//
+//	SHELL [ARGS...] FILE
+//
+// using /bin/sh if Shell is not set. Cmd and Script cannot both be set.
+
+const defaultShell = "/bin/sh"
+
+// shellArgs splits shell into the path of the interpreter and its arguments
+func shellArgs(shell string) []string {
+	args := strings.Fields(shell)
+	if len(args) == 0 {
+		args = []string{defaultShell}
+	}
+	if !strings.Contains(args[0], string(os.PathSeparator)) {
+		if path, err := exec.LookPath(args[0]); err == nil {
+			args[0] = path
+		}
+	}
+	return args
+}
+
+// scriptFile is the file Script is written to for the run runid
+func (job *Job) scriptFile(runid uuid.UUID) string {
+	return filepath.Join(job.TmpDir, fmt.Sprintf("%s", job.JobUUID), fmt.Sprintf("%s.script", runid))
+}
+
+// writeScript writes Script to the file of the run runid, if the job has a Script
+func (job *Job) writeScript(runid uuid.UUID) error {
+	if job.Script == "" {
+		return nil
+	}
+	job.runDir()
+	f, err := createRunFile(job.scriptFile(runid), os.FileMode(0700))
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write([]byte(job.Script)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// hasCmd is true if the job runs a process, i.e. has a Cmd or a Script
+func (job *Job) hasCmd() bool {
+	return job.Cmd != nil || job.Script != ""
+}
@@ -3,14 +3,115 @@
 package rpeat
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
+	"runtime"
+	"strings"
 	"syscall"
 )
 
+// the Umask and the path of the command of a run started by umaskCmd
+const umaskEnv = "_RPEAT_UMASK"
+
+// oNoFollow fails an open of a symlink, see createRunFile
+const oNoFollow = syscall.O_NOFOLLOW
+
+// A run with Umask is started as the rpeat executable, which sets its own umask in init and
+// then execs the command of the job, as the umask of the server is shared by all its runs
+func init() {
+	if v := os.Getenv(umaskEnv); v != "" {
+		execUmask(v)
+	}
+}
+
+// umaskCmd starts c with umask mask. It must be applied before limitCmd, so a run with
+// both Limits and Umask passes through the rpeat executable twice, setting the Limits then
+// the umask
+func umaskCmd(c *exec.Cmd, mask int) error {
+	if c.Err != nil {
+		return nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if c.Env == nil {
+		c.Env = os.Environ()
+	}
+	c.Env = append(c.Env, fmt.Sprintf("%s=%04o:%s", umaskEnv, mask, c.Path))
+	c.Path = exe
+	return nil
+}
+
+// execUmask sets the umask and execs the path of v, MASK:PATH
+func execUmask(v string) {
+	kv := strings.SplitN(v, ":", 2)
+	mask, err := parseUmask(kv[0])
+	if err != nil || len(kv) != 2 {
+		fmt.Fprintf(os.Stderr, "[ rpeat ] invalid %s %q\n", umaskEnv, v)
+		os.Exit(127)
+	}
+	syscall.Umask(mask)
+	var env []string
+	for _, e := range os.Environ() {
+		if !strings.HasPrefix(e, umaskEnv+"=") {
+			env = append(env, e)
+		}
+	}
+	err = syscall.Exec(kv[1], os.Args, env)
+	fmt.Fprintf(os.Stderr, "[ rpeat ] Unable to create process %s: %s\n", kv[1], err)
+	os.Exit(127)
+}
+
 func syscallSysProcAttr() *syscall.SysProcAttr {
 	return &syscall.SysProcAttr{Setpgid: true}
 }
 
+func syscallCredential(attr *syscall.SysProcAttr, uid, gid uint32, groups []uint32) error {
+	attr.Credential = &syscall.Credential{Uid: uid, Gid: gid, Groups: groups}
+	return nil
+}
+
+// syscallAccess reports whether uid, with groups gids, is permitted mode (4 read, 2 write,
+// 1 execute or search) by the owner, group and permissions of fi
+func syscallAccess(fi os.FileInfo, uid uint32, gids []uint32, mode uint32) bool {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	if uid == 0 {
+		return true
+	}
+	perm := uint32(fi.Mode().Perm())
+	if st.Uid == uid {
+		perm >>= 6
+	} else {
+		for _, gid := range gids {
+			if st.Gid == gid {
+				perm >>= 3
+				break
+			}
+		}
+	}
+	return perm&mode == mode
+}
+
+func syscallUsage(ps *os.ProcessState, u *Usage) {
+	ru, ok := ps.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return
+	}
+	u.MaxRSS = int64(ru.Maxrss)
+	if runtime.GOOS != "darwin" {
+		u.MaxRSS *= 1024 // kilobytes
+	}
+	u.InBlock = int64(ru.Inblock)
+	u.OutBlock = int64(ru.Oublock)
+	u.VolCtxSw = int64(ru.Nvcsw)
+	u.InvolCtxSw = int64(ru.Nivcsw)
+}
+
 func syscallGetpgid(pid int) (pgid int, err error) {
 	pgid, err = syscall.Getpgid(pid)
 	return
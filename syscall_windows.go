@@ -6,13 +6,31 @@ import (
 	"errors"
 	"math"
 	"os"
+	"os/exec"
 	"syscall"
 )
 
+// oNoFollow is unsupported in windows, where RunAs is also unsupported
+const oNoFollow = 0
+
 func syscallSysProcAttr() *syscall.SysProcAttr {
 	return &syscall.SysProcAttr{}
 }
 
+func syscallCredential(attr *syscall.SysProcAttr, uid, gid uint32, groups []uint32) error {
+	return errors.New("RunAs is unsupported in windows")
+}
+
+func syscallAccess(fi os.FileInfo, uid uint32, gids []uint32, mode uint32) bool {
+	return false
+}
+
+func syscallUsage(ps *os.ProcessState, u *Usage) {}
+
+func umaskCmd(c *exec.Cmd, mask int) error {
+	return errors.New("Umask is unsupported in windows")
+}
+
 func syscallGetpgid(pid int) (pgid int, err error) {
 	pgid = int(math.Abs(float64(pid)))
 	err = errors.New("unsupported group pid in windows")
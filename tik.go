@@ -1,6 +1,7 @@
 package rpeat
 
 import (
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"io"
@@ -30,10 +31,15 @@ func RegisterJob(job *Job, updates chan *JobUpdate, depEvt chan *depEvt, stop <-
 		job.Logging.l.Reset(n.PrevStop.Add(job.Logging.purge).Sub(time.Now()))
 	}
 
-	d, next := NextCronStart(job.cronStartArray)
-	job.t = time.NewTimer(d)
+	d, next, scheduled := NextCronSchedule(job.cronStartArray)
+	if job.queueMissed(job.prevStart, time.Now()) > 0 {
+		job.t = time.NewTimer(0)
+	} else {
+		job.t = time.NewTimer(d)
+	}
 
-	job.setNextStart(next)
+	job.setNextStart(next, scheduled)
+	job.checkCalendar()
 	job.updates = updates
 	job.state = depEvt
 
@@ -93,13 +99,18 @@ func RegisterJob(job *Job, updates chan *JobUpdate, depEvt chan *depEvt, stop <-
 
 		job.runlock.Lock()
 		job.t.Stop()
+		job.asof = ""
+		if !job.Unscheduled {
+			job.params = nil // Params are only overridden by a manual start
+		}
+		trigger := job.triggerAsOf()
 
-		d, next = NextCronStart(job.cronStartArray)
-		job.setNextStart(next)
+		d, next, scheduled = NextCronSchedule(job.cronStartArray)
+		job.setNextStart(next, scheduled)
 		ServerLogger.Printf("Next job %s <%s> scheduled for %s [%d] (starts in %s)\n", job.Name, job.JobUUID, next, next.Unix(), d.Round(time.Second))
 
 		if job.Updating {
-			job.resetTimer(d)
+			job.resetTimer(job.catchUpDelay(d))
 			job.Updating = false
 			job.sendUpdate()
 			job.runlock.Unlock()
@@ -108,6 +119,7 @@ func RegisterJob(job *Job, updates chan *JobUpdate, depEvt chan *depEvt, stop <-
 
 		if job.Hold {
 			ServerLogger.Printf("Job Trigger Ignored Job on Hold")
+			job.clearMissed()
 			job.resetTimer(d)
 			job.setJobState(JMissedWarning)
 			job.sendUpdate() // keep jobs on Hold updating next scheduled start
@@ -115,6 +127,43 @@ func RegisterJob(job *Job, updates chan *JobUpdate, depEvt chan *depEvt, stop <-
 			continue
 		}
 
+		// scheduled triggers within a Blackout window are deferred or skipped
+		if !job.Unscheduled && job.blackout() {
+			job.runlock.Unlock()
+			continue
+		}
+
+		// StartRule "Start" runs each trigger as a separate instance without waiting
+		if job.startRule.Concurrent && !job.isController() && job.hasCmd() {
+			if job.startInstance(job.Unscheduled, job.Reason, trigger) {
+				job.resetTimer(job.catchUpDelay(d))
+			} else {
+				job.resetTimer(d)
+			}
+			job.Unscheduled = false
+			job.sendUpdateClient()
+			job.runlock.Unlock()
+			continue
+		}
+
+		// missed triggers run with the missed time as asof, which is kept for retries
+		if !job.Unscheduled {
+			if job.asof = job.nextMissed(); job.asof == "" {
+				job.asof = trigger
+			}
+		}
+
+		// wait for Pool and JobsControl.MaxConcurrent slots
+		if !job.waitForSlots() {
+			ServerLogger.Printf("Queued trigger cancelled for %s:%s", job.JobUUID, job.Name)
+			d, next, scheduled = NextCronSchedule(job.cronStartArray)
+			job.resetTimer(d)
+			job.setNextStart(next, scheduled)
+			job.sendUpdate()
+			job.runlock.Unlock()
+			continue
+		}
+
 		maxduration := time.NewTimer(maxd)
 		if job.Retry > 0 {
 			go runTik(job, pid, true)
@@ -122,7 +171,7 @@ func RegisterJob(job *Job, updates chan *JobUpdate, depEvt chan *depEvt, stop <-
 			go runTik(job, pid, false)
 		}
 		if job.startRule.Concurrent || job.cronStart.IsEvery() {
-			job.resetTimer(d)
+			job.resetTimer(job.catchUpDelay(d))
 			//ServerLogger.Printf("[Concurrent] next job %s<%s> scheduled for %s [%d] (starts in %s)\n", job.Name, job.JobUUID, next, next.Unix(), d)
 		}
 
@@ -134,13 +183,13 @@ func RegisterJob(job *Job, updates chan *JobUpdate, depEvt chan *depEvt, stop <-
 			if job.MaxDuration != "" && job.startRule.Concurrent == false {
 				go endAtTime(maxduration, job, ctl, "maxduration", mend) //, false)
 			}
-			if job.CronEnd != nil {
+			if job.hasCronEnd() {
 				e, _ := NextCronStart(job.cronEndArray)
 				te.Reset(e)
 				go endAtTime(te, job, ctl, "cronend", eend)
 			}
-			if job.CronRestart != nil {
-				r, _ := getNextStart(job.cronRestart)
+			if job.hasCronRestart() {
+				r, _ := NextCronStart(job.cronRestartArray)
 				tr.Reset(r)
 				ServerLogger.Printf("Running job %s scheduled to RESTART %s [%d] (ends in %s)\n", job.Name, next, next.Unix(), r.Round(time.Second))
 				go func() {
@@ -154,17 +203,18 @@ func RegisterJob(job *Job, updates chan *JobUpdate, depEvt chan *depEvt, stop <-
 
 			s = <-job.status // blocks until runTik completes
 		}
+		job.releaseHeldSlots()
 
 		// stop all go routines watching for end/restart/maxduration triggers
 		isStopped := maxduration.Stop()
-		if job.CronEnd != nil {
+		if job.hasCronEnd() {
 			isStopped = te.Stop()
 			ServerLogger.Printf("CronEnd (te):%t", isStopped)
 			if isStopped {
 				eend <- true
 			}
 		}
-		if job.CronRestart != nil {
+		if job.hasCronRestart() {
 			isStopped = tr.Stop()
 			ServerLogger.Printf("CronRestart (tr):%t", isStopped)
 			if isStopped {
@@ -173,9 +223,9 @@ func RegisterJob(job *Job, updates chan *JobUpdate, depEvt chan *depEvt, stop <-
 		}
 
 		if !job.startRule.Concurrent && !job.cronStart.IsEvery() {
-			d, next = NextCronStart(job.cronStartArray)
-			job.resetTimer(d)
-			job.setNextStart(next)
+			d, next, scheduled = NextCronSchedule(job.cronStartArray)
+			job.resetTimer(job.catchUpDelay(d))
+			job.setNextStart(next, scheduled)
 			job.sendUpdate()
 			ServerLogger.Printf("[Not Concurrent] next job %s scheduled for %s [%d] (starts in %s)\n", job.Name, next, next.Unix(), d)
 		}
@@ -194,7 +244,8 @@ func RegisterJob(job *Job, updates chan *JobUpdate, depEvt chan *depEvt, stop <-
 			if s == 0 || job.Hold {
 				break
 			}
-			if s != 0 && job.Retry > retry {
+			limited := job.JobState == JLimitExceeded // runs exceeding Limits are not retried
+			if s != 0 && job.Retry > retry && !limited {
 				job.t.Stop() //stop current job while we retry
 
 				if !job.Restarting {
@@ -214,6 +265,9 @@ func RegisterJob(job *Job, updates chan *JobUpdate, depEvt chan *depEvt, stop <-
 					job.setJobState(JRunning)
 					job.sendUpdate()
 				}
+				if !job.waitForSlots() {
+					break
+				}
 				maxduration.Reset(maxd)
 				go runTik(job, pid, !job.Restarting)
 				thispid = <-pid
@@ -221,15 +275,16 @@ func RegisterJob(job *Job, updates chan *JobUpdate, depEvt chan *depEvt, stop <-
 					go endAtTime(maxduration, job, ctl, "maxduration(retry)", mend)
 				}
 				s = <-job.status
+				job.releaseHeldSlots()
 				if s == 0 {
-					d, next = NextCronStart(job.cronStartArray)
+					d, next, scheduled = NextCronSchedule(job.cronStartArray)
 					//if !job.cronStart.IsEvery() {
-					job.resetTimer(d)
+					job.resetTimer(job.catchUpDelay(d))
 					//}
 					ServerLogger.Printf("[Retry Success] next job %s scheduled for %s [%d] (starts in %s)\n", job.Name, next, next.Unix(), d)
 					retry = 0
 					job.setRetryAttempt(retry)
-					job.setNextStart(next)
+					job.setNextStart(next, scheduled)
 					if job.JobState == JRunning {
 						if job.Unscheduled {
 							job.setJobState(JManualSuccess)
@@ -251,7 +306,7 @@ func RegisterJob(job *Job, updates chan *JobUpdate, depEvt chan *depEvt, stop <-
 			   }
 			*/
 
-			if job.Retry == retry { // reset timer
+			if job.Retry == retry || limited { // reset timer
 				job.t.Stop() //stop current job while we retry
 				//log.Printf("job %s has failed %d times. Terminating with permanent failure\n", job.Name, job.Retry)
 				proc, err := os.FindProcess(thispid)
@@ -264,12 +319,14 @@ func RegisterJob(job *Job, updates chan *JobUpdate, depEvt chan *depEvt, stop <-
 				if !job.cronStart.isDependent() {
 					job.setHold(true)
 				}
-				job.setJobState(JFailed)
-				d, next = NextCronStart(job.cronStartArray)
+				if !limited {
+					job.setJobState(JFailed)
+				}
+				d, next, scheduled = NextCronSchedule(job.cronStartArray)
 				job.resetTimer(d)
 				retry = 0
 				job.setRetryAttempt(retry)
-				job.setNextStart(next)
+				job.setNextStart(next, scheduled)
 				job.sendUpdate()
 				break
 			}
@@ -330,12 +387,17 @@ func runTik(job *Job, pid chan int, retry bool) {
 
 	job.RunUUID = uuid.New()
 
-	if job.Cmd != nil {
+	if job.hasCmd() {
 
-		c, err := evaluatedCmd(job, false, "")
+		c, err := evaluatedCmd(job, false, job.asof)
 		if err != nil {
 			ServerLogger.Printf(err.Error())
 		}
+		scriptErr := job.writeScript(job.RunUUID)
+		if scriptErr != nil {
+			scriptErr = errors.New(fmt.Sprintf("unable to write Script: %s", scriptErr))
+			ServerLogger.Printf("[runTik] %s %s", job.Name, scriptErr)
+		}
 
 		// Kill child processes under *nix
 		// https://medium.com/@felixge/killing-a-child-process-and-all-of-its-children-in-go-54079af94773
@@ -346,12 +408,12 @@ func runTik(job *Job, pid chan int, retry bool) {
 
 		// SetUID and GID
 		// https://stackoverflow.com/questions/21705950/running-external-commands-through-os-exec-under-another-user
-		//
-		// c.SysProcAttr.Credential = &syscall.Credential{Uid: uid, Gid: gid}
-
-		jobRunDir := filepath.Join(job.TmpDir, fmt.Sprintf("%s", job.JobUUID))
+		uid, gid, runAsErr := job.applyRunAs(c.SysProcAttr)
+		stdin, workDirErr := job.applyWorkDir(&c, job.asof, uid, gid)
+		lim := job.newLimiter(&c, job.RunUUID)
 
 		var ferr error
+		var runFiles []*os.File
 		var stdout []io.Writer
 		var stdoutfile *os.File
 
@@ -362,16 +424,16 @@ func runTik(job *Job, pid chan int, retry bool) {
 
 		// if Logging.Std*File may change to slices to allow for tee style behavior
 		if job.Logging.StdoutFile != "" {
-			stdoutfile, ferr = os.OpenFile(job.ExpandEnv([]string{job.Logging.StdoutFile}, "")[0], flags, os.FileMode(0660))
+			stdoutfile, ferr = os.OpenFile(job.ExpandEnv([]string{job.Logging.StdoutFile}, job.asof)[0], flags, os.FileMode(0660))
 			if ferr != nil {
 				ServerLogger.Fatal(ferr.Error())
 			}
 		} else {
-			_ = os.Mkdir(jobRunDir, os.FileMode(0770))
-			stdoutfile, ferr = os.Create(filepath.Join(jobRunDir, fmt.Sprintf("%s.stdout", job.RunUUID)))
+			stdoutfile, ferr = createRunFile(filepath.Join(job.runDir(), fmt.Sprintf("%s.stdout", job.RunUUID)), os.FileMode(0660))
 			if ferr != nil {
 				ServerLogger.Fatal(ferr.Error())
 			}
+			runFiles = append(runFiles, stdoutfile)
 		}
 		job.Logging.stdoutFile = stdoutfile.Name()
 		job.StdoutFile = []string{job.Logging.stdoutFile}
@@ -386,16 +448,16 @@ func runTik(job *Job, pid chan int, retry bool) {
 		var stderrfile *os.File
 
 		if job.Logging.StderrFile != "" {
-			stderrfile, ferr = os.OpenFile(job.ExpandEnv([]string{job.Logging.StderrFile}, "")[0], flags, os.FileMode(0660))
+			stderrfile, ferr = os.OpenFile(job.ExpandEnv([]string{job.Logging.StderrFile}, job.asof)[0], flags, os.FileMode(0660))
 			if ferr != nil {
 				ServerLogger.Fatal(ferr.Error())
 			}
 		} else {
-			_ = os.Mkdir(jobRunDir, os.FileMode(0770))
-			stderrfile, ferr = os.Create(filepath.Join(jobRunDir, fmt.Sprintf("%s.stderr", job.RunUUID)))
+			stderrfile, ferr = createRunFile(filepath.Join(job.runDir(), fmt.Sprintf("%s.stderr", job.RunUUID)), os.FileMode(0660))
 			if ferr != nil {
 				ServerLogger.Fatal(ferr.Error())
 			}
+			runFiles = append(runFiles, stderrfile)
 		}
 		job.Logging.stderrFile = stderrfile.Name()
 		job.StderrFile = []string{job.Logging.stderrFile}
@@ -405,9 +467,23 @@ func runTik(job *Job, pid chan int, retry bool) {
 		}
 		stderr = append(stderr, stderrfile)
 		c.Stderr = io.MultiWriter(stderr...)
+		job.chownRun(uid, gid, job.RunUUID, runFiles...)
 
-		err = c.Start()
+		err = runAsErr
+		if err == nil {
+			err = workDirErr
+		}
+		if err == nil {
+			err = scriptErr
+		}
+		if err == nil {
+			err = c.Start()
+		}
+		if stdin != nil {
+			stdin.Close()
+		}
 		if err != nil {
+			lim.ended(nil)
 			ServerLogger.Printf("[runTik] %s failed to start with error ( %s )", job.Name, err)
 			job.Lock()
 			job.pid = 0
@@ -426,6 +502,7 @@ func runTik(job *Job, pid chan int, retry bool) {
 			job.elapsed = job.prevStop.Sub(job.prevStart).Round(time.Second)
 			job.Elapsed = dhms(job.elapsed)
 			job.ElapsedUNIX = elapsedToInt(job.elapsed)
+			job.recordUsage(nil)
 			job.Unscheduled = true
 			_, err := c.Stderr.Write([]byte("[ rpeat ] Unable to create process (possibly missing shell e.g. /bin/sh -c ): " + err.Error()))
 			retry = false
@@ -452,12 +529,20 @@ func runTik(job *Job, pid chan int, retry bool) {
 		job.prevStart = time.Now()
 		job.StartedUNIX = job.prevStart.Unix()
 		job.Started = job.prevStart.In(job._location).Format("2006-01-02 15:04:05")
+		job.Warning = ""
+		job.LimitExceeded = ""
 
 		job.lock.Unlock()
+		lim.started(job.pid)
 		job.setJobState(JRunning)
 		job.sendUpdate()
 
+		tw := job.maxRuntimeWarning(nil)
 		err = c.Wait() // blocks until job ends, possibly also sending <-job.Ctl if external trigger
+		if tw != nil {
+			tw.Stop()
+		}
+		job.LimitExceeded = lim.ended(c.ProcessState)
 
 		errcode = 0
 		if err != nil {
@@ -478,10 +563,7 @@ func runTik(job *Job, pid chan int, retry bool) {
 		job.elapsed = job.prevStop.Sub(job.prevStart).Round(time.Second)
 		job.Elapsed = dhms(job.elapsed)
 		job.ElapsedUNIX = elapsedToInt(job.elapsed)
-
-		// if job.elapsed < job.MinDuration {
-		//    job.SetJobState(KWarning)
-		// }
+		job.recordUsage(processUsage(c.ProcessState))
 
 		job.Pid = 0
 		job.pid = job.Pid
@@ -529,8 +611,15 @@ func runTik(job *Job, pid chan int, retry bool) {
 	default:
 		job.ExitCode = errcode
 		evt = &Ctl{killed: false, code: JState(errcode)}
-		if errcode == 0 {
-			if job.Unscheduled {
+		if job.LimitExceeded != "" {
+			job.setJobState(JLimitExceeded)
+			job.status <- -1
+		} else if errcode == 0 {
+			if w := job.minRuntimeWarning(job.elapsed); w != "" {
+				ServerLogger.Printf("[MinRuntime] %s:%s %s", job.JobUUID, job.Name, w)
+				job.Warning = w
+				job.setJobState(JWarning)
+			} else if job.Unscheduled {
 				job.setJobState(JManualSuccess)
 			} else {
 				job.setJobState(JSuccess)
@@ -555,6 +644,11 @@ func runTik(job *Job, pid chan int, retry bool) {
 func shutdownJob(job *Job, jstate JState) {
 	ServerLogger.Printf("[shutdownJob] triggered for %s (%s => %s)", job.JobUUID, job.JobState, jstate)
 
+	if job.hasInstances() { // concurrent instances are signalled individually
+		stopJob(job, jstate)
+		return
+	}
+
 	pid := job.pid
 	if pid == 0 && job.cronStart.isDependent() {
 		//ServerLogger.Printf("[shutdownJob] %s job has already exited", job.JobUUID)
@@ -563,7 +657,10 @@ func shutdownJob(job *Job, jstate JState) {
 
 	c, _ := evaluatedCmd(job, true, "")
 	c.SysProcAttr = syscallSysProcAttr()
-	err := c.Run()
+	_, _, err := job.applyRunAs(c.SysProcAttr)
+	if err == nil {
+		err = c.Run()
+	}
 	if err != nil {
 		ServerLogger.Println("shutdown failure:", err)
 	}
@@ -592,6 +689,26 @@ func resumeJob(job *Job) {
 
 func stopJob(job *Job, jstate JState) {
 
+	if job.hasInstances() {
+		ServerLogger.Printf("[stopJob] stopping %d instances of %s (%s)", job.Instances, job.JobUUID, job.JobState)
+		if !job.cronStart.isDependent() && jstate != JEnd {
+			job.setHold(true)
+		}
+		job.stopInstances(jstate)
+		return
+	}
+
+	if job.JobState == JQueued {
+		ServerLogger.Printf("[stopJob] removing %s from queue", job.JobUUID)
+		job.cancelQueued()
+		if !job.cronStart.isDependent() && jstate != JEnd {
+			job.setHold(true)
+		}
+		job.setJobState(jstate)
+		job.sendUpdate()
+		return
+	}
+
 	pid := job.getPid()
 	ServerLogger.Printf("[stopJob] triggered for %s (%d, %s)", job.JobUUID, pid, job.JobState)
 	if pid == 0 && job.cronStart.isDependent() { // FIXME: need to disable stops if job isn't running
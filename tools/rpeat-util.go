@@ -18,26 +18,74 @@ CMD:
     -calendarDirs
     -reqcal
     -rollback
+    -adjust
+    -dst
     -asof
     -sep
     -timefmt
     -n
+    -serverConfig
+    -group
+
+  backfill
+    -server
+    -user
+    -password
+    -job
+    -from
+    -to
+    -cal
+    -parallel
+    -dryrun
+    -insecure
+
+  start
+    -server
+    -user
+    -password
+    -job
+    -param
+    -comment
+    -insecure
 
 
 */
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"rpeat"
 	"strings"
 	"time"
 )
 
+// paramFlags collects repeated -param NAME=VALUE flags
+type paramFlags map[string]string
+
+func (p paramFlags) String() string {
+	var s []string
+	for k, v := range p {
+		s = append(s, k+"="+v)
+	}
+	return strings.Join(s, ",")
+}
+
+func (p paramFlags) Set(kv string) error {
+	i := strings.Index(kv, "=")
+	if i < 1 {
+		return fmt.Errorf("expecting NAME=VALUE, got %q", kv)
+	}
+	p[kv[:i]] = kv[i+1:]
+	return nil
+}
+
 func main() {
 	rpeat.Init()
 
@@ -59,12 +107,45 @@ Usage:
       argument allows for next scheduled trigger to be tested at arbitrary moments in
       time.  Output is delimited suitable for further inspection
 
+      RFC 5545 recurrence rules (as used in RRStart, RREnd and RRRestart) may
+      be given in place of a cron expression
+
+         rpeat-util next -cron "DTSTART:20240109T090000 RRULE:FREQ=MONTHLY;INTERVAL=3;BYDAY=2TU" -n 4
+
+      Daylight saving transitions are handled per -dst (see DSTPolicy in JobSpec),
+      with the zone of each trigger reported in the final column
+
+         rpeat-util next -cron "0 30 2 * * *" -tz America/New_York -asof 20240309000000 -dst skip -n 3
+
+      Calendars (-cal) are files in -calendarDirs of YYYYMMDD dates, or JSON holiday
+      rules (see CalendarRules), with built-in NYSE, US-FED, UK, TARGET and WEEKDAYS
+      calendars available without a file. Calendars may be combined with & (both
+      open), | (either open) and - (excluding), e.g. -cal "(NYSE & UK) - closures"
+
+         rpeat-util next -cron "0 0 9 * * *" -tz America/New_York -cal NYSE -n 5
+
+      Dates not in the calendar are moved per -adjust (see CalAdjust in JobSpec):
+      following, modified-following, preceding, modified-preceding or none
+
+         rpeat-util next -cron "0 0 9 L * *" -cal NYSE -adjust modified-following -n 12
+
+      Blackout windows of a server are applied with -serverConfig, including windows
+      added with the API, for jobs in the comma separated -group (all groups if empty).
+      Triggers deferred to the end of a window, or following a skipped trigger, report
+      the window in the final column
+
+         rpeat-util next -cron "0 0 * * * *" -serverConfig config.json -group Reports -n 6
+
     date: date variable expansion as used in DateEnv
 
       Convert specially formatted string into formatted date variable, similar
       to UNIX date function.
 
-         rpeat-util date -datevar DATEVAR[,ADJ[,CAL]]
+         rpeat-util date -datevar DATEVAR[,STEP]...[,CAL[,CALADJUST]]
+
+      where each STEP is a shift (e.g. -2D) or an anchor SOW, EOW, SOM, EOM, SOQ,
+      EOQ, SOY or EOY, applied in order. The date after each step is printed
+      before the result.
 
       If CAL is provided, -calendarDirs must contain specified calendar 
 
@@ -76,6 +157,36 @@ Usage:
          # today minus two months, using MF only
          rpeat-util date -datevar CCYYMMDD,+2M,MF -calendarDirs=caldir
 
+         # month end next month, modified following on NYSE business days
+         rpeat-util date -datevar CCYYMMDD,+1M,NYSE,modified-following
+
+         # last business day of the previous month, then two business days before
+         rpeat-util date -datevar CCYY-MM-DD,EOM,-1M,-2D,NYSE
+
+         # ISO week, day of year, NYSE business day of the month and fiscal quarter
+         rpeat-util date -datevar IYYY-WW_DDD_BD_FYFQ,NYSE -fiscal October
+
+    backfill: replay a job on a running server across historical asof dates
+
+      Every CronStart trigger of the job from -from through -to (YYYYMMDD or
+      YYYYMMDDhhmmss in the job Timezone) is run with the trigger time used as
+      the asof time for DateEnv and Cmd evaluation, up to the current time if -to
+      is today or later. An optional -cal replaces the job Calendar when computing
+      triggers. Runs appear in History flagged as backfill with their asof date.
+      Use -dryrun to list the asof times only.
+
+         rpeat-util backfill -server https://localhost:4334 -user admin -job daily-load -from 20240101 -to 20240131 -parallel 4
+
+      -user and -password default to RPEAT_USER and RPEAT_PASSWORD
+
+    start: manually start a job on a running server
+
+      Each -param overrides the Default of a Param declared by the job for this
+      run only. Values are validated by the server against the Param Type, Values
+      and Regex, and recorded in the History of the run.
+
+         rpeat-util start -server https://localhost:4334 -user admin -job daily-load -param REGION=eu -param LIMIT=500
+
     convert: convert job file(s) between xml and json format
 
     validate: comprehensive validation check on list of job file(s)
@@ -104,25 +215,30 @@ Usage:
   
 `, rpeat.BUILDDATE)
 	// next
-	var cron, cronfile, tz, cal, calendarDirs, asof, timefmt, sep string
+	var cron, cronfile, tz, cal, calendarDirs, asof, timefmt, sep, dst, adjust string
 	var reqcal, rollback, header, endof, verbose, asjson bool
+	var serverConfig, group string
 	var jitter int
 	var N int
 	nextCmd := flag.NewFlagSet("next", flag.ExitOnError)
-	nextCmd.StringVar(&cron, "cron", "", "required")
+	nextCmd.StringVar(&cron, "cron", "", "cron expression or RFC 5545 RRULE (required)")
 	nextCmd.StringVar(&cronfile, "cronfile", "", "file containing one cron spec per lin")
 	nextCmd.StringVar(&tz, "tz", "", "timezone (empty)")
-	nextCmd.StringVar(&cal, "cal", "", "calendar (empty)")
+	nextCmd.StringVar(&cal, "cal", "", "calendar, or calendar expression e.g. \"NYSE & UK\" (empty)")
 	nextCmd.StringVar(&calendarDirs, "calendarDirs", "", "calendar directories (empty)")
 	nextCmd.BoolVar(&reqcal, "reqcal", false, "if calendar unavailable for period return error, otherwise fallback to cron-only")
 	nextCmd.BoolVar(&rollback, "rollback", false, "if calendar date is not available for cron-interval, rollback to prior calendar date. Defaults to next.")
+	nextCmd.StringVar(&adjust, "adjust", "", "business day convention in place of -rollback: following, modified-following, preceding, modified-preceding or none (empty)")
 	nextCmd.BoolVar(&endof, "endof", false, "sets EndOf flag in cron")
+	nextCmd.StringVar(&dst, "dst", "run-once", "daylight saving policy: run-once, run-twice, skip or run-at-shift")
 	nextCmd.IntVar(&jitter, "jitter", 0, "add max `seconds` of random time (aka jitter) to start time")
 	nextCmd.StringVar(&asof, "asof", "", "asof time to calculate next start time formatted as YYYYMMDDmmddss (current time)")
 	nextCmd.StringVar(&sep, "sep", "|", "output field seperator (|)")
 	nextCmd.StringVar(&timefmt, "timefmt", "2006-01-02T15:04:05", "time format string expressed as golang format string (2006-01-02T15:04:05)")
 	nextCmd.BoolVar(&header, "header", true, "include column name header")
 	nextCmd.IntVar(&N, "n", 1, "number of future times to display")
+	nextCmd.StringVar(&serverConfig, "serverConfig", "", "server configuration `file` with Blackouts to apply (empty)")
+	nextCmd.StringVar(&group, "group", "", "comma seperated job groups used to select Blackouts (empty)")
 
 	// validate
 	var jobfiles, configFile, authFile string // TODO: add configFile and home option
@@ -143,13 +259,42 @@ Usage:
 	jobstateCmd.BoolVar(&uncompressed, "uncompressed", false, "is .rj file uncompressed (deprecated)")
 
 	// date
-	var datevar, timezone, caldirs string
+	var datevar, timezone, caldirs, fiscal string
 	dateCmd := flag.NewFlagSet("date", flag.ExitOnError)
-	dateCmd.StringVar(&datevar, "datevar", "", "one or more variables CC,YY,MM,hh,mm,ss, and/or QTR to be automagically replaced with two-digit century, year, month,\nhour, minute, second or quarter, respectively.  DATEVAR[[,[+-][0-9]{0,}[D|W|M|Q|Y][,CAL]]")
+	dateCmd.StringVar(&datevar, "datevar", "", "one or more variables CC,YY,MM,DD,hh,mm,ss,QTR,DDD,WW,IYYY,MON,Month,DOW,EPOCH,FY,FQ and/or BD to be automagically replaced\n(see ConvertDate), each any number of times, with DDD onwards replaced only as words.  DATEVAR[,STEP]...[,CAL[,CALADJUST]] where STEP is [+-][0-9]{0,}[D|W|M|Q|Y] or SOW|EOW|SOM|EOM|SOQ|EOQ|SOY|EOY")
+	dateCmd.StringVar(&fiscal, "fiscal", "", "first `month` of the fiscal year used by FY and FQ, as a name or number (January)")
 	dateCmd.StringVar(&timezone, "tz", "", "a valid IANA timezone. e.g. America/Chicago")
 	dateCmd.StringVar(&caldirs, "calendarDirs", "", "comma sep string of one or more calendar directories")
 	dateCmd.StringVar(&asof, "asof", "", "asof time to calculate next start time formatted as YYYYMMDDmmddss (current time)")
 
+	// backfill
+	var server, user, password, jobid, from, to string
+	var parallel int
+	var dryrun, insecure bool
+	backfillCmd := flag.NewFlagSet("backfill", flag.ExitOnError)
+	backfillCmd.StringVar(&server, "server", "http://localhost:4334", "rpeat® server `url`")
+	backfillCmd.StringVar(&user, "user", os.Getenv("RPEAT_USER"), "user for server authentication (RPEAT_USER)")
+	backfillCmd.StringVar(&password, "password", os.Getenv("RPEAT_PASSWORD"), "password for server authentication (RPEAT_PASSWORD)")
+	backfillCmd.StringVar(&jobid, "job", "", "job name or JobUUID (required)")
+	backfillCmd.StringVar(&from, "from", "", "first date of range as YYYYMMDD or YYYYMMDDhhmmss (required)")
+	backfillCmd.StringVar(&to, "to", "", "last date of range as YYYYMMDD or YYYYMMDDhhmmss (required)")
+	backfillCmd.StringVar(&cal, "cal", "", "calendar used in place of job Calendar (empty)")
+	backfillCmd.IntVar(&parallel, "parallel", 1, "maximum number of runs at once (1 is sequential)")
+	backfillCmd.BoolVar(&dryrun, "dryrun", false, "list asof times without running the job")
+	backfillCmd.BoolVar(&insecure, "insecure", false, "skip TLS certificate verification (self-signed certificates)")
+
+	// start
+	var comment string
+	params := paramFlags{}
+	startCmd := flag.NewFlagSet("start", flag.ExitOnError)
+	startCmd.StringVar(&server, "server", "http://localhost:4334", "rpeat® server `url`")
+	startCmd.StringVar(&user, "user", os.Getenv("RPEAT_USER"), "user for server authentication (RPEAT_USER)")
+	startCmd.StringVar(&password, "password", os.Getenv("RPEAT_PASSWORD"), "password for server authentication (RPEAT_PASSWORD)")
+	startCmd.StringVar(&jobid, "job", "", "job name or JobUUID (required)")
+	startCmd.Var(params, "param", "override of a job Param as `NAME=VALUE` (repeatable)")
+	startCmd.StringVar(&comment, "comment", "", "comment recorded with the start")
+	startCmd.BoolVar(&insecure, "insecure", false, "skip TLS certificate verification (self-signed certificates)")
+
 	if len(os.Args) == 1 || os.Args[1] == "-h" || os.Args[1] == "help" {
 		fmt.Println(help)
 		os.Exit(2)
@@ -193,40 +338,95 @@ Usage:
 			}
 		}
 
+		var blackouts rpeat.Blackouts
+		var groups []string
+		if serverConfig != "" {
+			sconf, err := rpeat.LoadServerConfig(serverConfig, false)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if blackouts, err = rpeat.LoadBlackouts(sconf); err != nil {
+				log.Fatal(err)
+			}
+			if group != "" {
+				groups = strings.Split(group, ",")
+			}
+		}
+
 		asof = t.Format(timefmt)
 		if err != nil {
 			log.Fatal(fmt.Sprintf("tz: %s failed to parse", tz))
 		}
 		if header {
-			fmt.Println(strings.Join([]string{"cron", "timezone", "calendar", "asOf", "triggerDate", "humanDate", "duration"}, sep))
+			columns := []string{"cron", "timezone", "calendar", "asOf", "triggerDate", "humanDate", "duration", "zone"}
+			if len(blackouts) > 0 {
+				columns = append(columns, "blackout")
+			}
+			fmt.Println(strings.Join(columns, sep))
+		}
+		dstPolicy, err := rpeat.ParseDSTPolicy(dst)
+		if err != nil {
+			log.Fatal(err)
+		}
+		calAdjust, err := rpeat.ParseCalAdjust(adjust)
+		if err != nil {
+			log.Fatal(err)
 		}
 		//var asofstring string
 		for ci := range crons {
 			c, err := rpeat.ParseCron(crons[ci], tz, cal, []string{calendarDirs}, rollback, reqcal, jitter)
 			c.EndOf = endof
+			c.DSTPolicy = dstPolicy
+			if adjust != "" {
+				c.SetAdjust(calAdjust)
+			}
+			at := t
+			asof = t.Format(timefmt)
 			for i := 0; i < N; i++ {
 				//asofstring = setTime(asof, timefmt, loc)
 
 				if err != nil {
 					panic(err)
 				}
-				d, next, err := c.NextStart("")
+				d, next, err := c.NextStartAfter(at)
+				window := ""
+				if len(blackouts) > 0 && err == nil {
+					var b *rpeat.Blackout
+					skipped := next
+					next, b = blackouts.Next([]rpeat.Cron{c}, groups, at)
+					d = next.Sub(at)
+					if b != nil {
+						window = b.String()
+					} else if !next.Equal(skipped) {
+						window = "skipped " + skipped.In(loc).Format(timefmt)
+					}
+				}
 
 				if err != nil {
 					log.Println(err)
 				}
 				if next.IsZero() {
-					fmt.Println(strings.Join([]string{crons[ci], tz, cal, asof, "NA", "NA", "NA"}, sep))
+					row := []string{crons[ci], tz, cal, asof, "NA", "NA", "NA", "NA"}
+					if len(blackouts) > 0 {
+						row = append(row, window)
+					}
+					fmt.Println(strings.Join(row, sep))
 					//fmt.Println(strings.Join([]string{cron,tz,cal,asofstring,"NA","NA","NA"}, sep))
 				} else {
 					nxtfmt := next.In(loc).Format(timefmt)
 					nxt := next.Format(time.ANSIC)
 					dur := rpeat.DHMS(d)
-					fmt.Println(strings.Join([]string{crons[ci], tz, cal, asof, nxtfmt, nxt, dur}, sep))
+					zone, _ := next.In(loc).Zone()
+					row := []string{crons[ci], tz, cal, asof, nxtfmt, nxt, dur, zone}
+					if len(blackouts) > 0 {
+						row = append(row, window)
+					}
+					fmt.Println(strings.Join(row, sep))
 					//fmt.Println(strings.Join([]string{cron,tz,cal,asofstring,nxtfmt,nxt,dur},sep))
 				}
 
 				newAsOf := next //.Add(time.Second).In(loc)
+				at = next
 				asof = newAsOf.Format(timefmt)
 				os.Setenv("RPEAT_NOW", newAsOf.Format("20060102150405"))
 				//asof = next.In(loc).Format("20060102150405")
@@ -276,6 +476,81 @@ Usage:
 		if exceptions.JState == rpeat.JConfigError {
 			os.Exit(2)
 		}
+	case "backfill":
+		backfillCmd.Parse(os.Args[2:])
+		if jobid == "" || from == "" || to == "" {
+			backfillCmd.PrintDefaults()
+			os.Exit(2)
+		}
+		req, _ := json.Marshal(map[string]interface{}{"jobid": jobid, "from": from, "to": to, "calendar": cal, "parallel": parallel, "dryrun": dryrun})
+		r, err := http.NewRequest("POST", strings.TrimRight(server, "/")+"/api/backfill", bytes.NewBuffer(req))
+		if err != nil {
+			log.Fatal(err)
+		}
+		r.SetBasicAuth(user, password)
+		client := &http.Client{}
+		if insecure {
+			client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+		}
+		resp, err := client.Do(r)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			fmt.Printf("backfill request failed: %s\n", resp.Status)
+			os.Exit(1)
+		}
+		var kresp struct {
+			Job rpeat.BackfillResponse `json:"job"`
+			Err string                 `json:"err"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&kresp); err != nil {
+			log.Fatal(err)
+		}
+		for _, asof := range kresp.Job.AsOf {
+			fmt.Println(asof)
+		}
+		if kresp.Err != "" {
+			fmt.Printf("backfill error: %s (%s)\n", kresp.Job.Status, kresp.Err)
+			os.Exit(1)
+		}
+		fmt.Printf("backfill %s: %d run(s)\n", kresp.Job.Status, len(kresp.Job.AsOf))
+	case "start":
+		startCmd.Parse(os.Args[2:])
+		if jobid == "" {
+			startCmd.PrintDefaults()
+			os.Exit(2)
+		}
+		req, _ := json.Marshal(map[string]interface{}{"jobid": jobid, "params": params, "comment": comment})
+		r, err := http.NewRequest("POST", strings.TrimRight(server, "/")+"/api/start", bytes.NewBuffer(req))
+		if err != nil {
+			log.Fatal(err)
+		}
+		r.SetBasicAuth(user, password)
+		client := &http.Client{}
+		if insecure {
+			client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+		}
+		resp, err := client.Do(r)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			fmt.Printf("start request failed: %s\n", resp.Status)
+			os.Exit(1)
+		}
+		var ctl struct {
+			Status string
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&ctl); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("start %s: %s\n", jobid, ctl.Status)
+		if ctl.Status != "success" {
+			os.Exit(1)
+		}
 	case "convert":
 		convertCmd.Parse(os.Args[2:])
 		if convertCmd.NArg() == 0 {
@@ -310,7 +585,15 @@ Usage:
 			os.Exit(2)
 		}
 		calendarDirs := strings.Split(caldirs, ",")
-		dt, err := rpeat.ConvertDate(datevar, timezone, calendarDirs, asof)
+		fiscalYearStart, err := rpeat.ParseFiscalYearStart(fiscal)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		dt, steps, err := rpeat.ConvertDateSteps(datevar, timezone, calendarDirs, asof, fiscalYearStart)
+		for _, step := range steps {
+			fmt.Printf("  %s\n", step)
+		}
 		if err != nil {
 			dateenverr := err.(rpeat.DateEnvError)
 			fmt.Printf("%s: %s\n", dateenverr.Exception, dateenverr.Error())
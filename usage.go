@@ -0,0 +1,55 @@
+package rpeat
+
+import (
+	"fmt"
+	"os"
+)
+
+// Usage is the resource usage of the process of a run, including the children it waited
+// for, as reported by the OS when the run ends. UserCPU and SysCPU are in seconds and
+// MaxRSS in bytes. InBlock and OutBlock count block I/O operations, and VolCtxSw and
+// InvolCtxSw voluntary and involuntary context switches, which are not available in
+// windows.
+//
+// Usage is recorded in the History entry of each run, and the usage of the latest run
+// in Usage and Stats of the job. The job page charts MaxRSS and CPU over the History,
+// kept for MaxHistory runs, to show a job growing towards its Limits.
+type Usage struct {
+	UserCPU    float64 `json:"UserCPU"`
+	SysCPU     float64 `json:"SysCPU"`
+	MaxRSS     int64   `json:"MaxRSS"`
+	InBlock    int64   `json:"InBlock,omitempty"`
+	OutBlock   int64   `json:"OutBlock,omitempty"`
+	VolCtxSw   int64   `json:"VolCtxSw,omitempty"`
+	InvolCtxSw int64   `json:"InvolCtxSw,omitempty"`
+}
+
+// processUsage returns the Usage of the ended process ps, or nil if it was not started
+func processUsage(ps *os.ProcessState) *Usage {
+	if ps == nil {
+		return nil
+	}
+	u := &Usage{UserCPU: ps.UserTime().Seconds(), SysCPU: ps.SystemTime().Seconds()}
+	syscallUsage(ps, u)
+	return u
+}
+
+// CPU is the total of UserCPU and SysCPU
+func (u *Usage) CPU() float64 {
+	return u.UserCPU + u.SysCPU
+}
+
+func (u *Usage) String() string {
+	return fmt.Sprintf("cpu %.2fs (user %.2fs sys %.2fs) maxrss %s io %d/%d ctxsw %d/%d",
+		u.CPU(), u.UserCPU, u.SysCPU, formatSize(u.MaxRSS), u.InBlock, u.OutBlock, u.VolCtxSw, u.InvolCtxSw)
+}
+
+// recordUsage sets the Usage of the latest run and its Stats. Requires job.lock
+func (job *Job) recordUsage(u *Usage) {
+	job.Usage = u
+	job.Stats.Elapsed = job.ElapsedUNIX
+	if u != nil {
+		job.Stats.Memory = u.MaxRSS
+		job.Stats.CPU = u.CPU()
+	}
+}
@@ -124,7 +124,7 @@ func makerange(s int, len int, step int) []int {
 
 func (job *Job) ElapsedSeconds() int64 {
 	var t int64
-	if job.JobState == JRunning {
+	if job.JobState == JRunning || job.JobState == JWarning2 {
 		t = elapsedToInt(time.Now().Sub(job.prevStart).Round(time.Second))
 	} else {
 		t = elapsedToInt(job.elapsed)
@@ -535,15 +535,18 @@ func expandAllVars(job *Job, vars []string, cmd *string, shutdown bool, asof str
 		"RPEAT_TIMESTAMP=" + strconv.FormatInt(time.Now().Unix(), 10)}
 
 	env = append(env, JobEnv...)
+	env = append(env, job.runAsEnv()...)
+	env = append(env, job.paramEnv()...)
 
 	EnvMap := make(map[string]string) // map built sequentially from DateEnv/Env slices
 
 	// parse dates into values
+	fiscalYearStart, _ := ParseFiscalYearStart(job.FiscalYearStart)
 	for _, keyval := range job.DateEnv {
 		kv := strings.Split(string(keyval), "=")
 		dname := kv[0]
 		dval := kv[1]
-		convertedDate, _ := ConvertDate(dval, job.Timezone, job.CalendarDirs, asof)
+		convertedDate, _ := ConvertDate(dval, job.Timezone, job.CalendarDirs, asof, fiscalYearStart)
 		newkv := fmt.Sprintf("%s=%s", dname, convertedDate)
 		env = append(env, newkv)
 	}
@@ -588,10 +591,21 @@ func expandAllVars(job *Job, vars []string, cmd *string, shutdown bool, asof str
 	if cmd != nil {
 		args = strings.Fields(*cmd)
 	}
+	shell := true // Cmd or Script is passed to Shell unchanged
+	switch {
+	case !shutdown && job.Script != "":
+		args = append(shellArgs(job.Shell), job.scriptFile(job.RunUUID))
+	case job.Shell != "" && len(args) > 0:
+		args = append(shellArgs(job.Shell), "-c", *cmd)
+	default:
+		shell = false
+	}
 	c := exec.Cmd{}
 	if len(args) > 0 {
 		c.Path = args[0]
-		if len(args) > 1 {
+		if shell {
+			c.Args = args
+		} else if len(args) > 1 {
 			c.Args = []string{args[0], args[1], strings.Join(args[2:], " ")}
 		} else {
 			c.Args = args[:]
@@ -609,7 +623,8 @@ func expandAllVars(job *Job, vars []string, cmd *string, shutdown bool, asof str
 		if shutdown {
 			job.ShutdownCmdEval = cmdEvalString
 		} else {
-			job.CmdEval = cmdEvalString
+			// overridden Params are shown ahead of the command
+			job.CmdEval = strings.Join(append(job.paramOverrides(), cmdEvalString), " ")
 		}
 		evo.execErr = isExecutable(os.Expand(c.Path, getenv))
 	}
@@ -3,6 +3,7 @@ package rpeat
 import (
 	"fmt"
 	"github.com/google/uuid"
+	"os"
 	"strings"
 	"time"
 )
@@ -160,15 +161,19 @@ type CmdException int
 
 const (
 	CmdMissing CmdException = iota
+	CmdAndScript
+	WorkDirMissing
+	StdinMissing
 )
 
 type CmdError struct {
 	Cmd       string
+	Path      string
 	Exception CmdException
 }
 
 func (e CmdException) String() string {
-	names := [...]string{"CmdMissing"}
+	names := [...]string{"CmdMissing", "CmdAndScript", "WorkDirMissing", "StdinMissing"}
 	return names[e]
 }
 func (e CmdError) Error() string {
@@ -176,16 +181,40 @@ func (e CmdError) Error() string {
 	switch e.Exception {
 	case CmdMissing:
 		s = fmt.Sprintf("%s: 'Cmd' is missing or template inherited from is not available", e.Exception)
+	case CmdAndScript:
+		s = fmt.Sprintf("%s: 'Cmd' and 'Script' cannot both be set", e.Exception)
+	case WorkDirMissing:
+		s = fmt.Sprintf("%s: 'WorkDir' %s is not a directory (set CreateWorkDir to create it)", e.Exception, e.Path)
+	case StdinMissing:
+		s = fmt.Sprintf("%s: 'Stdin' file %s does not exist", e.Exception, e.Path)
 	default:
 		s = e.Exception.String()
 	}
 	return s
 }
 func (job *Job) ValidateCmd() {
-	if job.Cmd == nil {
+	if !job.hasCmd() {
 		ce := CmdError{Exception: CmdMissing, Cmd: ""}
 		job.jve.AddWarning(ValidationWarning{Exception: Cmd, Msg: ce.Error(), JobName: job.Name})
 	}
+	if job.Cmd != nil && job.Script != "" {
+		ce := CmdError{Exception: CmdAndScript, Cmd: *job.Cmd}
+		job.jve.AddError(ValidationError{Exception: Cmd, Msg: ce.Error(), JobName: job.Name})
+	}
+	if job.WorkDir != "" && !job.CreateWorkDir {
+		dir := job.ExpandEnv([]string{job.WorkDir}, job.asof)[0]
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			ce := CmdError{Exception: WorkDirMissing, Path: dir}
+			job.jve.AddError(ValidationError{Exception: Cmd, Msg: ce.Error(), JobName: job.Name})
+		}
+	}
+	// Stdin files are often written by upstream jobs, so only warn
+	if path, ok := stdinFile(job.ExpandEnv([]string{job.Stdin}, job.asof)[0]); ok {
+		if _, err := os.Stat(path); err != nil {
+			ce := CmdError{Exception: StdinMissing, Path: path}
+			job.jve.AddWarning(ValidationWarning{Exception: Cmd, Msg: ce.Error(), JobName: job.Name})
+		}
+	}
 }
 
 // EXCEPTION: Env and DateEnv
@@ -200,6 +229,7 @@ const (
 	UnknownShiftUnit
 	UnknownCalendar
 	CommaError // CC,CC ,,,+
+	UnknownCalAdjust
 )
 
 type DateEnvError struct {
@@ -211,14 +241,13 @@ type DateEnvError struct {
 }
 
 func (e DateEnvException) String() string {
-	names := [...]string{"NoMagicDateVars", "DuplicateMagicVar", "MissingShiftValue", "MissingShiftUnit", "ErrorInShiftValue", "UnknownShiftUnit", "UnknownCalendar", "CommaError"}
+	names := [...]string{"NoMagicDateVars", "DuplicateMagicVar", "MissingShiftValue", "MissingShiftUnit", "ErrorInShiftValue", "UnknownShiftUnit", "UnknownCalendar", "CommaError", "UnknownCalAdjust"}
 	return names[e]
 }
 func (e DateEnvError) Error() string {
 	var s string
 	switch e.Exception {
-	case UnknownShiftUnit:
-	case ErrorInShiftValue:
+	case UnknownShiftUnit, ErrorInShiftValue, UnknownCalAdjust:
 		s = e.UnitField
 	case DuplicateMagicVar:
 		s = e.MagicDate
@@ -278,6 +307,11 @@ func (e CalendarError) Error() string {
 	//    s = fmt.Sprintf("%s: \"%s\" not found in %s (%s)\n",e.Exception,e.Calendar,Stringify(e.CalendarDirs),e.fileErr)
 	case CalendarNotFound, CalendarDirNotFound:
 		s = fmt.Sprintf("%s: %s\n", e.Exception, e.fileErr)
+	case CalendarReadError, CalendarOutOfRange:
+		s = e.Exception.String()
+		if e.fileErr != nil {
+			s = fmt.Sprintf("%s: %s", e.Exception, e.fileErr)
+		}
 	default:
 		s = e.Exception.String()
 	}
@@ -303,6 +337,9 @@ func (job *Job) ValidateCalendar() {
 		return
 	}
 	if job.Calendar != "" && len(job.CalendarDirs) == 0 {
+		if _, err := ReadCalendar(job.Calendar, nil); err == nil {
+			return // built-in calendars
+		}
 		ce := CalendarError{Exception: MissingCalendarDirs, Calendar: job.Calendar}
 		job.jve.AddError(ValidationError{Exception: Calendar, Msg: ce.Error(), JobName: job.Name})
 		return
@@ -376,10 +413,12 @@ const (
 	NoUserSet
 	NoAuthUserSet // this may be OK, as long as they are legit users or no users
 	NoRestrictions
+	InvalidRunAs
+	RunAsNotPermitted
 )
 
 func (pe PermissionException) String() string {
-	names := [...]string{"UnknownUser", "UnknownAction", "NoPermissionsGranted", "NoUserSet", "NoAuthUserSet", "NoRestrictions"}
+	names := [...]string{"UnknownUser", "UnknownAction", "NoPermissionsGranted", "NoUserSet", "NoAuthUserSet", "NoRestrictions", "InvalidRunAs", "RunAsNotPermitted"}
 	return names[pe]
 }
 
@@ -387,6 +426,8 @@ type PermissionError struct {
 	Exception PermissionException
 	User      string
 	Action    string
+	RunAs     string
+	Msg       string
 	isWarning bool
 }
 
@@ -405,10 +446,14 @@ func (e PermissionError) Error() string {
 		s = fmt.Sprintf("%s: single user job.", e.Exception)
 	case NoRestrictions:
 		s = fmt.Sprintf("%s: unrestricted users (*) granted for '%s'", e.Exception, e.Action)
+	case InvalidRunAs:
+		s = fmt.Sprintf("%s: %s", e.Exception, e.Msg)
+	case RunAsNotPermitted:
+		s = fmt.Sprintf("%s: user '%s' is not granted RunAs '%s' in auth file", e.Exception, e.User, e.RunAs)
 	}
 	return s
 }
-func (job *Job) ValidatePermissions() PermissionError {
+func (job *Job) ValidatePermissions(authUsers []AuthUser) PermissionError {
 	user := job.User
 	perms := job.Permissions
 	//admin := job.Admin   TODO
@@ -442,6 +487,18 @@ func (job *Job) ValidatePermissions() PermissionError {
 			*/
 		}
 	}
+	// RunAs must resolve to OS user and groups, and be granted to the owner (if auth file is known)
+	if job.RunAs != nil {
+		if _, _, _, _, err := job.RunAs.lookup(); err != nil {
+			pe := PermissionError{Exception: InvalidRunAs, Msg: err.Error()}
+			job.jve.AddError(ValidationError{Exception: Permissions, Msg: pe.Error(), JobName: job.Name})
+		}
+		if authUsers != nil {
+			if err := job.allowedRunAs(authUsers); err != nil {
+				job.jve.AddError(ValidationError{Exception: Permissions, Msg: err.Error(), JobName: job.Name})
+			}
+		}
+	}
 	return PermissionError{}
 }
 
@@ -494,6 +551,9 @@ func (e DependencyError) Error() string {
 	return s
 }
 func (job *Job) ValidateDependency(jobs map[string]*Job) {
+	if job.isController() || job.isJOJ() {
+		return // Dependency of controllers (Jobs and Matrix) and their jobs is constructed
+	}
 	if job.Dependency == nil && job.isCronDependent() && !job.isTemplate() {
 		de := DependencyError{Exception: DependsWithoutDependency, Name: job.Name}
 		job.jve.AddError(ValidationError{JobName: job.Name, Msg: de.Error(), Exception: Dependencies})
@@ -676,6 +736,11 @@ func ValidateJobs(files []string, configFile, authFile string, verbose bool) (Jo
 
 	var serverkey, servername, apiKey string // FIXME: this needs to be extracted from LoadServerConfig(configFile, false)
 
+	var users []AuthUser
+	if authFile != "" {
+		users, _ = LoadAuth(authFile)
+	}
+
 	tmplOut := LoadTemplates(files)
 	templates := tmplOut.templates
 	//allJobNames := tmplOut.allJobNames
@@ -696,6 +761,42 @@ func ValidateJobs(files []string, configFile, authFile string, verbose bool) (Jo
 		var ntemplates, ndisabled int
 		ji := 0
 		jobs, specs, _, _, err := LoadJobSpec(f, 1, templates, ServerConfig{}, servername, serverkey, apiKey, logging)
+		// checkJob validates a regular (non-template) job of f
+		checkJob := func(job *Job) {
+			if _, ok := seenJobNames[job.Name]; ok {
+				msg := fmt.Sprintf("Duplicate job name '%#s' found", job.Name)
+				job.jve.AddError(ValidationError{Msg: msg, Exception: DuplicateJob})
+			}
+			if _, ok := seenJobUUIDs[job.JobUUID.String()]; ok {
+				msg := fmt.Sprintf("Duplicate job UUID '%#s' found", job.JobUUID.String())
+				job.jve.AddError(ValidationError{Msg: msg, Exception: DuplicateJob})
+			}
+			seenJobNames[job.Name] = true
+			seenJobUUIDs[job.JobUUID.String()] = true
+
+			parseErr := job.parseJob(false)
+			if parseErr != nil {
+				if verbose {
+					fmt.Printf(ErrorColor, fmt.Sprintf("  Job parse error encountered in %s (%s)\n", job.Name, f))
+				}
+				exception := Parse
+				_, ok := parseErr.(CronError)
+				if ok {
+					exception = Schedule
+				}
+				// FIXME: this should be set within parseJob call
+				job.jve.AddError(ValidationError{JobName: job.Name, Msg: parseErr.Error(), Exception: exception})
+				jve.JState = JConfigError
+			}
+			job.ValidateCmd()
+			job.ValidateTimezone()
+			job.ValidateCalendar()
+			job.ValidatePermissions(users)
+			job.ValidateAlerts()
+			alljobs = append(alljobs, job)
+			allParseErrs = append(allParseErrs, parseErr)
+		}
+
 		if err != nil && verbose {
 			fmt.Printf("  Job loading error encountered in %s\n", f)
 		} else {
@@ -740,39 +841,16 @@ func ValidateJobs(files []string, configFile, authFile string, verbose bool) (Jo
 						continue
 					}
 
-					if _, ok := seenJobNames[jobs[ji].Name]; ok {
-						msg := fmt.Sprintf("Duplicate job name '%#s' found", jobs[ji].Name)
-						jobs[ji].jve.AddError(ValidationError{Msg: msg, Exception: DuplicateJob})
-					}
-					if _, ok := seenJobUUIDs[jobs[ji].JobUUID.String()]; ok {
-						msg := fmt.Sprintf("Duplicate job UUID '%#s' found", jobs[ji].JobUUID.String())
-						jobs[ji].jve.AddError(ValidationError{Msg: msg, Exception: DuplicateJob})
+					if !jobs[ji].Disabled { // a Matrix job without Parent only runs its children
+						checkJob(&jobs[ji])
 					}
-					seenJobNames[jobs[ji].Name] = true
-					seenJobUUIDs[jobs[ji].JobUUID.String()] = true
-
-					parseErr := jobs[ji].parseJob(false)
-					if parseErr != nil {
-						if verbose {
-							fmt.Printf(ErrorColor, fmt.Sprintf("  Job parse error encountered in %s (%s)\n", jobs[ji].Name, f))
-						}
-						exception := Parse
-						_, ok := parseErr.(CronError)
-						if ok {
-							exception = Schedule
-						}
-						// FIXME: this should be set within parseJob call
-						jobs[si].jve.AddError(ValidationError{JobName: jobs[ji].Name, Msg: parseErr.Error(), Exception: exception})
-						jve.JState = JConfigError
-					}
-					jobs[ji].ValidateCmd()
-					jobs[ji].ValidateTimezone()
-					jobs[ji].ValidateCalendar()
-					jobs[ji].ValidatePermissions()
-					jobs[ji].ValidateAlerts()
-					alljobs = append(alljobs, &jobs[ji])
-					allParseErrs = append(allParseErrs, parseErr)
 					ji++
+					// jobs of Jobs are not checked, while Matrix children follow their job
+					ji += len(specs[si].Jobs)
+					for ji < len(jobs) && jobs[ji].Parent != "" && jobs[ji].Parent == specs[si].Name {
+						checkJob(&jobs[ji])
+						ji++
+					}
 				}
 			}
 			njobs := len(jobs)
@@ -817,7 +895,7 @@ func ValidateJobs(files []string, configFile, authFile string, verbose bool) (Jo
 
 			// get CCYYMMDDhhmmss "asof" string for correct DateEnv
 			var asofstart string
-			if len(alljobs[i].CronStartArray) > 0 {
+			if len(alljobs[i].CronStartArray) > 0 || len(alljobs[i].RRStart) > 0 {
 				if cronErr.Schedule != CronStart {
 					_, next := NextCronStart(alljobs[i].cronStartArray)
 					if !next.IsZero() {
@@ -844,13 +922,34 @@ func ValidateJobs(files []string, configFile, authFile string, verbose bool) (Jo
 					group = Stringify(alljobs[i].Group)
 				}
 				fmt.Printf("    \033[1;38;5;12mGroup:\033[0m [%s]\t\033[1;38;5;12mInherits:\033[0m [%s]\n", group, inheritanceChain)
-				if alljobs[i].Cmd != nil {
+				if alljobs[i].Shell != "" {
+					fmt.Printf("    \033[1;38;5;12mShell:\033[0m\t%s\n", alljobs[i].Shell)
+				}
+				if alljobs[i].WorkDir != "" {
+					fmt.Printf("    \033[1;38;5;12mWorkDir:\033[0m\t%s\n", alljobs[i].WorkDir)
+				}
+				if alljobs[i].Umask != "" {
+					fmt.Printf("    \033[1;38;5;12mUmask:\033[0m\t%s\n", alljobs[i].Umask)
+				}
+				if alljobs[i].Stdin != "" {
+					fmt.Printf("    \033[1;38;5;12mStdin:\033[0m\t%q\n", alljobs[i].Stdin)
+				}
+				if alljobs[i].Script != "" {
+					fmt.Printf("    \033[1;38;5;12mScript:\033[0m\n")
+					for _, line := range strings.Split(strings.TrimRight(alljobs[i].Script, "\n"), "\n") {
+						fmt.Printf("      | %s\n", line)
+					}
+					fmt.Printf("    \033[1;38;5;12mCmdEval:\033[0m\t%s\n", alljobs[i].CmdEval)
+				} else if alljobs[i].Cmd != nil {
 					fmt.Printf("    \033[1;38;5;12mCmd:\033[0m\t%s\n", *alljobs[i].Cmd)
 					fmt.Printf("    \033[1;38;5;12mCmdEval:\033[0m\t%s\n", alljobs[i].CmdEval)
 				} else {
 					fmt.Printf("    \033[1;38;5;12mCmd:\033[0m\t%s\n", "NOT DEFINED")
 					fmt.Printf("    \033[1;38;5;12mCmdEval:\033[0m\t%s\n", "NOT DEFINED")
 				}
+				if alljobs[i].Limits != nil {
+					fmt.Printf("    \033[1;38;5;12mLimits:\033[0m\t%s\n", alljobs[i].Limits)
+				}
 				// TODO:
 				// Variable overrides
 				// Held
@@ -859,7 +958,7 @@ func ValidateJobs(files []string, configFile, authFile string, verbose bool) (Jo
 				// Logging
 			}
 
-			if len(alljobs[i].CronStartArray) > 0 {
+			if len(alljobs[i].CronStartArray) > 0 || len(alljobs[i].RRStart) > 0 {
 				if cronErr.Schedule == CronStart {
 					if verbose {
 						fmt.Printf(ErrorColor, "    Start:\t** FAILED ** (see below)\n")
@@ -872,10 +971,13 @@ func ValidateJobs(files []string, configFile, authFile string, verbose bool) (Jo
 							nextStart = "TBD (Dependency Triggered)"
 						}
 						fmt.Printf("    \033[1;38;5;12mStart:\033[0m\t%s [ CronStart:  %s ]\n", nextStart, Stringify(alljobs[i].CronStartArray))
+						if len(alljobs[i].RRStart) > 0 {
+							fmt.Printf("    \033[1;38;5;12m      \033[0m\t[ RRStart:  %s ]\n", Stringify(alljobs[i].RRStart))
+						}
 					}
 				}
 			}
-			if len(alljobs[i].CronEndArray) > 0 {
+			if len(alljobs[i].CronEndArray) > 0 || len(alljobs[i].RREnd) > 0 {
 				if cronErr.Schedule == CronEnd {
 					if verbose {
 						fmt.Printf(ErrorColor, "    End:\t** FAILED ** (see below)\n")
@@ -888,22 +990,29 @@ func ValidateJobs(files []string, configFile, authFile string, verbose bool) (Jo
 							nextStart = "TBD"
 						}
 						fmt.Printf("    \033[1;38;5;12mNextEnd:\033[0m\t%s [ CronEnd:  %s ]\n", nextStart, Stringify(alljobs[i].CronEndArray))
+						if len(alljobs[i].RREnd) > 0 {
+							fmt.Printf("    \033[1;38;5;12m        \033[0m\t[ RREnd:  %s ]\n", Stringify(alljobs[i].RREnd))
+						}
 					}
 				}
 			}
-			if alljobs[i].CronRestart != nil {
+			if alljobs[i].hasCronRestart() {
 				if cronErr.Schedule == CronRestart {
 					if verbose {
 						fmt.Printf(ErrorColor, "    Restart:\t** FAILED ** (see below)\n")
 					}
 				} else {
-					_, next := NextCronStart([]Cron{alljobs[i].cronRestart})
+					_, next := NextCronStart(alljobs[i].cronRestartArray)
 					if verbose {
 						nextStart := next.In(alljobs[i]._location).Format(time.RFC1123)
 						if next.IsZero() {
 							nextStart = "TBD"
 						}
-						fmt.Printf("    \033[1;38;5;12mRestart:\033[0m\t%s [ CronRestart:  %s ]\n", nextStart, *alljobs[i].CronRestart)
+						if alljobs[i].CronRestart != nil {
+							fmt.Printf("    \033[1;38;5;12mRestart:\033[0m\t%s [ CronRestart:  %s ]\n", nextStart, *alljobs[i].CronRestart)
+						} else {
+							fmt.Printf("    \033[1;38;5;12mRestart:\033[0m\t%s [ RRRestart:  %s ]\n", nextStart, Stringify(alljobs[i].RRRestart))
+						}
 					}
 				}
 			}
@@ -0,0 +1,118 @@
+package rpeat
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// parseUmask parses an octal Umask, returning -1 if it is not set
+func parseUmask(umask string) (int, error) {
+	if umask == "" {
+		return -1, nil
+	}
+	mask, err := strconv.ParseUint(umask, 8, 32)
+	if err != nil || mask > 0777 {
+		return -1, errors.New(fmt.Sprintf("invalid Umask %q (expecting octal e.g. \"0027\")", umask))
+	}
+	return int(mask), nil
+}
+
+// stdinFile returns the file Stdin reads from, if it starts with @
+func stdinFile(stdin string) (string, bool) {
+	if strings.HasPrefix(stdin, "@") {
+		return strings.TrimPrefix(stdin, "@"), true
+	}
+	return "", false
+}
+
+// applyWorkDir sets the WorkDir, Stdin and Umask of the process c of a run as of asof.
+// The server creates WorkDir and opens the Stdin file on behalf of a RunAs user uid:gid,
+// so these are refused unless the user could do so itself, and created directories are
+// given to the user. The returned Stdin file, if any, is to be closed once the process
+// has started. Must be called before newLimiter
+func (job *Job) applyWorkDir(c *exec.Cmd, asof string, uid, gid int) (*os.File, error) {
+	if job.WorkDir != "" {
+		c.Dir = job.ExpandEnv([]string{job.WorkDir}, asof)[0]
+		if job.CreateWorkDir {
+			if _, err := os.Stat(c.Dir); os.IsNotExist(err) {
+				if err = job.createWorkDir(c.Dir, uid, gid); err != nil {
+					return nil, errors.New(fmt.Sprintf("unable to create WorkDir: %s", err))
+				}
+			}
+		}
+		if fi, err := os.Stat(c.Dir); err != nil || !fi.IsDir() {
+			return nil, errors.New(fmt.Sprintf("WorkDir %s is not a directory", c.Dir))
+		}
+	}
+	if mask, _ := parseUmask(job.Umask); mask >= 0 {
+		if err := job.runAsExecutable(); err != nil {
+			return nil, err
+		}
+		if err := umaskCmd(c, mask); err != nil {
+			return nil, err
+		}
+	}
+	if job.Stdin != "" {
+		stdin := job.ExpandEnv([]string{job.Stdin}, asof)[0]
+		if path, ok := stdinFile(stdin); ok {
+			if !filepath.IsAbs(path) && c.Dir != "" {
+				path = filepath.Join(c.Dir, path)
+			}
+			f, err := os.Open(path)
+			if err == nil {
+				// checked on the open file, which cannot be replaced after the check
+				var fi os.FileInfo
+				if fi, err = f.Stat(); err == nil {
+					err = job.runAsAccess(path, fi, 4)
+				}
+				if err != nil {
+					f.Close()
+				}
+			}
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("unable to open Stdin: %s", err))
+			}
+			c.Stdin = f
+			return f, nil
+		}
+		c.Stdin = strings.NewReader(stdin)
+	}
+	return nil, nil
+}
+
+// createWorkDir creates dir and any missing parents, which must be permitted to the RunAs
+// user by the existing parent, and gives them to uid:gid
+func (job *Job) createWorkDir(dir string, uid, gid int) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	parent := dir
+	for {
+		if _, err := os.Stat(parent); err == nil || parent == filepath.Dir(parent) {
+			break
+		}
+		missing = append(missing, parent)
+		parent = filepath.Dir(parent)
+	}
+	if err := job.runAsAccess(parent, nil, 3); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, os.FileMode(0770)); err != nil {
+		return err
+	}
+	if uid >= 0 {
+		for i := len(missing) - 1; i >= 0; i-- {
+			if err := os.Chown(missing[i], uid, gid); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}